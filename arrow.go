@@ -0,0 +1,73 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+// An Apache Arrow validity bitmap records which elements of an array are
+// valid (not null), with bit i set if element i is valid.  Bits are numbered
+// least significant bit first within each byte, which is the layout of a
+// Bytes bitset, so a Bytes bitset may be used as a validity buffer without
+// conversion.  Arrow recommends that buffers are padded to a multiple of
+// 64 bytes, and requires that padding bits are zero.  An array with an
+// offset begins at that bit of its validity buffer, and an array without a
+// validity buffer has no null elements.
+
+// arrowPadding is the buffer size multiple recommended by Arrow.
+const arrowPadding = 64
+
+// NewArrowBytes returns a new bitset capable of holding numBits bits, with
+// its length padded to a multiple of 64 bytes so that it may be used
+// directly as the validity buffer of an Arrow array of numBits elements.
+// Every bit is unset, so every element is initially null.
+func NewArrowBytes(numBits int) Bytes {
+	return make(Bytes, arrowPadded(numBits))
+}
+
+// ToArrowValidity returns a new Arrow validity buffer for an array of length
+// elements, where element i is valid if bit i of s is set.  The buffer is
+// padded to a multiple of 64 bytes, and bits of s at or beyond length are
+// not copied.  Bits beyond the end of s are considered unset.
+func ToArrowValidity(s Bytes, length int) []byte {
+	buf := NewArrowBytes(length)
+	copy(buf, s[:min(len(s), (length+byteModMask)>>byteShift)])
+	buf.clearFrom(length)
+	return buf
+}
+
+// FromArrowValidity returns a new Bytes bitset holding the validity of the
+// length elements of an Arrow array beginning at bit offset of its validity
+// buffer buf, with bit i set if element i is valid.  If buf is nil, the
+// array has no validity buffer and every bit is set.  This function will
+// panic if buf is non-nil and too short to hold the validity of the array.
+func FromArrowValidity(buf []byte, offset, length int) Bytes {
+	s := NewBytes(length)
+	if buf == nil {
+		for i := range s {
+			s[i] = 0xff
+		}
+		s.clearFrom(length)
+		return s
+	}
+	if offset < 0 || length < 0 || len(buf)<<byteShift-offset < length {
+		panic("bitset: Arrow validity buffer too short")
+	}
+	src := buf[offset>>byteShift:]
+	sh := uint(offset) & byteModMask
+	for j := range s {
+		b := src[j] >> sh
+		if sh != 0 && j+1 < len(src) {
+			b |= src[j+1] << (8 - sh)
+		}
+		s[j] = b
+	}
+	s.clearFrom(length)
+	return s
+}
+
+// arrowPadded returns the number of bytes needed to hold numBits bits,
+// rounded up to a multiple of the Arrow buffer padding.
+func arrowPadded(numBits int) int {
+	n := (numBits + byteModMask) >> byteShift
+	return (n + arrowPadding - 1) / arrowPadding * arrowPadding
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset_test
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/jrick/bitset"
+)
+
+func TestArrowValidity(t *testing.T) {
+	for _, numBits := range []int{0, 1, 8, 511, 512, 513} {
+		if got := len(NewArrowBytes(numBits)); got%64 != 0 || got*8 < numBits {
+			t.Errorf("NewArrowBytes(%d): got length %d", numBits, got)
+		}
+	}
+
+	s := NewBytesFromIndices([]int{0, 2, 9, 15, 600})
+	buf := ToArrowValidity(s, 10)
+	exp := make([]byte, 64)
+	exp[0], exp[1] = 0x05, 0x02
+	if !bytes.Equal(buf, exp) {
+		t.Errorf("ToArrowValidity: got %x expected %x", buf, exp)
+	}
+	if buf := ToArrowValidity(s, 601); len(buf) != 128 || buf[75] != 0x01 {
+		t.Errorf("ToArrowValidity: got %x", buf)
+	}
+
+	tests := []struct {
+		buf            []byte
+		offset, length int
+		exp            []byte
+	}{
+		{buf: []byte{0x05, 0x02}, offset: 0, length: 10, exp: []byte{0x05, 0x02}},
+		{buf: []byte{0x05, 0x02}, offset: 0, length: 3, exp: []byte{0x05}},
+		{buf: []byte{0x05, 0x02}, offset: 2, length: 8, exp: []byte{0x81}},
+		{buf: []byte{0xf0, 0x0f}, offset: 4, length: 12, exp: []byte{0xff, 0x00}},
+		{buf: []byte{0xf0, 0x0f}, offset: 8, length: 8, exp: []byte{0x0f}},
+		{buf: []byte{0xff}, offset: 3, length: 5, exp: []byte{0x1f}},
+		{buf: nil, offset: 7, length: 10, exp: []byte{0xff, 0x03}},
+		{buf: nil, offset: 0, length: 0, exp: []byte{}},
+	}
+	for testNum, test := range tests {
+		got := FromArrowValidity(test.buf, test.offset, test.length)
+		if !bytes.Equal(got, test.exp) {
+			t.Errorf("Test %d: got %x expected %x", testNum, []byte(got), test.exp)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic for short buffer")
+		}
+	}()
+	FromArrowValidity([]byte{0xff}, 1, 8)
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+// AutoGrowPointers is a Pointers bitset which grows itself as needed rather
+// than panicking when indexed beyond its allocated range.  Setting a bit
+// outside the range grows the bitset using GrowAmortized, while getting or
+// unsetting such a bit does not allocate: the bit is reported as unset, just
+// as for a Sparse bitset.  The zero value is an empty bitset ready for use.
+//
+// Negative indexes are never valid and cause every method to panic.
+type AutoGrowPointers struct {
+	p Pointers
+}
+
+// NewAutoGrowPointers returns a new auto-growing bitset with room for numBits
+// number of binary values before it must first grow.
+func NewAutoGrowPointers(numBits int) *AutoGrowPointers {
+	return &AutoGrowPointers{p: NewPointers(numBits)}
+}
+
+// Pointers returns the underlying bitset.  The returned slice aliases the
+// bitset's storage until the next call to Set grows it.
+func (a *AutoGrowPointers) Pointers() Pointers {
+	return a.p
+}
+
+// Get returns whether the bit at index i is set or not.  Bits beyond the
+// allocated range are unset.
+func (a *AutoGrowPointers) Get(i int) bool {
+	if i >= 0 && uint(i)>>ptrShift >= uint(len(a.p)) {
+		return false
+	}
+	return a.p.Get(i)
+}
+
+// Set sets the bit at index i, first growing the bitset if i is beyond the
+// allocated range.
+func (a *AutoGrowPointers) Set(i int) {
+	if i < 0 {
+		panic("bitset: negative index")
+	}
+	a.p.GrowAmortized(i + 1)
+	a.p.Set(i)
+}
+
+// Unset unsets the bit at index i.  Bits beyond the allocated range are
+// already unset, and unsetting them does not grow the bitset.
+func (a *AutoGrowPointers) Unset(i int) {
+	if i >= 0 && uint(i)>>ptrShift >= uint(len(a.p)) {
+		return
+	}
+	a.p.Unset(i)
+}
+
+// SetBool sets or unsets the bit at index i depending on the value of b.
+func (a *AutoGrowPointers) SetBool(i int, b bool) {
+	if b {
+		a.Set(i)
+		return
+	}
+	a.Unset(i)
+}
+
+// AutoGrowBytes is a Bytes bitset which grows itself as needed rather than
+// panicking when indexed beyond its allocated range.  Setting a bit outside
+// the range grows the bitset using GrowAmortized, while getting or unsetting
+// such a bit does not allocate: the bit is reported as unset, just as for a
+// Sparse bitset.  The zero value is an empty bitset ready for use.
+//
+// Negative indexes are never valid and cause every method to panic.
+type AutoGrowBytes struct {
+	s Bytes
+}
+
+// NewAutoGrowBytes returns a new auto-growing bitset with room for numBits
+// number of binary values before it must first grow.
+func NewAutoGrowBytes(numBits int) *AutoGrowBytes {
+	return &AutoGrowBytes{s: NewBytes(numBits)}
+}
+
+// Bytes returns the underlying bitset.  The returned slice aliases the
+// bitset's storage until the next call to Set grows it.
+func (a *AutoGrowBytes) Bytes() Bytes {
+	return a.s
+}
+
+// Get returns whether the bit at index i is set or not.  Bits beyond the
+// allocated range are unset.
+func (a *AutoGrowBytes) Get(i int) bool {
+	if i >= 0 && uint(i)>>byteShift >= uint(len(a.s)) {
+		return false
+	}
+	return a.s.Get(i)
+}
+
+// Set sets the bit at index i, first growing the bitset if i is beyond the
+// allocated range.
+func (a *AutoGrowBytes) Set(i int) {
+	if i < 0 {
+		panic("bitset: negative index")
+	}
+	a.s.GrowAmortized(i + 1)
+	a.s.Set(i)
+}
+
+// Unset unsets the bit at index i.  Bits beyond the allocated range are
+// already unset, and unsetting them does not grow the bitset.
+func (a *AutoGrowBytes) Unset(i int) {
+	if i >= 0 && uint(i)>>byteShift >= uint(len(a.s)) {
+		return
+	}
+	a.s.Unset(i)
+}
+
+// SetBool sets or unsets the bit at index i depending on the value of b.
+func (a *AutoGrowBytes) SetBool(i int, b bool) {
+	if b {
+		a.Set(i)
+		return
+	}
+	a.Unset(i)
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset_test
+
+import (
+	"testing"
+
+	. "github.com/jrick/bitset"
+)
+
+func TestAutoGrow(t *testing.T) {
+	set := []int{0, 7, 8, 63, 64, 1000, 5000}
+	bitsets := []struct {
+		name string
+		bs   BitSet
+	}{
+		{"AutoGrowPointers", new(AutoGrowPointers)},
+		{"AutoGrowBytes", NewAutoGrowBytes(8)},
+	}
+	allocated := func(bs BitSet) int {
+		switch bs := bs.(type) {
+		case *AutoGrowPointers:
+			return len(bs.Pointers())
+		case *AutoGrowBytes:
+			return len(bs.Bytes())
+		}
+		return 0
+	}
+
+	for _, nbs := range bitsets {
+		if nbs.bs.Get(10000) {
+			t.Errorf("bitset %s: bit beyond range reported set", nbs.name)
+		}
+		nbs.bs.Unset(10000)
+		nbs.bs.SetBool(20000, false)
+		if allocated(nbs.bs) > 1 {
+			t.Errorf("bitset %s: grew without setting a bit", nbs.name)
+		}
+		for _, i := range set {
+			nbs.bs.SetBool(i, true)
+		}
+		for i := 0; i <= 5100; i++ {
+			exp := false
+			for _, j := range set {
+				if i == j {
+					exp = true
+				}
+			}
+			if got := nbs.bs.Get(i); got != exp {
+				t.Errorf("bitset %s: bit %d got %v expected %v",
+					nbs.name, i, got, exp)
+			}
+		}
+		nbs.bs.Unset(5000)
+		if nbs.bs.Get(5000) {
+			t.Errorf("bitset %s: bit 5000 set after Unset", nbs.name)
+		}
+	}
+}
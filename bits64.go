@@ -0,0 +1,150 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+import (
+	"iter"
+	"math/bits"
+)
+
+// Bits64 is a bitset of at most 64 bits held in a single uint64.  It is a
+// value type: it requires no allocation, may be copied and compared with ==,
+// and is suitable for small flag sets where a slice-backed bitset would be
+// overkill.  Bit i is bit i of the integer, so Bits64(1<<3) has only bit 3
+// set.
+//
+// Methods which modify the set have pointer receivers, and a *Bits64
+// implements the BitSet interface.  The binary set operations return a new
+// value.  Every index must be in the range [0, 64), and out of range indexes
+// cause a panic.
+type Bits64 uint64
+
+// check panics if i is not a valid index of a Bits64.
+func (Bits64) check(i int) {
+	if uint(i) >= 64 {
+		panic("bitset: index out of range")
+	}
+}
+
+// Get returns whether the bit at index i is set or not.
+func (b Bits64) Get(i int) bool {
+	b.check(i)
+	return b&(1<<uint(i)) != 0
+}
+
+// Set sets the bit at index i.
+func (b *Bits64) Set(i int) {
+	b.check(i)
+	*b |= 1 << uint(i)
+}
+
+// Unset unsets the bit at index i.
+func (b *Bits64) Unset(i int) {
+	b.check(i)
+	*b &^= 1 << uint(i)
+}
+
+// SetBool sets or unsets the bit at index i depending on the value of v.
+func (b *Bits64) SetBool(i int, v bool) {
+	if v {
+		b.Set(i)
+		return
+	}
+	b.Unset(i)
+}
+
+// Flip toggles the bit at index i.
+func (b *Bits64) Flip(i int) {
+	b.check(i)
+	*b ^= 1 << uint(i)
+}
+
+// Count returns the total number of set bits in the bitset.
+func (b Bits64) Count() int {
+	return bits.OnesCount64(uint64(b))
+}
+
+// Any returns whether any bit in the bitset is set.
+func (b Bits64) Any() bool {
+	return b != 0
+}
+
+// None returns whether no bits in the bitset are set.
+func (b Bits64) None() bool {
+	return b == 0
+}
+
+// NextSet returns the index of the first set bit at or after index i, or -1
+// if there is no such bit.  Negative indexes are treated as zero.
+func (b Bits64) NextSet(i int) int {
+	if i < 0 {
+		i = 0
+	}
+	if i >= 64 {
+		return -1
+	}
+	rest := uint64(b) >> uint(i)
+	if rest == 0 {
+		return -1
+	}
+	return i + bits.TrailingZeros64(rest)
+}
+
+// Ones returns an iterator over the indexes of all set bits in increasing
+// order.  As b is a value, modifications made during iteration are never
+// observed.
+func (b Bits64) Ones() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for w := uint64(b); w != 0; w &= w - 1 {
+			if !yield(bits.TrailingZeros64(w)) {
+				return
+			}
+		}
+	}
+}
+
+// ToSlice returns the indexes of all set bits in increasing order.
+func (b Bits64) ToSlice() []int {
+	s := make([]int, 0, b.Count())
+	for i := range b.Ones() {
+		s = append(s, i)
+	}
+	return s
+}
+
+// And returns the intersection of b and other.
+func (b Bits64) And(other Bits64) Bits64 {
+	return b & other
+}
+
+// Or returns the union of b and other.
+func (b Bits64) Or(other Bits64) Bits64 {
+	return b | other
+}
+
+// Xor returns the symmetric difference of b and other.
+func (b Bits64) Xor(other Bits64) Bits64 {
+	return b ^ other
+}
+
+// AndNot returns the bits of b which are not set in other.
+func (b Bits64) AndNot(other Bits64) Bits64 {
+	return b &^ other
+}
+
+// Complement returns the bitset with every bit of b inverted.
+func (b Bits64) Complement() Bits64 {
+	return ^b
+}
+
+// Intersects returns whether b and other have any set bit in common.
+func (b Bits64) Intersects(other Bits64) bool {
+	return b&other != 0
+}
+
+// IsSubsetOf returns whether every bit set in b is also set in other.
+func (b Bits64) IsSubsetOf(other Bits64) bool {
+	return b&^other == 0
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset_test
+
+import (
+	"testing"
+
+	. "github.com/jrick/bitset"
+)
+
+func TestBits64(t *testing.T) {
+	var b Bits64
+	var bs BitSet = &b
+	set := []int{0, 5, 31, 32, 63}
+	for _, i := range set {
+		bs.SetBool(i, true)
+	}
+	if b != 1|1<<5|1<<31|1<<32|1<<63 {
+		t.Errorf("unexpected value %#x", uint64(b))
+	}
+	if b.Count() != len(set) || !b.Any() || b.None() {
+		t.Errorf("got count %d any %v none %v", b.Count(), b.Any(), b.None())
+	}
+	if got := b.ToSlice(); !equalIndices(got, set) {
+		t.Errorf("ToSlice: got %v expected %v", got, set)
+	}
+	for i, exp := range map[int]int{-1: 0, 0: 0, 1: 5, 33: 63, 63: 63} {
+		if got := b.NextSet(i); got != exp {
+			t.Errorf("NextSet(%d): got %d expected %d", i, got, exp)
+		}
+	}
+	if got := b.NextSet(64); got != -1 {
+		t.Errorf("NextSet(64): got %d expected -1", got)
+	}
+	b.Flip(5)
+	b.Unset(63)
+	if b.Get(5) || b.Get(63) || !b.Get(32) {
+		t.Errorf("Flip/Unset: unexpected value %#x", uint64(b))
+	}
+
+	x, y := Bits64(0b1100), Bits64(0b1010)
+	ops := []struct {
+		name     string
+		got, exp Bits64
+	}{
+		{"And", x.And(y), 0b1000},
+		{"Or", x.Or(y), 0b1110},
+		{"Xor", x.Xor(y), 0b0110},
+		{"AndNot", x.AndNot(y), 0b0100},
+		{"Complement", x.Complement().And(0b1111), 0b0011},
+	}
+	for _, op := range ops {
+		if op.got != op.exp {
+			t.Errorf("%s: got %#b expected %#b", op.name, op.got, op.exp)
+		}
+	}
+	if !x.Intersects(y) || x.IsSubsetOf(y) || !x.And(y).IsSubsetOf(y) {
+		t.Errorf("unexpected Intersects or IsSubsetOf result")
+	}
+
+	for _, i := range []int{-1, 64} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Set(%d) did not panic", i)
+				}
+			}()
+			b.Set(i)
+		}()
+	}
+}
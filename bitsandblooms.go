@@ -0,0 +1,73 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// ErrInvalidBitsAndBlooms describes an error where a bitset encoded by the
+// github.com/bits-and-blooms/bitset package could not be decoded because it
+// was truncated or otherwise malformed.
+var ErrInvalidBitsAndBlooms = errors.New("bitset: invalid bits-and-blooms encoding")
+
+// The github.com/bits-and-blooms/bitset package encodes a bitset with its
+// MarshalBinary and WriteTo methods as:
+//
+//	big endian uint64   length of the bitset in bits, n
+//	big endian uint64s  (n+63)/64 words, where bit i is bit i%64 of word i/64
+//
+// Its bits are numbered as those of a Words64 bitset, and only the byte
+// order of each word differs from the encodings of this package.  Bitsets
+// encoded by that package may be decoded with DecodeBitsAndBlooms, and the
+// encoding returned by AppendBitsAndBlooms may be decoded by its
+// UnmarshalBinary and ReadFrom methods.
+
+// AppendBitsAndBlooms appends the github.com/bits-and-blooms/bitset encoding
+// of a bitset of numBits bits, held by w, to dst and returns the extended
+// slice.  Bits of w at or beyond numBits are written as zero.  This function
+// will panic if numBits is negative or exceeds the number of bits held by w.
+func AppendBitsAndBlooms(dst []byte, w Words64, numBits int) []byte {
+	if numBits < 0 || numBits > len(w)<<6 {
+		panic("bitset: bit length out of range")
+	}
+	dst = binary.BigEndian.AppendUint64(dst, uint64(numBits))
+	numWords := (numBits + 63) >> 6
+	for k, word := range w[:numWords] {
+		if k == numWords-1 && numBits&63 != 0 {
+			word &= 1<<(uint(numBits)&63) - 1
+		}
+		dst = binary.BigEndian.AppendUint64(dst, word)
+	}
+	return dst
+}
+
+// DecodeBitsAndBlooms decodes a bitset encoded by the MarshalBinary or
+// WriteTo methods of the github.com/bits-and-blooms/bitset package,
+// returning its words and its length in bits.  Bits of the final word at or
+// beyond the length are cleared.  If data is truncated, holds trailing bytes,
+// or records a length which cannot be represented,
+// ErrInvalidBitsAndBlooms is returned.
+func DecodeBitsAndBlooms(data []byte) (Words64, int, error) {
+	if len(data) < 8 {
+		return nil, 0, ErrInvalidBitsAndBlooms
+	}
+	n := binary.BigEndian.Uint64(data)
+	data = data[8:]
+	// Checking the length against the data before allocating bounds the
+	// allocation made for a hostile length.
+	if n > uint64(maxInt-63) || (n+63)>>6 != uint64(len(data))>>3 || len(data)&7 != 0 {
+		return nil, 0, ErrInvalidBitsAndBlooms
+	}
+	w := make(Words64, len(data)>>3)
+	for k := range w {
+		w[k] = binary.BigEndian.Uint64(data[k<<3:])
+	}
+	if n&63 != 0 {
+		w[len(w)-1] &= 1<<(n&63) - 1
+	}
+	return w, int(n), nil
+}
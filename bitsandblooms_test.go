@@ -0,0 +1,82 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset_test
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+
+	. "github.com/jrick/bitset"
+)
+
+func TestBitsAndBlooms(t *testing.T) {
+	tests := []struct {
+		numBits int
+		set     []int
+		exp     []byte
+	}{
+		{numBits: 0, exp: []byte{0, 0, 0, 0, 0, 0, 0, 0}},
+		{numBits: 10, set: []int{0, 9}, exp: []byte{
+			0, 0, 0, 0, 0, 0, 0, 10,
+			0, 0, 0, 0, 0, 0, 0x02, 0x01,
+		}},
+		{numBits: 65, set: []int{63, 64}, exp: []byte{
+			0, 0, 0, 0, 0, 0, 0, 65,
+			0x80, 0, 0, 0, 0, 0, 0, 0,
+			0, 0, 0, 0, 0, 0, 0, 1,
+		}},
+	}
+	for testNum, test := range tests {
+		w := NewWords64(test.numBits + 64)
+		for _, i := range test.set {
+			w.Set(i)
+		}
+		// Bits beyond the length are not written.
+		w.Set(test.numBits)
+		got := AppendBitsAndBlooms(nil, w, test.numBits)
+		if !bytes.Equal(got, test.exp) {
+			t.Errorf("Test %d: got encoding %x expected %x", testNum, got, test.exp)
+		}
+
+		decoded, n, err := DecodeBitsAndBlooms(test.exp)
+		if err != nil {
+			t.Errorf("Test %d: unexpected error %v", testNum, err)
+			continue
+		}
+		if n != test.numBits || len(decoded) != (n+63)/64 {
+			t.Errorf("Test %d: got length %d with %d words", testNum, n, len(decoded))
+		}
+		if ones := slices.Collect(decoded.Ones()); !slices.Equal(ones, test.set) {
+			t.Errorf("Test %d: decoded %v expected %v", testNum, ones, test.set)
+		}
+	}
+}
+
+func TestBitsAndBloomsInvalid(t *testing.T) {
+	tests := [][]byte{
+		nil,
+		{0, 0, 0, 0, 0, 0, 0},
+		{0, 0, 0, 0, 0, 0, 0, 1},    // missing word
+		{0, 0, 0, 0, 0, 0, 0, 0, 0}, // trailing byte
+		{0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0},       // truncated word
+		{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0}, // hostile length
+	}
+	for testNum, data := range tests {
+		if _, _, err := DecodeBitsAndBlooms(data); err != ErrInvalidBitsAndBlooms {
+			t.Errorf("Test %d: got error %v expected %v", testNum, err,
+				ErrInvalidBitsAndBlooms)
+		}
+	}
+
+	// The final word of a partially filled bitset is masked.
+	w, _, err := DecodeBitsAndBlooms([]byte{
+		0, 0, 0, 0, 0, 0, 0, 4,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+	})
+	if err != nil || w.Count() != 4 {
+		t.Errorf("got %x, %v", w, err)
+	}
+}
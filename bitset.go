@@ -82,6 +82,27 @@ func NewPointers(numBits int) Pointers {
 	return make(Pointers, (numBits+ptrModMask)>>ptrShift)
 }
 
+// NewPointersCap returns a new bitset that is capable of holding numBits number
+// of binary values, with a backing array large enough to hold capBits bits.
+// The bitset may later be grown to capBits bits without reallocating.  If
+// capBits is less than numBits, numBits is used as the capacity.
+func NewPointersCap(numBits, capBits int) Pointers {
+	n := (numBits + ptrModMask) >> ptrShift
+	c := (capBits + ptrModMask) >> ptrShift
+	return make(Pointers, n, max(n, c))
+}
+
+// NewPointersFromIndices returns a new bitset with the bit at each index in
+// indices set.  The bitset is sized to hold exactly enough pointers for the
+// largest index.  This function will panic if any index is negative.
+func NewPointersFromIndices(indices []int) Pointers {
+	p := NewPointers(maxIndex(indices) + 1)
+	for _, i := range indices {
+		p.Set(i)
+	}
+	return p
+}
+
 // Get returns whether the bit at index i is set or not.  This method will
 // panic if the index results in a pointer index that exceeds the number of
 // pointers held by the bitset.
@@ -89,6 +110,14 @@ func (p Pointers) Get(i int) bool {
 	return p[uint(i)>>ptrShift]&(1<<(uint(i)&ptrModMask)) != 0
 }
 
+// GetOrFalse returns whether the bit at index i is set or not.  Unlike Get,
+// it never panics: indexes outside of the allocated range, including
+// negative indexes, are reported as unset, matching the behavior of Sparse.
+func (p Pointers) GetOrFalse(i int) bool {
+	k := uint(i) >> ptrShift
+	return k < uint(len(p)) && p[k]&(1<<(uint(i)&ptrModMask)) != 0
+}
+
 // Set sets the bit at index i.  This method will panic if the index results
 // in a pointer index that exceeds the number of pointers held by the bitset.
 func (p Pointers) Set(i int) {
@@ -113,16 +142,122 @@ func (p Pointers) SetBool(i int, b bool) {
 	p.Unset(i)
 }
 
-// Grow ensures that the bitset w is large enough to hold numBits number of
-// bits, potentially appending to and/or reallocating the slice if the
-// current length is not sufficient.
+// TestAndSet sets the bit at index i and returns whether it was previously
+// set.  This method will panic if the index results in a pointer index that
+// exceeds the number of pointers held by the bitset.
+func (p Pointers) TestAndSet(i int) bool {
+	ptr := &p[uint(i)>>ptrShift]
+	mask := uintptr(1) << (uint(i) & ptrModMask)
+	old := *ptr
+	*ptr = old | mask
+	return old&mask != 0
+}
+
+// TestAndClear unsets the bit at index i and returns whether it was
+// previously set.  This method will panic if the index results in a pointer
+// index that exceeds the number of pointers held by the bitset.
+func (p Pointers) TestAndClear(i int) bool {
+	ptr := &p[uint(i)>>ptrShift]
+	mask := uintptr(1) << (uint(i) & ptrModMask)
+	old := *ptr
+	*ptr = old &^ mask
+	return old&mask != 0
+}
+
+// Flip toggles the bit at index i.  This method will panic if the index
+// results in a pointer index that exceeds the number of pointers held by the
+// bitset.
+func (p Pointers) Flip(i int) {
+	p[uint(i)>>ptrShift] ^= 1 << (uint(i) & ptrModMask)
+}
+
+// Clone returns a copy of p which does not share memory with p.
+func (p Pointers) Clone() Pointers {
+	c := make(Pointers, len(p))
+	copy(c, p)
+	return c
+}
+
+// Grow ensures that the bitset p is large enough to hold numBits number of
+// bits.  If the backing array has enough spare capacity, the slice is
+// extended in place and the reclaimed pointers are zeroed, so bits left
+// behind by an earlier Truncate or reslice never reappear as set.
+// Otherwise, the slice is reallocated.
 func (p *Pointers) Grow(numBits int) {
 	ptrs := *p
+	numBits = max(numBits, 0)
+	targetLen := (numBits + ptrModMask) >> ptrShift
+	if targetLen <= len(ptrs) {
+		return
+	}
+	if targetLen <= cap(ptrs) {
+		*p = ptrs[:targetLen]
+		clear((*p)[len(ptrs):])
+		return
+	}
+	*p = append(ptrs, make(Pointers, targetLen-len(ptrs))...)
+}
+
+// GrowAmortized ensures that the bitset p is large enough to hold numBits
+// number of bits, like Grow.  When the backing array must be reallocated,
+// its capacity is at least doubled, so growing a bitset a few bits at a time
+// performs an amortized constant amount of copying per bit rather than
+// copying the whole bitset on every call.
+func (p *Pointers) GrowAmortized(numBits int) {
+	ptrs := *p
+	numBits = max(numBits, 0)
 	targetLen := (numBits + ptrModMask) >> ptrShift
-	missing := targetLen - len(ptrs)
-	if missing > 0 && missing <= targetLen {
-		*p = append(ptrs, make(Pointers, missing)...)
+	if targetLen <= len(ptrs) {
+		return
+	}
+	if targetLen > cap(ptrs) {
+		grown := make(Pointers, targetLen, max(targetLen, 2*cap(ptrs)))
+		copy(grown, ptrs)
+		*p = grown
+		return
 	}
+	p.Grow(numBits)
+}
+
+// Cap returns the number of bits the bitset p can hold without reallocating
+// its backing array.
+func (p Pointers) Cap() int {
+	return cap(p) << ptrShift
+}
+
+// Truncate shrinks the bitset p to the number of pointers needed to hold
+// numBits number of bits, and unsets any bits at or beyond numBits in the
+// final retained pointer.  Dropped pointers are zeroed before the slice is
+// shortened so their bits cannot reappear if the backing array is later
+// reused.  If p is already too small to hold numBits bits, it is unchanged.
+// A negative numBits is treated as zero.
+func (p *Pointers) Truncate(numBits int) {
+	ptrs := *p
+	numBits = max(numBits, 0)
+	targetLen := (numBits + ptrModMask) >> ptrShift
+	if targetLen > len(ptrs) {
+		return
+	}
+	ptrs.clearFrom(numBits)
+	*p = ptrs[:targetLen]
+}
+
+// Compact removes trailing pointers with no set bits from p and returns the
+// number of bits the compacted bitset can hold.  If the remaining pointers
+// use less than half of the capacity of the backing array, they are copied
+// to a new array of the exact size so that the old one may be freed.
+func (p *Pointers) Compact() int {
+	ptrs := *p
+	n := len(ptrs)
+	for n > 0 && ptrs[n-1] == 0 {
+		n--
+	}
+	ptrs = ptrs[:n]
+	if n < cap(ptrs)/2 {
+		ptrs = ptrs.Clone()
+	}
+	*p = ptrs
+	return n << ptrShift
 }
 
 // Bytes represents a bitset backed by a bytes slice.  Bytes bitsets,
@@ -145,6 +280,27 @@ func NewBytes(numBits int) Bytes {
 	return make(Bytes, (numBits+byteModMask)>>byteShift)
 }
 
+// NewBytesCap returns a new bitset that is capable of holding numBits number
+// of binary values, with a backing array large enough to hold capBits bits.
+// The bitset may later be grown to capBits bits without reallocating.  If
+// capBits is less than numBits, numBits is used as the capacity.
+func NewBytesCap(numBits, capBits int) Bytes {
+	n := (numBits + byteModMask) >> byteShift
+	c := (capBits + byteModMask) >> byteShift
+	return make(Bytes, n, max(n, c))
+}
+
+// NewBytesFromIndices returns a new bitset with the bit at each index in
+// indices set.  The bitset is sized to hold exactly enough bytes for the
+// largest index.  This function will panic if any index is negative.
+func NewBytesFromIndices(indices []int) Bytes {
+	s := NewBytes(maxIndex(indices) + 1)
+	for _, i := range indices {
+		s.Set(i)
+	}
+	return s
+}
+
 // Get returns whether the bit at index i is set or not.  This method will
 // panic if the index results in a byte index that exceeds the number of
 // bytes held by the bitset.
@@ -152,6 +308,14 @@ func (s Bytes) Get(i int) bool {
 	return s[uint(i)>>byteShift]&(1<<(uint(i)&byteModMask)) != 0
 }
 
+// GetOrFalse returns whether the bit at index i is set or not.  Unlike Get,
+// it never panics: indexes outside of the allocated range, including
+// negative indexes, are reported as unset, matching the behavior of Sparse.
+func (s Bytes) GetOrFalse(i int) bool {
+	k := uint(i) >> byteShift
+	return k < uint(len(s)) && s[k]&(1<<(uint(i)&byteModMask)) != 0
+}
+
 // Set sets the bit at index i.  This method will panic if the index results
 // in a byte index that exceeds the number of a bytes held by the bitset.
 func (s Bytes) Set(i int) {
@@ -176,16 +340,122 @@ func (s Bytes) SetBool(i int, b bool) {
 	s.Unset(i)
 }
 
+// TestAndSet sets the bit at index i and returns whether it was previously
+// set.  This method will panic if the index results in a byte index that
+// exceeds the number of bytes held by the bitset.
+func (s Bytes) TestAndSet(i int) bool {
+	b := &s[uint(i)>>byteShift]
+	mask := byte(1) << (uint(i) & byteModMask)
+	old := *b
+	*b = old | mask
+	return old&mask != 0
+}
+
+// TestAndClear unsets the bit at index i and returns whether it was
+// previously set.  This method will panic if the index results in a byte
+// index that exceeds the number of bytes held by the bitset.
+func (s Bytes) TestAndClear(i int) bool {
+	b := &s[uint(i)>>byteShift]
+	mask := byte(1) << (uint(i) & byteModMask)
+	old := *b
+	*b = old &^ mask
+	return old&mask != 0
+}
+
+// Flip toggles the bit at index i.  This method will panic if the index
+// results in a byte index that exceeds the number of bytes held by the
+// bitset.
+func (s Bytes) Flip(i int) {
+	s[uint(i)>>byteShift] ^= 1 << (uint(i) & byteModMask)
+}
+
+// Clone returns a copy of s which does not share memory with s.
+func (s Bytes) Clone() Bytes {
+	c := make(Bytes, len(s))
+	copy(c, s)
+	return c
+}
+
 // Grow ensures that the bitset s is large enough to hold numBits number of
-// bits, potentially appending to and/or reallocating the slice if the
-// current length is not sufficient.
+// bits.  If the backing array has enough spare capacity, the slice is
+// extended in place and the reclaimed bytes are zeroed, so bits left behind
+// by an earlier Truncate or reslice never reappear as set.  Otherwise, the
+// slice is reallocated.
 func (s *Bytes) Grow(numBits int) {
 	bytes := *s
+	numBits = max(numBits, 0)
+	targetLen := (numBits + byteModMask) >> byteShift
+	if targetLen <= len(bytes) {
+		return
+	}
+	if targetLen <= cap(bytes) {
+		*s = bytes[:targetLen]
+		clear((*s)[len(bytes):])
+		return
+	}
+	*s = append(bytes, make(Bytes, targetLen-len(bytes))...)
+}
+
+// GrowAmortized ensures that the bitset s is large enough to hold numBits
+// number of bits, like Grow.  When the backing array must be reallocated,
+// its capacity is at least doubled, so growing a bitset a few bits at a time
+// performs an amortized constant amount of copying per bit rather than
+// copying the whole bitset on every call.
+func (s *Bytes) GrowAmortized(numBits int) {
+	bytes := *s
+	numBits = max(numBits, 0)
+	targetLen := (numBits + byteModMask) >> byteShift
+	if targetLen <= len(bytes) {
+		return
+	}
+	if targetLen > cap(bytes) {
+		grown := make(Bytes, targetLen, max(targetLen, 2*cap(bytes)))
+		copy(grown, bytes)
+		*s = grown
+		return
+	}
+	s.Grow(numBits)
+}
+
+// Cap returns the number of bits the bitset s can hold without reallocating
+// its backing array.
+func (s Bytes) Cap() int {
+	return cap(s) << byteShift
+}
+
+// Truncate shrinks the bitset s to the number of bytes needed to hold numBits
+// number of bits, and unsets any bits at or beyond numBits in the final
+// retained byte.  Dropped bytes are zeroed before the slice is shortened so
+// their bits cannot reappear if the backing array is later reused.  If s is
+// already too small to hold numBits bits, it is unchanged.  A negative
+// numBits is treated as zero.
+func (s *Bytes) Truncate(numBits int) {
+	bytes := *s
+	numBits = max(numBits, 0)
 	targetLen := (numBits + byteModMask) >> byteShift
-	missing := targetLen - len(bytes)
-	if missing > 0 && missing <= targetLen {
-		*s = append(bytes, make(Bytes, missing)...)
+	if targetLen > len(bytes) {
+		return
 	}
+	bytes.clearFrom(numBits)
+	*s = bytes[:targetLen]
+}
+
+// Compact removes trailing bytes with no set bits from s and returns the
+// number of bits the compacted bitset can hold.  If the remaining bytes use
+// less than half of the capacity of the backing array, they are copied to a
+// new array of the exact size so that the old one may be freed.
+func (s *Bytes) Compact() int {
+	bytes := *s
+	n := len(bytes)
+	for n > 0 && bytes[n-1] == 0 {
+		n--
+	}
+	bytes = bytes[:n]
+	if n < cap(bytes)/2 {
+		bytes = bytes.Clone()
+	}
+	*s = bytes
+	return n << byteShift
 }
 
 // Sparse is a memory efficient bitset for sparsly-distributed set bits.
@@ -195,9 +465,9 @@ func (s *Bytes) Grow(numBits int) {
 // implementation from this package which will dynamically expand and shrink
 // as bits are set and unset.
 //
-// As the map is unordered and there is no obvious way to (de)serialize this
-// structure, no byte implementation is provided, and all map values are
-// machine pointer-sized.
+// All map values are machine pointer-sized.  Sparse bitsets are serialized
+// with a compact encoding of their nonzero words, sorted by key, which is
+// independent of the pointer size (see MarshalBinary).
 //
 // As Sparse bitsets are backed by a map, getting and setting bits are
 // orders of magnitude slower than other slice-backed bitsets and should
@@ -209,6 +479,16 @@ func (s *Bytes) Grow(numBits int) {
 // New Sparse bitsets can be created using the builtin make function.
 type Sparse map[int]uintptr
 
+// NewSparseFromIndices returns a new Sparse bitset with the bit at each index
+// in indices set.
+func NewSparseFromIndices(indices []int) Sparse {
+	s := make(Sparse)
+	for _, i := range indices {
+		s.Set(i)
+	}
+	return s
+}
+
 // Get returns whether the bit at index i is set or not.
 func (s Sparse) Get(i int) bool {
 	return s[int(uint(i)>>ptrShift)]&(1<<(uint(i)&ptrModMask)) != 0
@@ -237,6 +517,46 @@ func (s Sparse) Unset(i int) {
 	}
 }
 
+// TestAndSet sets the bit at index i and returns whether it was previously
+// set.  The map is only written to if the bit was not already set.
+func (s Sparse) TestAndSet(i int) bool {
+	ptrKey := int(uint(i) >> ptrShift)
+	mask := uintptr(1) << (uint(i) & ptrModMask)
+	ptr := s[ptrKey]
+	if ptr&mask != 0 {
+		return true
+	}
+	s[ptrKey] = ptr | mask
+	return false
+}
+
+// TestAndClear unsets the bit at index i and returns whether it was
+// previously set.  As with Unset, the pointer is removed from the map if no
+// set bits remain.
+func (s Sparse) TestAndClear(i int) bool {
+	ptrKey := int(uint(i) >> ptrShift)
+	mask := uintptr(1) << (uint(i) & ptrModMask)
+	ptr := s[ptrKey]
+	if ptr&mask == 0 {
+		return false
+	}
+	s.andNotKey(ptrKey, ptr, mask)
+	return true
+}
+
+// Flip toggles the bit at index i.  As with Set and Unset, a map insert is
+// performed if no bits of the associated pointer were previously set, and the
+// pointer is removed from the map if flipping leaves it with no set bits.
+func (s Sparse) Flip(i int) {
+	ptrKey := int(uint(i) >> ptrShift)
+	ptr := s[ptrKey] ^ 1<<(uint(i)&ptrModMask)
+	if ptr == 0 {
+		delete(s, ptrKey)
+	} else {
+		s[ptrKey] = ptr
+	}
+}
+
 // SetBool sets the bit at index i if b is true, otherwise the bit is unset.
 // see the comments for the get and set methods for the memory allocation
 // rules that are followed when getting or setting bits in a Sparse bitset.
@@ -247,3 +567,39 @@ func (s Sparse) SetBool(i int, b bool) {
 	}
 	s.Unset(i)
 }
+
+// Truncate unsets every bit at or beyond index numBits, removing the map
+// entries of any pointers left with no set bits.
+func (s Sparse) Truncate(numBits int) {
+	full := int(uint(numBits) >> ptrShift)
+	mask := uintptr(1)<<(uint(numBits)&ptrModMask) - 1
+	for k, ptr := range s {
+		switch {
+		case k > full:
+			delete(s, k)
+		case k == full:
+			s.andNotKey(k, ptr, ^mask)
+		}
+	}
+}
+
+// Clone returns a copy of s backed by a new map.  The result is never nil,
+// even when s is, and so may always have bits set.
+func (s Sparse) Clone() Sparse {
+	c := make(Sparse, len(s))
+	for k, ptr := range s {
+		c[k] = ptr
+	}
+	return c
+}
+
+// maxIndex returns the largest index in indices, or -1 if indices is empty.
+func maxIndex(indices []int) int {
+	m := -1
+	for _, i := range indices {
+		if i > m {
+			m = i
+		}
+	}
+	return m
+}
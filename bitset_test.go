@@ -5,6 +5,7 @@
 package bitset_test
 
 import (
+	"reflect"
 	"testing"
 
 	. "github.com/jrick/bitset"
@@ -186,3 +187,299 @@ func TestNoSets(t *testing.T) {
 		}
 	}
 }
+
+func TestFlip(t *testing.T) {
+	type flipper interface {
+		BitSet
+		Flip(i int)
+	}
+	for _, nbs := range standardBitsets(128) {
+		bs := nbs.bitset.(flipper)
+		for _, i := range []int{0, 7, 63, 64, 127} {
+			bs.Flip(i)
+			if !bs.Get(i) {
+				t.Errorf("bitset %s: bit %d unset after first flip",
+					nbs.name, i)
+			}
+			bs.Flip(i)
+			if bs.Get(i) {
+				t.Errorf("bitset %s: bit %d set after second flip",
+					nbs.name, i)
+			}
+		}
+	}
+
+	s := make(Sparse)
+	s.Flip(100)
+	s.Flip(100)
+	if len(s) != 0 {
+		t.Errorf("Sparse: zero pointer retained after flipping")
+	}
+}
+
+func TestTestAndSetClear(t *testing.T) {
+	type tester interface {
+		BitSet
+		TestAndSet(i int) bool
+		TestAndClear(i int) bool
+	}
+	for _, nbs := range standardBitsets(128) {
+		bs := nbs.bitset.(tester)
+		for _, i := range []int{0, 9, 63, 64, 127} {
+			if bs.TestAndSet(i) {
+				t.Errorf("bitset %s: bit %d reported previously set",
+					nbs.name, i)
+			}
+			if !bs.TestAndSet(i) || !bs.Get(i) {
+				t.Errorf("bitset %s: bit %d not set", nbs.name, i)
+			}
+			if !bs.TestAndClear(i) {
+				t.Errorf("bitset %s: bit %d reported previously unset",
+					nbs.name, i)
+			}
+			if bs.TestAndClear(i) || bs.Get(i) {
+				t.Errorf("bitset %s: bit %d not cleared", nbs.name, i)
+			}
+		}
+	}
+}
+
+func TestClone(t *testing.T) {
+	p := NewPointers(128)
+	b := NewBytes(128)
+	s := make(Sparse)
+	for _, i := range []int{1, 64, 100} {
+		p.Set(i)
+		b.Set(i)
+		s.Set(i)
+	}
+	pc, bc, sc := p.Clone(), b.Clone(), s.Clone()
+	pc.Set(2)
+	bc.Set(2)
+	sc.Set(2)
+	pc.Unset(64)
+	bc.Unset(64)
+	sc.Unset(64)
+	for _, nbs := range []namedBitSet{{"Pointers", p}, {"Bytes", b}, {"Sparse", s}} {
+		if nbs.bitset.Get(2) || !nbs.bitset.Get(64) {
+			t.Errorf("bitset %s: modifying clone modified original", nbs.name)
+		}
+	}
+	for _, nbs := range []namedBitSet{{"Pointers", pc}, {"Bytes", bc}, {"Sparse", sc}} {
+		if !nbs.bitset.Get(1) || !nbs.bitset.Get(2) || nbs.bitset.Get(64) {
+			t.Errorf("bitset %s: clone has incorrect bits", nbs.name)
+		}
+	}
+
+	var nilSparse Sparse
+	nilSparse.Clone().Set(5)
+}
+
+func TestTruncate(t *testing.T) {
+	set := []int{0, 5, 63, 64, 70, 200}
+	tests := []struct {
+		numBits int
+		exp     []int
+	}{
+		{numBits: 300, exp: set},
+		{numBits: 201, exp: set},
+		{numBits: 200, exp: []int{0, 5, 63, 64, 70}},
+		{numBits: 65, exp: []int{0, 5, 63, 64}},
+		{numBits: 6, exp: []int{0, 5}},
+		{numBits: 0, exp: nil},
+	}
+	type truncater interface {
+		BitSet
+		ToSlice() []int
+	}
+	for testNum, test := range tests {
+		p := NewPointersFromIndices(set)
+		p.Truncate(test.numBits)
+		b := NewBytesFromIndices(set)
+		b.Truncate(test.numBits)
+		s := NewSparseFromIndices(set)
+		s.Truncate(test.numBits)
+		for _, c := range []struct {
+			name string
+			bs   truncater
+		}{{"Pointers", p}, {"Bytes", b}, {"Sparse", s}} {
+			got := c.bs.ToSlice()
+			if len(got) != len(test.exp) || (len(got) != 0 && !reflect.DeepEqual(got, test.exp)) {
+				t.Errorf("Test %d bitset %s: got %v expected %v",
+					testNum, c.name, got, test.exp)
+			}
+		}
+		if test.numBits <= 201 && len(b) != (test.numBits+7)/8 {
+			t.Errorf("Test %d bitset Bytes: got len %d", testNum, len(b))
+		}
+
+		// Regrowing must not resurrect truncated bits.
+		b.Grow(300)
+		if got := b.Count(); got != len(test.exp) {
+			t.Errorf("Test %d bitset Bytes: regrown count %d expected %d",
+				testNum, got, len(test.exp))
+		}
+		p.Grow(300)
+		if got := p.Count(); got != len(test.exp) {
+			t.Errorf("Test %d bitset Pointers: regrown count %d expected %d",
+				testNum, got, len(test.exp))
+		}
+	}
+
+	// Negative lengths truncate to an empty bitset.
+	for _, numBits := range []int{-1, -8, -9, -64, -65, -100} {
+		p := NewPointersFromIndices(set)
+		p.Truncate(numBits)
+		b := NewBytesFromIndices(set)
+		b.Truncate(numBits)
+		if len(p) != 0 || len(b) != 0 {
+			t.Errorf("Truncate(%d): got Pointers len %d, Bytes len %d",
+				numBits, len(p), len(b))
+		}
+		p.Grow(300)
+		b.Grow(300)
+		if p.Count() != 0 || b.Count() != 0 {
+			t.Errorf("Truncate(%d): regrown Pointers %v, Bytes %v",
+				numBits, p.ToSlice(), b.ToSlice())
+		}
+	}
+}
+
+func TestCompact(t *testing.T) {
+	p := NewPointers(64 * 100)
+	p.Set(3)
+	p.Set(70)
+	p.Unset(70)
+	if got := p.Compact(); got != len(p)*(64*100/len(NewPointers(64*100))) || len(p) != 1 {
+		t.Errorf("Pointers: Compact returned %d with len %d", got, len(p))
+	}
+	if cap(p) != 1 {
+		t.Errorf("Pointers: compacted capacity %d not reallocated", cap(p))
+	}
+	if !p.Get(3) {
+		t.Errorf("Pointers: compacted set lost bit 3")
+	}
+
+	b := NewBytes(16)
+	b.Set(9)
+	if got := b.Compact(); got != 16 || len(b) != 2 {
+		t.Errorf("Bytes: Compact returned %d with len %d", got, len(b))
+	}
+	b.Unset(9)
+	if got := b.Compact(); got != 0 || len(b) != 0 {
+		t.Errorf("Bytes: Compact of empty set returned %d with len %d",
+			got, len(b))
+	}
+}
+
+func TestGrowAmortized(t *testing.T) {
+	var b Bytes
+	reallocs := 0
+	for i := 0; i < 8*4096; i++ {
+		oldCap := b.Cap()
+		b.GrowAmortized(i + 1)
+		if b.Cap() != oldCap {
+			reallocs++
+		}
+		b.Set(i)
+	}
+	if b.Count() != 8*4096 {
+		t.Errorf("Bytes: count %d after growing", b.Count())
+	}
+	if reallocs > 20 {
+		t.Errorf("Bytes: %d reallocations growing one bit at a time", reallocs)
+	}
+
+	var p Pointers
+	reallocs = 0
+	for i := 0; i < 64*1024; i++ {
+		oldCap := p.Cap()
+		p.GrowAmortized(i + 1)
+		if p.Cap() != oldCap {
+			reallocs++
+		}
+		p.Set(i)
+	}
+	if p.Count() != 64*1024 {
+		t.Errorf("Pointers: count %d after growing", p.Count())
+	}
+	if reallocs > 20 {
+		t.Errorf("Pointers: %d reallocations growing one bit at a time", reallocs)
+	}
+}
+
+func TestGrowReusesCapacity(t *testing.T) {
+	p := NewPointers(256)
+	b := NewBytes(256)
+	for i := 0; i < 256; i++ {
+		p.Set(i)
+		b.Set(i)
+	}
+	p, b = p[:1], b[:1]
+	pCap, bCap := p.Cap(), b.Cap()
+	pCount := p.Count()
+	p.Grow(256)
+	b.Grow(256)
+	if p.Cap() != pCap {
+		t.Errorf("Pointers: Grow reallocated despite spare capacity")
+	}
+	if b.Cap() != bCap {
+		t.Errorf("Bytes: Grow reallocated despite spare capacity")
+	}
+	if got := p.Count(); got != pCount {
+		t.Errorf("Pointers: reclaimed bits not zeroed: count %d expected %d", got, pCount)
+	}
+	if got := b.Count(); got != 8 {
+		t.Errorf("Bytes: reclaimed bits not zeroed: count %d expected 8", got)
+	}
+}
+
+func TestNewCap(t *testing.T) {
+	tests := []struct {
+		numBits, capBits int
+		bLen             int
+	}{
+		{numBits: 0, capBits: 0, bLen: 0},
+		{numBits: 0, capBits: 1000, bLen: 0},
+		{numBits: 9, capBits: 1000, bLen: 2},
+		{numBits: 800, capBits: 10, bLen: 100},
+	}
+	for testNum, test := range tests {
+		p := NewPointersCap(test.numBits, test.capBits)
+		b := NewBytesCap(test.numBits, test.capBits)
+		if len(NewPointers(test.numBits)) != len(p) {
+			t.Errorf("Test %d Pointers: length %d does not match NewPointers",
+				testNum, len(p))
+		}
+		if len(b) != test.bLen {
+			t.Errorf("Test %d Bytes: length %d expected %d", testNum, len(b), test.bLen)
+		}
+		expCap := max(test.numBits, test.capBits)
+		if p.Cap() < expCap {
+			t.Errorf("Test %d Pointers: capacity %d less than %d", testNum, p.Cap(), expCap)
+		}
+		if b.Cap() < expCap {
+			t.Errorf("Test %d Bytes: capacity %d less than %d", testNum, b.Cap(), expCap)
+		}
+		pCap, bCap := p.Cap(), b.Cap()
+		p.Grow(expCap)
+		b.Grow(expCap)
+		if p.Cap() != pCap || b.Cap() != bCap {
+			t.Errorf("Test %d: Grow to reserved capacity reallocated", testNum)
+		}
+	}
+}
+
+func TestGetOrFalse(t *testing.T) {
+	set := []int{0, 9, 63, 64, 127}
+	p, b, sp := NewPointersFromIndices(set), NewBytesFromIndices(set), NewSparseFromIndices(set)
+	for _, i := range []int{-1000, -1, 0, 1, 9, 63, 64, 127, 128, 1 << 20} {
+		exp := sp.Get(i)
+		if got := p.GetOrFalse(i); got != exp {
+			t.Errorf("Pointers: bit %d got %v expected %v", i, got, exp)
+		}
+		if got := b.GetOrFalse(i); got != exp {
+			t.Errorf("Bytes: bit %d got %v expected %v", i, got, exp)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+import "errors"
+
+// ErrInvalidBitString describes an error where a bit string passed to
+// ParseBits contains a character other than '0', '1', or a separator.
+var ErrInvalidBitString = errors.New("bitset: invalid bit string")
+
+// A bit string is the human readable form of a bitset, with one '0' or '1'
+// character per bit.  Bits are written in increasing index order, so the
+// first character of the string is bit 0.  This is the reverse of the order
+// in which binary integer literals are written: the bitset with only bit 1
+// set is "01", not "10".
+
+// ParseBits returns a new Bytes bitset with the bits described by the bit
+// string s.  Underscores and spaces are ignored, and may be used to group
+// bits for readability, as in "0110_1001".  The bitset is sized to hold
+// every bit of the string.  If s contains any other character,
+// ErrInvalidBitString is returned.
+func ParseBits(s string) (Bytes, error) {
+	n := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '0', '1':
+			n++
+		case '_', ' ':
+		default:
+			return nil, ErrInvalidBitString
+		}
+	}
+	bs := NewBytes(n)
+	n = 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '1':
+			bs.Set(n)
+			fallthrough
+		case '0':
+			n++
+		}
+	}
+	return bs, nil
+}
+
+// appendBitString appends the bit string of the first numBits bits of bs to
+// dst and returns the extended slice.
+func appendBitString(dst []byte, bs BitSet, numBits int) []byte {
+	for i := 0; i < numBits; i++ {
+		if bs.Get(i) {
+			dst = append(dst, '1')
+		} else {
+			dst = append(dst, '0')
+		}
+	}
+	return dst
+}
+
+// BitString returns the bit string of the first numBits bits of p.  This
+// method will panic if numBits exceeds the number of bits held by the bitset.
+func (p Pointers) BitString(numBits int) string {
+	return string(appendBitString(make([]byte, 0, numBits), p, numBits))
+}
+
+// BitString returns the bit string of the first numBits bits of s.  This
+// method will panic if numBits exceeds the number of bits held by the bitset.
+func (s Bytes) BitString(numBits int) string {
+	return string(appendBitString(make([]byte, 0, numBits), s, numBits))
+}
+
+// BitString returns the bit string of the first numBits bits of s.
+func (s Sparse) BitString(numBits int) string {
+	return string(appendBitString(make([]byte, 0, numBits), s, numBits))
+}
+
+// BitString returns the bit string of every bit of d.
+func (d *Dense) BitString() string {
+	return string(appendBitString(make([]byte, 0, d.n), d.p, d.n))
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset_test
+
+import (
+	"testing"
+
+	. "github.com/jrick/bitset"
+)
+
+func TestParseBits(t *testing.T) {
+	tests := []struct {
+		s       string
+		numBits int
+		set     []int
+		err     error
+	}{
+		{s: "", numBits: 0, set: nil},
+		{s: "1", numBits: 1, set: []int{0}},
+		{s: "01", numBits: 2, set: []int{1}},
+		{s: "0110_1001 1", numBits: 9, set: []int{1, 2, 4, 7, 8}},
+		{s: "10x", err: ErrInvalidBitString},
+		{s: "0b01", err: ErrInvalidBitString},
+	}
+	for testNum, test := range tests {
+		bs, err := ParseBits(test.s)
+		if err != test.err {
+			t.Errorf("Test %d: got error %v expected %v", testNum, err, test.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if len(bs) != (test.numBits+7)/8 {
+			t.Errorf("Test %d: got %d bytes for %d bits", testNum, len(bs), test.numBits)
+		}
+		if got := bs.ToSlice(); !equalIndices(got, test.set) {
+			t.Errorf("Test %d: got %v expected %v", testNum, got, test.set)
+		}
+	}
+}
+
+func TestBitString(t *testing.T) {
+	const s = "1001000000000000000000000000000000000000000000000000000000000001001"
+	bs, err := ParseBits(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := bs.ToSlice()
+	d := NewDense(len(s))
+	for _, i := range set {
+		d.Set(i)
+	}
+	results := []struct {
+		name string
+		got  string
+	}{
+		{"Bytes", bs.BitString(len(s))},
+		{"Pointers", NewPointersFromIndices(set).BitString(len(s))},
+		{"Sparse", NewSparseFromIndices(set).BitString(len(s))},
+		{"Dense", d.BitString()},
+	}
+	for _, r := range results {
+		if r.got != s {
+			t.Errorf("bitset %s: got %s expected %s", r.name, r.got, s)
+		}
+	}
+	if got := bs.BitString(4); got != "1001" {
+		t.Errorf("prefix: got %s expected 1001", got)
+	}
+}
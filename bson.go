@@ -0,0 +1,147 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// ErrInvalidBSON describes an error where a BSON value could not be decoded
+// as a bitset because it was truncated, malformed, or of the wrong type.
+var ErrInvalidBSON = errors.New("bitset: invalid BSON bitset")
+
+// Bitsets are represented in BSON as a binary value (type 0x05) of the user
+// defined subtype 0x80, with the payload:
+//
+//	int64  little endian number of bits, n
+//	bytes  (n+7)/8 bytes using the layout of a Bytes bitset
+//
+// Bits at or beyond n in the final byte must be unset.  Recording the number
+// of bits allows a Dense bitset to be stored with its exact length.  A Sparse
+// bitset is stored through its highest nonzero pointer.  Bitsets may only be
+// stored when their bytes fit within a BSON document.
+//
+// The MarshalBSONValue and UnmarshalBSONValue methods match the
+// bson.ValueMarshaler and bson.ValueUnmarshaler interfaces of version 2 of
+// the MongoDB Go driver, so bitsets may be stored as fields of documents
+// without this package depending on the driver.
+const (
+	bsonBinary     = 0x05
+	bsonSubtype    = 0x80
+	bsonPayloadHdr = 8
+)
+
+// bsonMaxData is the largest number of bytes of a bitset which are encoded,
+// leaving room for the headers within a 16 MiB BSON document.
+const bsonMaxData = 16<<20 - 1<<10
+
+// MarshalBSONValue encodes s as a BSON binary value.  If its bytes exceed the
+// 16 MiB limit of a BSON document, ErrIndexOutOfRange is returned.
+func (s Bytes) MarshalBSONValue() (typ byte, data []byte, err error) {
+	return marshalBSON(s)
+}
+
+// UnmarshalBSONValue replaces the contents of s with the bits of a BSON
+// binary value.  If the value is not a valid bitset, ErrInvalidBSON is
+// returned and s is not modified.
+func (s *Bytes) UnmarshalBSONValue(typ byte, data []byte) error {
+	return unmarshalBSON(typ, data, maxInt, s)
+}
+
+// MarshalBSONValue encodes p as a BSON binary value.  If its bytes exceed the
+// 16 MiB limit of a BSON document, ErrIndexOutOfRange is returned.
+func (p Pointers) MarshalBSONValue() (typ byte, data []byte, err error) {
+	return marshalBSON(p)
+}
+
+// UnmarshalBSONValue replaces the contents of p with the bits of a BSON
+// binary value.  If the value is not a valid bitset, ErrInvalidBSON is
+// returned and p is not modified.
+func (p *Pointers) UnmarshalBSONValue(typ byte, data []byte) error {
+	return unmarshalBSON(typ, data, maxInt, p)
+}
+
+// MarshalBSONValue encodes s as a BSON binary value.  If its bits exceed the
+// 16 MiB limit of a BSON document, as they do for any Sparse bitset holding a
+// negative index, ErrIndexOutOfRange is returned without allocating them.
+func (s Sparse) MarshalBSONValue() (typ byte, data []byte, err error) {
+	return marshalBSON(s)
+}
+
+// UnmarshalBSONValue replaces the contents of s with the bits of a BSON
+// binary value, allocating the map if s is nil.  If the value is not a valid
+// bitset, ErrInvalidBSON is returned and s is not modified.
+func (s *Sparse) UnmarshalBSONValue(typ byte, data []byte) error {
+	return unmarshalBSON(typ, data, maxInt, s)
+}
+
+// MarshalBSONValue encodes d, including its length, as a BSON binary value.
+// If its bytes exceed the 16 MiB limit of a BSON document, ErrIndexOutOfRange
+// is returned.
+func (d *Dense) MarshalBSONValue() (typ byte, data []byte, err error) {
+	return marshalBSON(d)
+}
+
+// UnmarshalBSONValue replaces the contents and length of d with those of a
+// BSON binary value.  If the value is not a valid bitset, ErrInvalidBSON is
+// returned and d is not modified.
+func (d *Dense) UnmarshalBSONValue(typ byte, data []byte) error {
+	return unmarshalBSON(typ, data, maxInt, d)
+}
+
+// marshalBSON encodes bs, which must be a type supported by encodedParts, as
+// a BSON binary value.  Bitsets of more than bsonMaxData bytes result in
+// ErrIndexOutOfRange.
+func marshalBSON(bs BitSet) (byte, []byte, error) {
+	_, _, numBits, bits, err := encodedParts(bs, bsonMaxData)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(bits) > bsonMaxData {
+		return 0, nil, ErrIndexOutOfRange
+	}
+	data := make([]byte, 0, 5+bsonPayloadHdr+len(bits))
+	data = binary.LittleEndian.AppendUint32(data, uint32(bsonPayloadHdr+len(bits)))
+	data = append(data, bsonSubtype)
+	data = binary.LittleEndian.AppendUint64(data, uint64(numBits))
+	data = append(data, bits...)
+	return bsonBinary, data, nil
+}
+
+// unmarshalBSON decodes a BSON binary value, replacing the contents of bs,
+// which must be a *Dense or a type supported by replacers.  Encodings of more
+// than maxBits bits result in ErrTooLarge.
+func unmarshalBSON(typ byte, data []byte, maxBits int, bs BitSet) error {
+	fromBytes, _, ok := replacers(bs)
+	d, isDense := bs.(*Dense)
+	if !ok && !isDense {
+		return ErrUnsupportedType
+	}
+	if typ != bsonBinary || len(data) < 5+bsonPayloadHdr ||
+		uint64(binary.LittleEndian.Uint32(data)) != uint64(len(data)-5) ||
+		data[4] != bsonSubtype {
+		return ErrInvalidBSON
+	}
+	n := binary.LittleEndian.Uint64(data[5:])
+	bits := data[5+bsonPayloadHdr:]
+	if n > uint64(maxInt-byteModMask) ||
+		uint64(len(bits)) != (n+byteModMask)>>byteShift {
+		return ErrInvalidBSON
+	}
+	if n&byteModMask != 0 && bits[len(bits)-1]>>(n&byteModMask) != 0 {
+		return ErrInvalidBSON
+	}
+	if n > uint64(maxBits) {
+		return ErrTooLarge
+	}
+	if isDense {
+		d.p.setBytes(bits)
+		d.n = int(n)
+		return nil
+	}
+	fromBytes(bits)
+	return nil
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset_test
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/jrick/bitset"
+)
+
+func TestBSON(t *testing.T) {
+	d := NewDense(12)
+	d.Set(0)
+	d.Set(11)
+	typ, data, err := d.MarshalBSONValue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []byte{
+		0x0a, 0x00, 0x00, 0x00, // payload length
+		0x80,                                           // subtype
+		0x0c, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // number of bits
+		0x01, 0x08,
+	}
+	if typ != 0x05 || !bytes.Equal(data, exp) {
+		t.Errorf("Dense: got %02x %x expected 05 %x", typ, data, exp)
+	}
+	var d2 Dense
+	if err := d2.UnmarshalBSONValue(typ, data); err != nil {
+		t.Fatalf("Dense: %v", err)
+	}
+	if !d2.Equal(d) {
+		t.Errorf("Dense: got %v expected %v", &d2, d)
+	}
+
+	typ, data, _ = Bytes{0x81}.MarshalBSONValue()
+	exp = []byte{0x09, 0, 0, 0, 0x80, 0x08, 0, 0, 0, 0, 0, 0, 0, 0x81}
+	if !bytes.Equal(data, exp) {
+		t.Errorf("Bytes: got %x expected %x", data, exp)
+	}
+	var p Pointers
+	if err := p.UnmarshalBSONValue(typ, data); err != nil {
+		t.Fatalf("Pointers: %v", err)
+	}
+	if !p.Get(0) || !p.Get(7) || p.Count() != 2 {
+		t.Errorf("Pointers: unexpected bits %v", p)
+	}
+	typ, data, _ = p.MarshalBSONValue()
+	var s Sparse
+	if err := s.UnmarshalBSONValue(typ, data); err != nil {
+		t.Fatalf("Sparse: %v", err)
+	}
+	if !s.Get(0) || !s.Get(7) || s.Count() != 2 {
+		t.Errorf("Sparse: unexpected bits %v", s)
+	}
+	typ, data, _ = s.MarshalBSONValue()
+	var b Bytes
+	if err := b.UnmarshalBSONValue(typ, data); err != nil {
+		t.Fatalf("Bytes: %v", err)
+	}
+	if !b.Get(0) || !b.Get(7) || b.Count() != 2 {
+		t.Errorf("Bytes: unexpected bits %v", b)
+	}
+}
+
+func TestBSONSparseTooLarge(t *testing.T) {
+	for _, i := range []int{int(^uint(0)>>1) - 1, 1 << 30, -1} {
+		s := NewSparseFromIndices([]int{1, i})
+		if _, _, err := s.MarshalBSONValue(); err != ErrIndexOutOfRange {
+			t.Errorf("index %d: got error %v expected %v", i, err, ErrIndexOutOfRange)
+		}
+	}
+}
+
+func TestBSONTooLarge(t *testing.T) {
+	const numBits = 16 << 23 // 16 MiB
+	tests := []struct {
+		name string
+		bs   interface {
+			MarshalBSONValue() (byte, []byte, error)
+		}
+	}{
+		{"Bytes", NewBytes(numBits)},
+		{"Pointers", NewPointers(numBits)},
+		{"Dense", NewDense(numBits)},
+	}
+	for _, test := range tests {
+		if _, _, err := test.bs.MarshalBSONValue(); err != ErrIndexOutOfRange {
+			t.Errorf("%s: got error %v expected %v", test.name, err, ErrIndexOutOfRange)
+		}
+	}
+	if _, _, err := NewBytes(numBits >> 1).MarshalBSONValue(); err != nil {
+		t.Errorf("Bytes: unexpected error %v", err)
+	}
+}
+
+func TestBSONInvalid(t *testing.T) {
+	valid := []byte{0x09, 0, 0, 0, 0x80, 0x03, 0, 0, 0, 0, 0, 0, 0, 0x05}
+	tests := []struct {
+		name string
+		typ  byte
+		data []byte
+	}{
+		{"not binary", 0x02, valid},
+		{"short", 0x05, valid[:12]},
+		{"truncated", 0x05, valid[:13]},
+		{"subtype", 0x05, []byte{0x09, 0, 0, 0, 0x00, 0x03, 0, 0, 0, 0, 0, 0, 0, 0x05}},
+		{"length", 0x05, []byte{0x09, 0, 0, 0, 0x80, 0x09, 0, 0, 0, 0, 0, 0, 0, 0x05}},
+		{"padding", 0x05, []byte{0x09, 0, 0, 0, 0x80, 0x03, 0, 0, 0, 0, 0, 0, 0, 0x08}},
+		{"huge", 0x05, []byte{0x09, 0, 0, 0, 0x80, 0, 0, 0, 0, 0, 0, 0, 0x80, 0}},
+	}
+	for _, test := range tests {
+		d := NewDense(1)
+		if err := d.UnmarshalBSONValue(test.typ, test.data); err != ErrInvalidBSON {
+			t.Errorf("%s: got error %v expected %v", test.name, err, ErrInvalidBSON)
+		}
+		if d.Len() != 1 {
+			t.Errorf("%s: bitset modified on error", test.name)
+		}
+	}
+	var d Dense
+	if err := d.UnmarshalBSONValue(0x05, valid); err != nil || d.Len() != 3 || d.Count() != 2 {
+		t.Errorf("valid: got %v, %v", &d, err)
+	}
+	if err := (Limited{&d, 2}).UnmarshalBSONValue(0x05, valid); err != ErrTooLarge {
+		t.Errorf("Limited: got error %v expected %v", err, ErrTooLarge)
+	}
+}
@@ -0,0 +1,312 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+// SetAll sets every bit in the range [0, numBits) and unsets every bit at or
+// beyond numBits, so the bitset holds exactly the first numBits bits.  This
+// method will panic if numBits results in a pointer index that exceeds the
+// number of pointers held by the bitset.
+func (p Pointers) SetAll(numBits int) {
+	full := uint(numBits) >> ptrShift
+	for i := range p[:full] {
+		p[i] = ^uintptr(0)
+	}
+	tail := p[full:]
+	if rem := uint(numBits) & ptrModMask; rem != 0 {
+		tail[0] = 1<<rem - 1
+		tail = tail[1:]
+	}
+	clear(tail)
+}
+
+// ClearAll unsets every bit in the bitset without reallocating it.
+func (p Pointers) ClearAll() {
+	clear(p)
+}
+
+// SetAll sets every bit in the range [0, numBits) and unsets every bit at or
+// beyond numBits, so the bitset holds exactly the first numBits bits.  This
+// method will panic if numBits results in a byte index that exceeds the
+// number of bytes held by the bitset.
+func (s Bytes) SetAll(numBits int) {
+	full := uint(numBits) >> byteShift
+	for i := range s[:full] {
+		s[i] = 0xff
+	}
+	tail := s[full:]
+	if rem := uint(numBits) & byteModMask; rem != 0 {
+		tail[0] = 1<<rem - 1
+		tail = tail[1:]
+	}
+	clear(tail)
+}
+
+// ClearAll unsets every bit in the bitset without reallocating it.
+func (s Bytes) ClearAll() {
+	clear(s)
+}
+
+// SetAll sets every bit in the range [0, numBits) and unsets every bit at or
+// beyond numBits, so the bitset holds exactly the first numBits bits.  A map
+// entry is created for every pointer in the range.
+func (s Sparse) SetAll(numBits int) {
+	clear(s)
+	full := int(uint(numBits) >> ptrShift)
+	for k := 0; k < full; k++ {
+		s[k] = ^uintptr(0)
+	}
+	if rem := uint(numBits) & ptrModMask; rem != 0 {
+		s[full] = 1<<rem - 1
+	}
+}
+
+// ClearAll unsets every bit in the bitset by removing all map entries.
+func (s Sparse) ClearAll() {
+	clear(s)
+}
+
+// SetEvery sets every stride'th bit beginning at index start, through the end
+// of the bitset.  Strides smaller than the pointer size are performed a whole
+// pointer at a time using a precomputed mask of the repeating bit pattern.
+// This method will panic if stride is not positive.
+func (p Pointers) SetEvery(start, stride int) {
+	p.strided(start, stride, func(w int, mask uintptr) { p[w] |= mask })
+}
+
+// UnsetEvery unsets every stride'th bit beginning at index start, through the
+// end of the bitset.  Strides smaller than the pointer size are performed a
+// whole pointer at a time using a precomputed mask of the repeating bit
+// pattern.  This method will panic if stride is not positive.
+func (p Pointers) UnsetEvery(start, stride int) {
+	p.strided(start, stride, func(w int, mask uintptr) { p[w] &^= mask })
+}
+
+// strided calls fn with each pointer index and mask of the bits touched by a
+// strided operation.
+func (p Pointers) strided(start, stride int, fn func(w int, mask uintptr)) {
+	if stride <= 0 {
+		panic("bitset: stride must be positive")
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stride >= ptrBits {
+		// Stop before i+stride can overflow for very large strides.
+		limit := len(p) << ptrShift
+		for i := start; i < limit; i += stride {
+			fn(int(uint(i)>>ptrShift), 1<<(uint(i)&ptrModMask))
+			if stride >= limit-i {
+				break
+			}
+		}
+		return
+	}
+	var pattern uintptr
+	for j := 0; j < ptrBits; j += stride {
+		pattern |= 1 << uint(j)
+	}
+	off := start & ptrModMask
+	for w := int(uint(start) >> ptrShift); w < len(p); w++ {
+		fn(w, pattern<<uint(off))
+		off = (stride - (ptrBits-off)%stride) % stride
+	}
+}
+
+// SetEvery sets every stride'th bit beginning at index start, through the end
+// of the bitset.  Strides smaller than eight are performed a whole byte at a
+// time using a precomputed mask of the repeating bit pattern.  This method
+// will panic if stride is not positive.
+func (s Bytes) SetEvery(start, stride int) {
+	s.strided(start, stride, func(i int, mask byte) { s[i] |= mask })
+}
+
+// UnsetEvery unsets every stride'th bit beginning at index start, through the
+// end of the bitset.  Strides smaller than eight are performed a whole byte
+// at a time using a precomputed mask of the repeating bit pattern.  This
+// method will panic if stride is not positive.
+func (s Bytes) UnsetEvery(start, stride int) {
+	s.strided(start, stride, func(i int, mask byte) { s[i] &^= mask })
+}
+
+// strided calls fn with each byte index and mask of the bits touched by a
+// strided operation.
+func (s Bytes) strided(start, stride int, fn func(i int, mask byte)) {
+	if stride <= 0 {
+		panic("bitset: stride must be positive")
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stride >= 8 {
+		// Stop before i+stride can overflow for very large strides.
+		limit := len(s) << byteShift
+		for i := start; i < limit; i += stride {
+			fn(int(uint(i)>>byteShift), 1<<(uint(i)&byteModMask))
+			if stride >= limit-i {
+				break
+			}
+		}
+		return
+	}
+	var pattern byte
+	for j := 0; j < 8; j += stride {
+		pattern |= 1 << uint(j)
+	}
+	off := start & byteModMask
+	for i := int(uint(start) >> byteShift); i < len(s); i++ {
+		fn(i, pattern<<uint(off))
+		off = (stride - (8-off)%stride) % stride
+	}
+}
+
+// SetMany sets the bit at each index in indices.  This method will panic if
+// any index results in a pointer index that exceeds the number of pointers
+// held by the bitset, in which case bits at earlier indices will have already
+// been set.
+func (p Pointers) SetMany(indices []int) {
+	for _, i := range indices {
+		p[uint(i)>>ptrShift] |= 1 << (uint(i) & ptrModMask)
+	}
+}
+
+// UnsetMany unsets the bit at each index in indices.  This method will panic
+// if any index results in a pointer index that exceeds the number of pointers
+// held by the bitset, in which case bits at earlier indices will have already
+// been unset.
+func (p Pointers) UnsetMany(indices []int) {
+	for _, i := range indices {
+		p[uint(i)>>ptrShift] &^= 1 << (uint(i) & ptrModMask)
+	}
+}
+
+// SetMany sets the bit at each index in indices.  This method will panic if
+// any index results in a byte index that exceeds the number of bytes held by
+// the bitset, in which case bits at earlier indices will have already been
+// set.
+func (s Bytes) SetMany(indices []int) {
+	for _, i := range indices {
+		s[uint(i)>>byteShift] |= 1 << (uint(i) & byteModMask)
+	}
+}
+
+// UnsetMany unsets the bit at each index in indices.  This method will panic
+// if any index results in a byte index that exceeds the number of bytes held
+// by the bitset, in which case bits at earlier indices will have already been
+// unset.
+func (s Bytes) UnsetMany(indices []int) {
+	for _, i := range indices {
+		s[uint(i)>>byteShift] &^= 1 << (uint(i) & byteModMask)
+	}
+}
+
+// SetMany sets the bit at each index in indices.
+func (s Sparse) SetMany(indices []int) {
+	for _, i := range indices {
+		s.Set(i)
+	}
+}
+
+// UnsetMany unsets the bit at each index in indices.  Pointers left with no
+// set bits are removed from the map.
+func (s Sparse) UnsetMany(indices []int) {
+	for _, i := range indices {
+		s.Unset(i)
+	}
+}
+
+// FlipMany toggles the bit at each index in indices.  An index appearing
+// more than once is toggled once for each appearance.  This method will panic
+// if any index results in a pointer index that exceeds the number of pointers
+// held by the bitset, in which case bits at earlier indices will have already
+// been toggled.
+func (p Pointers) FlipMany(indices []int) {
+	for _, i := range indices {
+		p[uint(i)>>ptrShift] ^= 1 << (uint(i) & ptrModMask)
+	}
+}
+
+// FlipMany toggles the bit at each index in indices.  An index appearing
+// more than once is toggled once for each appearance.  This method will panic
+// if any index results in a byte index that exceeds the number of bytes held
+// by the bitset, in which case bits at earlier indices will have already been
+// toggled.
+func (s Bytes) FlipMany(indices []int) {
+	for _, i := range indices {
+		s[uint(i)>>byteShift] ^= 1 << (uint(i) & byteModMask)
+	}
+}
+
+// FlipMany toggles the bit at each index in indices.  An index appearing
+// more than once is toggled once for each appearance.
+func (s Sparse) FlipMany(indices []int) {
+	for _, i := range indices {
+		s.Flip(i)
+	}
+}
+
+// SetSorted sets the bit at each index in indices, which should be sorted in
+// increasing order.  Consecutive indices falling in the same pointer are
+// accumulated into a single mask which is stored with one OR.  Unsorted
+// indices are still set correctly, but with less benefit.  This method will
+// panic if any index results in a pointer index that exceeds the number of
+// pointers held by the bitset.
+func (p Pointers) SetSorted(indices []int) {
+	if len(indices) == 0 {
+		return
+	}
+	w := uint(indices[0]) >> ptrShift
+	var mask uintptr
+	for _, i := range indices {
+		if iw := uint(i) >> ptrShift; iw != w {
+			p[w] |= mask
+			w, mask = iw, 0
+		}
+		mask |= 1 << (uint(i) & ptrModMask)
+	}
+	p[w] |= mask
+}
+
+// SetSorted sets the bit at each index in indices, which should be sorted in
+// increasing order.  Consecutive indices falling in the same byte are
+// accumulated into a single mask which is stored with one OR.  Unsorted
+// indices are still set correctly, but with less benefit.  This method will
+// panic if any index results in a byte index that exceeds the number of bytes
+// held by the bitset.
+func (s Bytes) SetSorted(indices []int) {
+	if len(indices) == 0 {
+		return
+	}
+	b := uint(indices[0]) >> byteShift
+	var mask byte
+	for _, i := range indices {
+		if ib := uint(i) >> byteShift; ib != b {
+			s[b] |= mask
+			b, mask = ib, 0
+		}
+		mask |= 1 << (uint(i) & byteModMask)
+	}
+	s[b] |= mask
+}
+
+// SetSorted sets the bit at each index in indices, which should be sorted in
+// increasing order.  Consecutive indices falling in the same pointer are
+// accumulated into a single mask so that only one map update is performed
+// per pointer.  Unsorted indices are still set correctly, but with less
+// benefit.
+func (s Sparse) SetSorted(indices []int) {
+	if len(indices) == 0 {
+		return
+	}
+	key := int(uint(indices[0]) >> ptrShift)
+	var mask uintptr
+	for _, i := range indices {
+		if ik := int(uint(i) >> ptrShift); ik != key {
+			s[key] |= mask
+			key, mask = ik, 0
+		}
+		mask |= 1 << (uint(i) & ptrModMask)
+	}
+	s[key] |= mask
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset_test
+
+import (
+	"testing"
+
+	. "github.com/jrick/bitset"
+)
+
+type bulkSetter interface {
+	BitSet
+	SetAll(numBits int)
+	ClearAll()
+	ToSlice() []int
+}
+
+func bulkSetters(numBits int) []struct {
+	name string
+	bs   bulkSetter
+} {
+	return []struct {
+		name string
+		bs   bulkSetter
+	}{
+		{"Pointers", NewPointers(numBits)},
+		{"Bytes", NewBytes(numBits)},
+		{"Sparse", make(Sparse)},
+	}
+}
+
+func TestSetAllClearAll(t *testing.T) {
+	const capBits = 256
+	for _, numBits := range []int{0, 1, 7, 8, 63, 64, 65, 200, 256} {
+		for _, c := range bulkSetters(capBits) {
+			c.bs.Set(capBits - 1)
+			c.bs.SetAll(numBits)
+			if got := c.bs.ToSlice(); !equalIndices(got, seq(0, numBits)) {
+				t.Errorf("bitset %s: SetAll(%d) got %v", c.name,
+					numBits, got)
+			}
+			c.bs.ClearAll()
+			if got := c.bs.ToSlice(); len(got) != 0 {
+				t.Errorf("bitset %s: ClearAll left %v set", c.name, got)
+			}
+		}
+	}
+}
+
+func TestSetUnsetEvery(t *testing.T) {
+	const numBits = 256
+	type strider interface {
+		BitSet
+		SetEvery(start, stride int)
+		UnsetEvery(start, stride int)
+	}
+	const maxInt = int(^uint(0) >> 1)
+	strides := []int{1, 2, 3, 5, 7, 8, 9, 31, 32, 33, 63, 64, 65, 100,
+		maxInt - 1, maxInt}
+	for _, stride := range strides {
+		for _, start := range []int{0, 1, 6, 64, 70, 255, 300} {
+			for _, c := range []struct {
+				name string
+				bs   strider
+			}{
+				{"Pointers", NewPointers(numBits)},
+				{"Bytes", NewBytes(numBits)},
+			} {
+				c.bs.SetEvery(start, stride)
+				for i := 0; i < numBits; i++ {
+					exp := i >= start && (i-start)%stride == 0
+					if got := c.bs.Get(i); got != exp {
+						t.Fatalf("bitset %s: SetEvery(%d, %d): bit %d got %v expected %v",
+							c.name, start, stride, i, got, exp)
+					}
+				}
+				for i := 0; i < numBits; i++ {
+					c.bs.Set(i)
+				}
+				c.bs.UnsetEvery(start, stride)
+				for i := 0; i < numBits; i++ {
+					exp := !(i >= start && (i-start)%stride == 0)
+					if got := c.bs.Get(i); got != exp {
+						t.Fatalf("bitset %s: UnsetEvery(%d, %d): bit %d got %v expected %v",
+							c.name, start, stride, i, got, exp)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestSetUnsetMany(t *testing.T) {
+	type manySetter interface {
+		bulkSetter
+		SetMany(indices []int)
+		UnsetMany(indices []int)
+	}
+	set := []int{300, 0, 64, 7, 8, 511, 64}
+	unset := []int{64, 511, 1}
+	exp := []int{0, 7, 8, 300}
+	for _, c := range bulkSetters(512) {
+		bs := c.bs.(manySetter)
+		bs.SetMany(set)
+		bs.UnsetMany(unset)
+		if got := bs.ToSlice(); !equalIndices(got, exp) {
+			t.Errorf("bitset %s: got %v expected %v", c.name, got, exp)
+		}
+	}
+}
+
+func TestFlipMany(t *testing.T) {
+	type manyFlipper interface {
+		bulkSetter
+		FlipMany(indices []int)
+	}
+	flips := []int{1, 2, 3, 2, 64, 200, 200, 200}
+	exp := []int{1, 3, 64, 200}
+	for _, c := range bulkSetters(256) {
+		bs := c.bs.(manyFlipper)
+		bs.FlipMany(flips)
+		if got := bs.ToSlice(); !equalIndices(got, exp) {
+			t.Errorf("bitset %s: got %v expected %v", c.name, got, exp)
+		}
+		bs.FlipMany(exp)
+		if got := bs.ToSlice(); len(got) != 0 {
+			t.Errorf("bitset %s: got %v after flipping back", c.name, got)
+		}
+	}
+}
+
+func TestSetSorted(t *testing.T) {
+	type sortedSetter interface {
+		bulkSetter
+		SetSorted(indices []int)
+	}
+	tests := []struct {
+		indices []int
+		exp     []int
+	}{
+		{indices: nil, exp: nil},
+		{indices: []int{0, 1, 2, 63, 64, 65, 300, 511}, exp: []int{0, 1, 2, 63, 64, 65, 300, 511}},
+		{indices: []int{5, 5, 5}, exp: []int{5}},
+		{indices: []int{300, 2, 301, 1}, exp: []int{1, 2, 300, 301}},
+	}
+	for testNum, test := range tests {
+		for _, c := range bulkSetters(512) {
+			bs := c.bs.(sortedSetter)
+			bs.Set(100)
+			bs.SetSorted(test.indices)
+			bs.Unset(100)
+			if got := bs.ToSlice(); !equalIndices(got, test.exp) {
+				t.Errorf("Test %d bitset %s: got %v expected %v",
+					testNum, c.name, got, test.exp)
+			}
+		}
+	}
+}
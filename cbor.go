@@ -0,0 +1,189 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+import (
+	"encoding/binary"
+	"errors"
+	"iter"
+)
+
+// ErrInvalidCBOR describes an error where a CBOR data item could not be
+// decoded as a bitset because it was truncated, malformed, or of the wrong
+// type.
+var ErrInvalidCBOR = errors.New("bitset: invalid CBOR bitset")
+
+// Bitsets are represented in CBOR (RFC 8949) in one of two forms, mirroring
+// their JSON representations:
+//
+//   - A definite length byte string (major type 2) holding the bitset's bytes
+//     using the layout of a Bytes bitset.  This is the form produced by
+//     Pointers and Bytes.
+//   - A definite length array (major type 4) of unsigned integers (major type
+//     0) holding the indexes of all set bits in increasing order.  This is
+//     the form produced by Sparse.
+//
+// The UnmarshalCBOR methods accept either form, and limit the indexes
+// decoded into Pointers and Bytes bitsets as described for JSON.  Encoders
+// always use the shortest form of each length and integer, as required for
+// deterministic encoding.  The MarshalCBOR and UnmarshalCBOR methods match
+// the interfaces used by common CBOR packages, so bitsets may be embedded in
+// their encoded values without this package depending on any of them.
+
+const (
+	cborUint       = 0
+	cborByteString = 2
+	cborArray      = 4
+)
+
+// MarshalCBOR encodes s as a CBOR byte string.  It never returns an error.
+func (s Bytes) MarshalCBOR() ([]byte, error) {
+	b := appendCBORHead(make([]byte, 0, 9+len(s)), cborByteString, uint64(len(s)))
+	return append(b, s...), nil
+}
+
+// UnmarshalCBOR replaces the contents of s with the bits encoded by a CBOR
+// byte string or array of indexes.  If the data is not a single valid data
+// item of either form, ErrInvalidCBOR is returned and s is not modified.
+func (s *Bytes) UnmarshalCBOR(data []byte) error {
+	return unmarshalCBOR(data, maxInt, s)
+}
+
+// MarshalCBOR encodes p as a CBOR byte string of its binary encoding.  It
+// never returns an error.
+func (p Pointers) MarshalCBOR() ([]byte, error) {
+	n := len(p) * (ptrBits / 8)
+	b := appendCBORHead(make([]byte, 0, 9+n), cborByteString, uint64(n))
+	return p.appendBytes(b), nil
+}
+
+// UnmarshalCBOR replaces the contents of p with the bits encoded by a CBOR
+// byte string or array of indexes.  If the data is not a single valid data
+// item of either form, ErrInvalidCBOR is returned and p is not modified.
+func (p *Pointers) UnmarshalCBOR(data []byte) error {
+	return unmarshalCBOR(data, maxInt, p)
+}
+
+// MarshalCBOR encodes s as a CBOR array of the indexes of its set bits.  If
+// any bit is set at a negative index, which cannot be encoded as an unsigned
+// integer, ErrIndexOutOfRange is returned.
+func (s Sparse) MarshalCBOR() ([]byte, error) {
+	return appendCBORIndexes(nil, s.Count(), s.Ones())
+}
+
+// UnmarshalCBOR replaces the contents of s with the bits encoded by a CBOR
+// byte string or array of indexes, allocating the map if s is nil.  If the
+// data is not a single valid data item of either form, ErrInvalidCBOR is
+// returned and s is not modified.
+func (s *Sparse) UnmarshalCBOR(data []byte) error {
+	return unmarshalCBOR(data, maxInt, s)
+}
+
+// appendCBORHead appends the head of a CBOR data item with the major type and
+// argument v, using the shortest possible encoding.
+func appendCBORHead(b []byte, major byte, v uint64) []byte {
+	major <<= 5
+	switch {
+	case v < 24:
+		return append(b, major|byte(v))
+	case v <= 0xff:
+		return append(b, major|24, byte(v))
+	case v <= 0xffff:
+		return binary.BigEndian.AppendUint16(append(b, major|25), uint16(v))
+	case v <= 0xffffffff:
+		return binary.BigEndian.AppendUint32(append(b, major|26), uint32(v))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, major|27), v)
+	}
+}
+
+// appendCBORIndexes appends a CBOR array of the n indexes yielded by ones.
+// Negative indexes result in ErrIndexOutOfRange.
+func appendCBORIndexes(b []byte, n int, ones iter.Seq[int]) ([]byte, error) {
+	b = appendCBORHead(b, cborArray, uint64(n))
+	for i := range ones {
+		if i < 0 {
+			return nil, ErrIndexOutOfRange
+		}
+		b = appendCBORHead(b, cborUint, uint64(i))
+	}
+	return b, nil
+}
+
+// readCBORHead parses the head of the CBOR data item at the start of data,
+// returning its major type and argument and the remaining data.  Indefinite
+// lengths, simple values, and floats are rejected.
+func readCBORHead(data []byte) (major byte, v uint64, rest []byte, err error) {
+	if len(data) == 0 {
+		return 0, 0, nil, ErrInvalidCBOR
+	}
+	major, info := data[0]>>5, data[0]&0x1f
+	data = data[1:]
+	switch {
+	case info < 24:
+		return major, uint64(info), data, nil
+	case info > 27:
+		return 0, 0, nil, ErrInvalidCBOR
+	}
+	n := 1 << (info - 24)
+	if len(data) < n {
+		return 0, 0, nil, ErrInvalidCBOR
+	}
+	for _, b := range data[:n] {
+		v = v<<8 | uint64(b)
+	}
+	return major, v, data[n:], nil
+}
+
+// unmarshalCBOR decodes data as either a CBOR byte string or an array of
+// indexes, replacing the contents of bs.  Encodings of more than maxBits bits
+// result in ErrTooLarge.
+func unmarshalCBOR(data []byte, maxBits int, bs BitSet) error {
+	fromBytes, fromIndexes, ok := replacers(bs)
+	if !ok {
+		return ErrUnsupportedType
+	}
+	limit := indexLimit(bs, maxBits)
+	major, n, data, err := readCBORHead(data)
+	if err != nil {
+		return err
+	}
+	switch major {
+	case cborByteString:
+		if n != uint64(len(data)) {
+			return ErrInvalidCBOR
+		}
+		if len(data) > maxBits>>byteShift {
+			return ErrTooLarge
+		}
+		fromBytes(data)
+		return nil
+	case cborArray:
+		// Each index requires at least one byte, which bounds the
+		// allocation made for a hostile length.
+		if n > uint64(len(data)) {
+			return ErrInvalidCBOR
+		}
+		indices := make([]int, n)
+		for j := range indices {
+			var i uint64
+			major, i, data, err = readCBORHead(data)
+			if err != nil || major != cborUint {
+				return ErrInvalidCBOR
+			}
+			if i >= uint64(limit) {
+				return ErrTooLarge
+			}
+			indices[j] = int(i)
+		}
+		if len(data) != 0 {
+			return ErrInvalidCBOR
+		}
+		fromIndexes(indices)
+		return nil
+	default:
+		return ErrInvalidCBOR
+	}
+}
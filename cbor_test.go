@@ -0,0 +1,118 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset_test
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/jrick/bitset"
+)
+
+func TestCBOR(t *testing.T) {
+	set := []int{0, 9, 23, 24, 255, 256, 65536}
+	b := NewBytesFromIndices(set)
+
+	enc, err := b.MarshalCBOR()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Byte string with a two byte length.
+	if exp := []byte{0x59, 0x20, 0x01}; !bytes.Equal(enc[:3], exp) || len(enc) != 3+len(b) {
+		t.Errorf("Bytes: got head %x expected %x", enc[:3], exp)
+	}
+
+	enc, err = NewSparseFromIndices(set).MarshalCBOR()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []byte{0x87, 0x00, 0x09, 0x17, 0x18, 0x18, 0x18, 0xff,
+		0x19, 0x01, 0x00, 0x1a, 0x00, 0x01, 0x00, 0x00}
+	if !bytes.Equal(enc, exp) {
+		t.Errorf("Sparse: got %x expected %x", enc, exp)
+	}
+
+	// Each type decodes both forms.
+	bytesEnc, _ := b.MarshalCBOR()
+	ptrsEnc, _ := NewPointersFromIndices(set).MarshalCBOR()
+	for _, data := range [][]byte{bytesEnc, ptrsEnc, exp} {
+		var (
+			gb Bytes
+			gp Pointers
+			gs Sparse
+		)
+		if err := gb.UnmarshalCBOR(data); err != nil {
+			t.Fatalf("Bytes: %v", err)
+		}
+		if err := gp.UnmarshalCBOR(data); err != nil {
+			t.Fatalf("Pointers: %v", err)
+		}
+		if err := gs.UnmarshalCBOR(data); err != nil {
+			t.Fatalf("Sparse: %v", err)
+		}
+		for _, got := range [][]int{gb.ToSlice(), gp.ToSlice(), gs.ToSlice()} {
+			if !equalIndices(got, set) {
+				t.Errorf("decoding %x: got %v expected %v", data[:3], got, set)
+			}
+		}
+	}
+}
+
+func TestCBORInvalid(t *testing.T) {
+	tests := [][]byte{
+		nil,
+		{0x41},             // truncated byte string
+		{0x41, 0x01, 0x02}, // trailing data
+		{0x5f, 0x41, 0x01, 0xff},
+		{0x82, 0x01},       // truncated array
+		{0x81, 0x20},       // negative index
+		{0x81, 0x41, 0x01}, // non-integer index
+		{0x9b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x00},
+		{0x01},       // unsigned integer
+		{0x19, 0x01}, // truncated argument
+		{0xa0},       // map
+	}
+	for testNum, data := range tests {
+		s := NewBytesFromIndices([]int{3})
+		if err := s.UnmarshalCBOR(data); err != ErrInvalidCBOR {
+			t.Errorf("Test %d: got error %v expected %v", testNum, err, ErrInvalidCBOR)
+		}
+		if got := s.ToSlice(); !equalIndices(got, []int{3}) {
+			t.Errorf("Test %d: bitset modified by failed decode", testNum)
+		}
+	}
+}
+
+func TestCBORHugeIndex(t *testing.T) {
+	// An array of the single index 9000000000000000000.
+	data := []byte{0x81, 0x1b, 0x7c, 0xe6, 0x6c, 0x50, 0xe2, 0x84, 0x00, 0x00}
+	var b Bytes
+	if err := b.UnmarshalCBOR(data); err != ErrTooLarge {
+		t.Errorf("Bytes: got error %v expected %v", err, ErrTooLarge)
+	}
+	var p Pointers
+	if err := p.UnmarshalCBOR([]byte{0x81, 0x1a, 0x10, 0x00, 0x00, 0x00}); err != ErrTooLarge {
+		t.Errorf("Pointers: got error %v expected %v", err, ErrTooLarge)
+	}
+	if err := p.UnmarshalCBOR([]byte{0x81, 0x1a, 0x0f, 0xff, 0xff, 0xff}); err != nil {
+		t.Errorf("Pointers: unexpected error %v", err)
+	}
+	if err := (Limited{&b, 1 << 29}).UnmarshalCBOR([]byte{0x81, 0x1a, 0x10, 0x00, 0x00, 0x00}); err != nil {
+		t.Errorf("Limited: unexpected error %v", err)
+	}
+}
+
+func TestCBORNegativeIndex(t *testing.T) {
+	s := NewSparseFromIndices([]int{3})
+	s.Set(-5)
+	if _, err := s.MarshalCBOR(); err != ErrIndexOutOfRange {
+		t.Errorf("got error %v expected %v", err, ErrIndexOutOfRange)
+	}
+	s.Unset(-5)
+	data, err := s.MarshalCBOR()
+	if err != nil || !bytes.Equal(data, []byte{0x81, 0x03}) {
+		t.Errorf("got %x (err %v) expected 8103", data, err)
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+import "errors"
+
+// ErrIndexOutOfRange describes an error where a bit index is negative or
+// beyond the range of bits allocated by a bitset.
+var ErrIndexOutOfRange = errors.New("bitset: index out of range")
+
+// inRange returns whether the bit index i is addressable by p.
+func (p Pointers) inRange(i int) bool {
+	return i >= 0 && uint(i)>>ptrShift < uint(len(p))
+}
+
+// TryGet returns whether the bit at index i is set or not.  If the index is
+// out of range, ErrIndexOutOfRange is returned rather than panicking.
+func (p Pointers) TryGet(i int) (bool, error) {
+	if !p.inRange(i) {
+		return false, ErrIndexOutOfRange
+	}
+	return p.Get(i), nil
+}
+
+// TrySet sets the bit at index i.  If the index is out of range,
+// ErrIndexOutOfRange is returned rather than panicking.
+func (p Pointers) TrySet(i int) error {
+	if !p.inRange(i) {
+		return ErrIndexOutOfRange
+	}
+	p.Set(i)
+	return nil
+}
+
+// TryUnset unsets the bit at index i.  If the index is out of range,
+// ErrIndexOutOfRange is returned rather than panicking.
+func (p Pointers) TryUnset(i int) error {
+	if !p.inRange(i) {
+		return ErrIndexOutOfRange
+	}
+	p.Unset(i)
+	return nil
+}
+
+// TrySetBool sets or unsets the bit at index i depending on the value of b.
+// If the index is out of range, ErrIndexOutOfRange is returned rather than
+// panicking.
+func (p Pointers) TrySetBool(i int, b bool) error {
+	if !p.inRange(i) {
+		return ErrIndexOutOfRange
+	}
+	p.SetBool(i, b)
+	return nil
+}
+
+// inRange returns whether the bit index i is addressable by s.
+func (s Bytes) inRange(i int) bool {
+	return i >= 0 && uint(i)>>byteShift < uint(len(s))
+}
+
+// TryGet returns whether the bit at index i is set or not.  If the index is
+// out of range, ErrIndexOutOfRange is returned rather than panicking.
+func (s Bytes) TryGet(i int) (bool, error) {
+	if !s.inRange(i) {
+		return false, ErrIndexOutOfRange
+	}
+	return s.Get(i), nil
+}
+
+// TrySet sets the bit at index i.  If the index is out of range,
+// ErrIndexOutOfRange is returned rather than panicking.
+func (s Bytes) TrySet(i int) error {
+	if !s.inRange(i) {
+		return ErrIndexOutOfRange
+	}
+	s.Set(i)
+	return nil
+}
+
+// TryUnset unsets the bit at index i.  If the index is out of range,
+// ErrIndexOutOfRange is returned rather than panicking.
+func (s Bytes) TryUnset(i int) error {
+	if !s.inRange(i) {
+		return ErrIndexOutOfRange
+	}
+	s.Unset(i)
+	return nil
+}
+
+// TrySetBool sets or unsets the bit at index i depending on the value of b.
+// If the index is out of range, ErrIndexOutOfRange is returned rather than
+// panicking.
+func (s Bytes) TrySetBool(i int, b bool) error {
+	if !s.inRange(i) {
+		return ErrIndexOutOfRange
+	}
+	s.SetBool(i, b)
+	return nil
+}
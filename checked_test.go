@@ -0,0 +1,68 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset_test
+
+import (
+	"testing"
+
+	. "github.com/jrick/bitset"
+)
+
+type checked interface {
+	TryGet(i int) (bool, error)
+	TrySet(i int) error
+	TryUnset(i int) error
+	TrySetBool(i int, b bool) error
+}
+
+func TestChecked(t *testing.T) {
+	bitsets := []struct {
+		name    string
+		bs      checked
+		numBits int
+	}{
+		{"Pointers", NewPointers(128), NewPointers(128).Cap()},
+		{"Bytes", NewBytes(100), 104},
+	}
+	for _, nbs := range bitsets {
+		for _, i := range []int{-1 << 30, -1, nbs.numBits, nbs.numBits + 1, 1 << 30} {
+			if _, err := nbs.bs.TryGet(i); err != ErrIndexOutOfRange {
+				t.Errorf("bitset %s: TryGet(%d) got error %v expected %v",
+					nbs.name, i, err, ErrIndexOutOfRange)
+			}
+			if err := nbs.bs.TrySet(i); err != ErrIndexOutOfRange {
+				t.Errorf("bitset %s: TrySet(%d) got error %v expected %v",
+					nbs.name, i, err, ErrIndexOutOfRange)
+			}
+			if err := nbs.bs.TryUnset(i); err != ErrIndexOutOfRange {
+				t.Errorf("bitset %s: TryUnset(%d) got error %v expected %v",
+					nbs.name, i, err, ErrIndexOutOfRange)
+			}
+			if err := nbs.bs.TrySetBool(i, true); err != ErrIndexOutOfRange {
+				t.Errorf("bitset %s: TrySetBool(%d) got error %v expected %v",
+					nbs.name, i, err, ErrIndexOutOfRange)
+			}
+		}
+		for _, i := range []int{0, 1, 63, nbs.numBits - 1} {
+			if err := nbs.bs.TrySet(i); err != nil {
+				t.Errorf("bitset %s: TrySet(%d): %v", nbs.name, i, err)
+			}
+			if v, err := nbs.bs.TryGet(i); err != nil || !v {
+				t.Errorf("bitset %s: TryGet(%d) got %v, %v expected true, nil",
+					nbs.name, i, v, err)
+			}
+			if err := nbs.bs.TrySetBool(i, false); err != nil {
+				t.Errorf("bitset %s: TrySetBool(%d): %v", nbs.name, i, err)
+			}
+			if v, err := nbs.bs.TryGet(i); err != nil || v {
+				t.Errorf("bitset %s: TryGet(%d) got %v, %v expected false, nil",
+					nbs.name, i, v, err)
+			}
+			if err := nbs.bs.TryUnset(i); err != nil {
+				t.Errorf("bitset %s: TryUnset(%d): %v", nbs.name, i, err)
+			}
+		}
+	}
+}
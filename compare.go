@@ -0,0 +1,429 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+import "math/bits"
+
+// Intersects returns whether p and other have any set bit in common.  It
+// returns as soon as a pair of pointers with a nonzero intersection is found.
+func (p Pointers) Intersects(other Pointers) bool {
+	n := min(len(p), len(other))
+	for i, ptr := range other[:n] {
+		if p[i]&ptr != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Intersects returns whether s and other have any set bit in common.  It
+// returns as soon as a pair of bytes with a nonzero intersection is found.
+func (s Bytes) Intersects(other Bytes) bool {
+	n := min(len(s), len(other))
+	for i, b := range other[:n] {
+		if s[i]&b != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Intersects returns whether s and other have any set bit in common.  Only
+// the smaller of the two maps is iterated, and it returns as soon as a pair
+// of pointers with a nonzero intersection is found.
+func (s Sparse) Intersects(other Sparse) bool {
+	if len(other) < len(s) {
+		s, other = other, s
+	}
+	for k, ptr := range s {
+		if ptr&other[k] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSubsetOf returns whether every bit set in p is also set in other.
+func (p Pointers) IsSubsetOf(other Pointers) bool {
+	for i, ptr := range p {
+		var o uintptr
+		if i < len(other) {
+			o = other[i]
+		}
+		if ptr&^o != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSupersetOf returns whether every bit set in other is also set in p.
+func (p Pointers) IsSupersetOf(other Pointers) bool {
+	return other.IsSubsetOf(p)
+}
+
+// IsDisjoint returns whether p and other have no set bits in common.
+func (p Pointers) IsDisjoint(other Pointers) bool {
+	return !p.Intersects(other)
+}
+
+// IsSubsetOf returns whether every bit set in s is also set in other.
+func (s Bytes) IsSubsetOf(other Bytes) bool {
+	for i, b := range s {
+		var o byte
+		if i < len(other) {
+			o = other[i]
+		}
+		if b&^o != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSupersetOf returns whether every bit set in other is also set in s.
+func (s Bytes) IsSupersetOf(other Bytes) bool {
+	return other.IsSubsetOf(s)
+}
+
+// IsDisjoint returns whether s and other have no set bits in common.
+func (s Bytes) IsDisjoint(other Bytes) bool {
+	return !s.Intersects(other)
+}
+
+// IsSubsetOf returns whether every bit set in s is also set in other.
+func (s Sparse) IsSubsetOf(other Sparse) bool {
+	for k, ptr := range s {
+		if ptr&^other[k] != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSupersetOf returns whether every bit set in other is also set in s.
+func (s Sparse) IsSupersetOf(other Sparse) bool {
+	return other.IsSubsetOf(s)
+}
+
+// IsDisjoint returns whether s and other have no set bits in common.
+func (s Sparse) IsDisjoint(other Sparse) bool {
+	return !s.Intersects(other)
+}
+
+// Equal returns whether p and other have exactly the same bits set.  Trailing
+// zero pointers are insignificant, so bitsets of different lengths compare
+// equal if the longer one has no set bits beyond the end of the shorter.
+func (p Pointers) Equal(other Pointers) bool {
+	if len(p) < len(other) {
+		p, other = other, p
+	}
+	for i, ptr := range other {
+		if p[i] != ptr {
+			return false
+		}
+	}
+	return Pointers(p[len(other):]).None()
+}
+
+// EqualBytes returns whether p and the Bytes bitset b have exactly the same
+// bits set, regardless of their differing representations.  As with Equal,
+// trailing zero pointers and bytes are insignificant.
+func (p Pointers) EqualBytes(b Bytes) bool {
+	for w, ptr := range p {
+		if ptr != b.ptrAt(w) {
+			return false
+		}
+	}
+	if n := len(p) * (ptrBits / 8); n < len(b) {
+		return b[n:].None()
+	}
+	return true
+}
+
+// Equal returns whether s and other have exactly the same bits set.  Trailing
+// zero bytes are insignificant, so bitsets of different lengths compare equal
+// if the longer one has no set bits beyond the end of the shorter.
+func (s Bytes) Equal(other Bytes) bool {
+	if len(s) < len(other) {
+		s, other = other, s
+	}
+	for i, b := range other {
+		if s[i] != b {
+			return false
+		}
+	}
+	return s[len(other):].None()
+}
+
+// EqualPointers returns whether s and the Pointers bitset p have exactly the
+// same bits set, regardless of their differing representations.  As with
+// Equal, trailing zero pointers and bytes are insignificant.
+func (s Bytes) EqualPointers(p Pointers) bool {
+	return p.EqualBytes(s)
+}
+
+// ptrAt returns the bits of s that would be held by the w'th pointer of a
+// Pointers bitset with the same bits set.  Bytes beyond the end of s are
+// treated as zero.
+func (s Bytes) ptrAt(w int) uintptr {
+	var ptr uintptr
+	start := w * (ptrBits / 8)
+	for i := 0; i < ptrBits/8 && start+i < len(s); i++ {
+		ptr |= uintptr(s[start+i]) << (uint(i) << byteShift)
+	}
+	return ptr
+}
+
+// Equal returns whether s and other have exactly the same bits set.  Map
+// entries holding zero pointers are insignificant.
+func (s Sparse) Equal(other Sparse) bool {
+	for k, ptr := range s {
+		if other[k] != ptr {
+			return false
+		}
+	}
+	for k, ptr := range other {
+		if s[k] != ptr {
+			return false
+		}
+	}
+	return true
+}
+
+// Compare returns an integer comparing p and other lexicographically as bit
+// strings, beginning with bit zero.  The result is determined by the lowest
+// index at which the bitsets differ: it is -1 if that bit is unset in p, and
+// +1 if that bit is set in p.  It is 0 if the bitsets are Equal.  As trailing
+// zero pointers are insignificant, Compare is consistent with Equal.
+func (p Pointers) Compare(other Pointers) int {
+	n := max(len(p), len(other))
+	for i := 0; i < n; i++ {
+		var a, b uintptr
+		if i < len(p) {
+			a = p[i]
+		}
+		if i < len(other) {
+			b = other[i]
+		}
+		if c := compareWord(a, b); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// Compare returns an integer comparing s and other lexicographically as bit
+// strings, beginning with bit zero.  The result is determined by the lowest
+// index at which the bitsets differ: it is -1 if that bit is unset in s, and
+// +1 if that bit is set in s.  It is 0 if the bitsets are Equal.  As trailing
+// zero bytes are insignificant, Compare is consistent with Equal.
+func (s Bytes) Compare(other Bytes) int {
+	n := max(len(s), len(other))
+	for i := 0; i < n; i++ {
+		var a, b byte
+		if i < len(s) {
+			a = s[i]
+		}
+		if i < len(other) {
+			b = other[i]
+		}
+		if c := compareWord(uintptr(a), uintptr(b)); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// Compare returns an integer comparing s and other lexicographically as bit
+// strings, beginning with bit zero.  The result is determined by the lowest
+// index at which the bitsets differ: it is -1 if that bit is unset in s, and
+// +1 if that bit is set in s.  It is 0 if the bitsets are Equal.
+func (s Sparse) Compare(other Sparse) int {
+	key := -1
+	find := func(a, b Sparse) {
+		for k, ptr := range a {
+			if ptr != b[k] && (key == -1 || k < key) {
+				key = k
+			}
+		}
+	}
+	find(s, other)
+	find(other, s)
+	if key == -1 {
+		return 0
+	}
+	return compareWord(s[key], other[key])
+}
+
+// compareWord compares two words by their lowest differing bit, returning -1
+// if that bit is unset in a, +1 if it is set in a, and 0 if a == b.
+func compareWord(a, b uintptr) int {
+	diff := a ^ b
+	switch {
+	case diff == 0:
+		return 0
+	case a&diff&-diff != 0:
+		return 1
+	default:
+		return -1
+	}
+}
+
+// Diff returns the indexes of bits which are set in newer but not p (added),
+// and those set in p but not newer (removed), each in increasing order.
+// Pointers that are identical in both bitsets are skipped whole.
+func (p Pointers) Diff(newer Pointers) (added, removed []int) {
+	n := max(len(p), len(newer))
+	for w := 0; w < n; w++ {
+		var o, c uintptr
+		if w < len(p) {
+			o = p[w]
+		}
+		if w < len(newer) {
+			c = newer[w]
+		}
+		if o == c {
+			continue
+		}
+		added = appendWordBits(added, w<<ptrShift, c&^o)
+		removed = appendWordBits(removed, w<<ptrShift, o&^c)
+	}
+	return added, removed
+}
+
+// Diff returns the indexes of bits which are set in newer but not s (added),
+// and those set in s but not newer (removed), each in increasing order.
+// Bytes that are identical in both bitsets are skipped whole.
+func (s Bytes) Diff(newer Bytes) (added, removed []int) {
+	n := max(len(s), len(newer))
+	for i := 0; i < n; i++ {
+		var o, c byte
+		if i < len(s) {
+			o = s[i]
+		}
+		if i < len(newer) {
+			c = newer[i]
+		}
+		if o == c {
+			continue
+		}
+		added = appendWordBits(added, i<<byteShift, uintptr(c&^o))
+		removed = appendWordBits(removed, i<<byteShift, uintptr(o&^c))
+	}
+	return added, removed
+}
+
+// Diff returns the indexes of bits which are set in newer but not s (added),
+// and those set in s but not newer (removed), each in increasing order.
+func (s Sparse) Diff(newer Sparse) (added, removed []int) {
+	keys := make(Sparse, len(s)+len(newer))
+	for k := range s {
+		keys[k] = 0
+	}
+	for k := range newer {
+		keys[k] = 0
+	}
+	for _, k := range keys.sortedKeys() {
+		o, c := s[k], newer[k]
+		added = appendWordBits(added, k<<ptrShift, c&^o)
+		removed = appendWordBits(removed, k<<ptrShift, o&^c)
+	}
+	return added, removed
+}
+
+// appendWordBits appends base plus the index of each set bit of w to dst in
+// increasing order.
+func appendWordBits(dst []int, base int, w uintptr) []int {
+	for w != 0 {
+		dst = append(dst, base+bits.TrailingZeros(uint(w)))
+		w &= w - 1
+	}
+	return dst
+}
+
+// CommonPrefixLen returns the number of low bits, beginning at index zero,
+// which are equal in p and other.  This is the index of the lowest differing
+// bit.  The shorter bitset is treated as being padded with unset bits to the
+// length of the longer, and if no bits differ, the bit length of the longer
+// bitset is returned.
+func (p Pointers) CommonPrefixLen(other Pointers) int {
+	if len(p) < len(other) {
+		p, other = other, p
+	}
+	for w, ptr := range p {
+		var o uintptr
+		if w < len(other) {
+			o = other[w]
+		}
+		if x := ptr ^ o; x != 0 {
+			return w<<ptrShift + bits.TrailingZeros(uint(x))
+		}
+	}
+	return len(p) << ptrShift
+}
+
+// CommonSuffixLen returns the number of high bits, counting down from the
+// end of the longer bitset, which are equal in p and other.  The shorter
+// bitset is treated as being padded with unset bits to the length of the
+// longer, and if no bits differ, the bit length of the longer bitset is
+// returned.
+func (p Pointers) CommonSuffixLen(other Pointers) int {
+	if len(p) < len(other) {
+		p, other = other, p
+	}
+	for w := len(p) - 1; w >= 0; w-- {
+		var o uintptr
+		if w < len(other) {
+			o = other[w]
+		}
+		if x := p[w] ^ o; x != 0 {
+			return (len(p)-1-w)<<ptrShift + bits.LeadingZeros(uint(x))
+		}
+	}
+	return len(p) << ptrShift
+}
+
+// CommonPrefixLen returns the number of low bits, beginning at index zero,
+// which are equal in s and other.  This is the index of the lowest differing
+// bit.  The shorter bitset is treated as being padded with unset bits to the
+// length of the longer, and if no bits differ, the bit length of the longer
+// bitset is returned.
+func (s Bytes) CommonPrefixLen(other Bytes) int {
+	if len(s) < len(other) {
+		s, other = other, s
+	}
+	for i, b := range s {
+		var o byte
+		if i < len(other) {
+			o = other[i]
+		}
+		if x := b ^ o; x != 0 {
+			return i<<byteShift + bits.TrailingZeros8(x)
+		}
+	}
+	return len(s) << byteShift
+}
+
+// CommonSuffixLen returns the number of high bits, counting down from the
+// end of the longer bitset, which are equal in s and other.  The shorter
+// bitset is treated as being padded with unset bits to the length of the
+// longer, and if no bits differ, the bit length of the longer bitset is
+// returned.
+func (s Bytes) CommonSuffixLen(other Bytes) int {
+	if len(s) < len(other) {
+		s, other = other, s
+	}
+	for i := len(s) - 1; i >= 0; i-- {
+		var o byte
+		if i < len(other) {
+			o = other[i]
+		}
+		if x := s[i] ^ o; x != 0 {
+			return (len(s)-1-i)<<byteShift + bits.LeadingZeros8(x)
+		}
+	}
+	return len(s) << byteShift
+}
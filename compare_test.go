@@ -0,0 +1,250 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset_test
+
+import (
+	"testing"
+
+	. "github.com/jrick/bitset"
+)
+
+var relationTests = []struct {
+	a, b       []int
+	intersects bool
+	subset     bool // a is a subset of b
+	superset   bool // a is a superset of b
+}{
+	{a: nil, b: nil, intersects: false, subset: true, superset: true},
+	{a: nil, b: []int{3}, intersects: false, subset: true, superset: false},
+	{a: []int{3}, b: nil, intersects: false, subset: false, superset: true},
+	{a: []int{3}, b: []int{3}, intersects: true, subset: true, superset: true},
+	{a: []int{1, 70}, b: []int{1, 2, 70, 300}, intersects: true, subset: true, superset: false},
+	{a: []int{1, 2, 70, 300}, b: []int{2, 300}, intersects: true, subset: false, superset: true},
+	{a: []int{0, 64}, b: []int{1, 65, 500}, intersects: false, subset: false, superset: false},
+	{a: []int{0, 600}, b: []int{0, 64}, intersects: true, subset: false, superset: false},
+}
+
+func TestIntersects(t *testing.T) {
+	for testNum, test := range relationTests {
+		pa, pb := NewPointersFromIndices(test.a), NewPointersFromIndices(test.b)
+		if got := pa.Intersects(pb); got != test.intersects {
+			t.Errorf("Test %d bitset Pointers: got %v expected %v",
+				testNum, got, test.intersects)
+		}
+		ba, bb := NewBytesFromIndices(test.a), NewBytesFromIndices(test.b)
+		if got := ba.Intersects(bb); got != test.intersects {
+			t.Errorf("Test %d bitset Bytes: got %v expected %v",
+				testNum, got, test.intersects)
+		}
+		sa, sb := NewSparseFromIndices(test.a), NewSparseFromIndices(test.b)
+		if got := sa.Intersects(sb); got != test.intersects {
+			t.Errorf("Test %d bitset Sparse: got %v expected %v",
+				testNum, got, test.intersects)
+		}
+	}
+}
+
+func TestSubsetSupersetDisjoint(t *testing.T) {
+	for testNum, test := range relationTests {
+		pa, pb := NewPointersFromIndices(test.a), NewPointersFromIndices(test.b)
+		ba, bb := NewBytesFromIndices(test.a), NewBytesFromIndices(test.b)
+		sa, sb := NewSparseFromIndices(test.a), NewSparseFromIndices(test.b)
+		results := []struct {
+			name                       string
+			subset, superset, disjoint bool
+		}{
+			{"Pointers", pa.IsSubsetOf(pb), pa.IsSupersetOf(pb), pa.IsDisjoint(pb)},
+			{"Bytes", ba.IsSubsetOf(bb), ba.IsSupersetOf(bb), ba.IsDisjoint(bb)},
+			{"Sparse", sa.IsSubsetOf(sb), sa.IsSupersetOf(sb), sa.IsDisjoint(sb)},
+		}
+		for _, r := range results {
+			if r.subset != test.subset {
+				t.Errorf("Test %d bitset %s: IsSubsetOf got %v expected %v",
+					testNum, r.name, r.subset, test.subset)
+			}
+			if r.superset != test.superset {
+				t.Errorf("Test %d bitset %s: IsSupersetOf got %v expected %v",
+					testNum, r.name, r.superset, test.superset)
+			}
+			if r.disjoint == test.intersects {
+				t.Errorf("Test %d bitset %s: IsDisjoint got %v expected %v",
+					testNum, r.name, r.disjoint, !test.intersects)
+			}
+		}
+	}
+}
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		a, b   []int
+		aBits  int
+		bBits  int
+		expect bool
+	}{
+		{a: nil, b: nil, aBits: 0, bBits: 0, expect: true},
+		{a: nil, b: nil, aBits: 0, bBits: 512, expect: true},
+		{a: []int{1, 100}, b: []int{1, 100}, aBits: 128, bBits: 1024, expect: true},
+		{a: []int{1, 100}, b: []int{1, 101}, aBits: 128, bBits: 128, expect: false},
+		{a: []int{1}, b: []int{1, 900}, aBits: 64, bBits: 1024, expect: false},
+		{a: []int{7, 8, 63, 64}, b: []int{7, 8, 63, 64}, aBits: 72, bBits: 64 * 3, expect: true},
+	}
+	for testNum, test := range tests {
+		pa, pb := NewPointers(test.aBits), NewPointers(test.bBits)
+		ba, bb := NewBytes(test.aBits), NewBytes(test.bBits)
+		sa, sb := make(Sparse), make(Sparse)
+		for _, i := range test.a {
+			pa.Set(i)
+			ba.Set(i)
+			sa.Set(i)
+		}
+		for _, i := range test.b {
+			pb.Set(i)
+			bb.Set(i)
+			sb.Set(i)
+		}
+		results := []struct {
+			name string
+			got  bool
+		}{
+			{"Pointers", pa.Equal(pb)},
+			{"Pointers reversed", pb.Equal(pa)},
+			{"Bytes", ba.Equal(bb)},
+			{"Bytes reversed", bb.Equal(ba)},
+			{"Sparse", sa.Equal(sb)},
+			{"Pointers/Bytes", pa.EqualBytes(bb)},
+			{"Bytes/Pointers", ba.EqualPointers(pb)},
+			{"Pointers/Bytes reversed", pb.EqualBytes(ba)},
+		}
+		for _, r := range results {
+			if r.got != test.expect {
+				t.Errorf("Test %d %s: got %v expected %v", testNum,
+					r.name, r.got, test.expect)
+			}
+		}
+	}
+
+	// A grown then shrunk set compares equal to the original.
+	p := NewPointersFromIndices([]int{3})
+	orig := append(Pointers(nil), p...)
+	p.Grow(1000)
+	p.Set(999)
+	p.Unset(999)
+	if !p.Equal(orig) {
+		t.Errorf("Grown Pointers compared unequal to original")
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b []int
+		exp  int
+	}{
+		{a: nil, b: nil, exp: 0},
+		{a: []int{5}, b: []int{5}, exp: 0},
+		{a: nil, b: []int{5}, exp: -1},
+		{a: []int{5}, b: nil, exp: 1},
+		{a: []int{0, 300}, b: []int{1}, exp: 1},
+		{a: []int{1, 2}, b: []int{1, 3}, exp: 1},
+		{a: []int{1, 3, 500}, b: []int{1, 2}, exp: -1},
+		{a: []int{64, 65}, b: []int{64, 70}, exp: 1},
+	}
+	for testNum, test := range tests {
+		results := []struct {
+			name     string
+			got, rev int
+		}{
+			{"Pointers",
+				NewPointersFromIndices(test.a).Compare(NewPointersFromIndices(test.b)),
+				NewPointersFromIndices(test.b).Compare(NewPointersFromIndices(test.a))},
+			{"Bytes",
+				NewBytesFromIndices(test.a).Compare(NewBytesFromIndices(test.b)),
+				NewBytesFromIndices(test.b).Compare(NewBytesFromIndices(test.a))},
+			{"Sparse",
+				NewSparseFromIndices(test.a).Compare(NewSparseFromIndices(test.b)),
+				NewSparseFromIndices(test.b).Compare(NewSparseFromIndices(test.a))},
+		}
+		for _, r := range results {
+			if r.got != test.exp || r.rev != -test.exp {
+				t.Errorf("Test %d bitset %s: got %d (reversed %d) expected %d",
+					testNum, r.name, r.got, r.rev, test.exp)
+			}
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		old, new       []int
+		added, removed []int
+	}{
+		{old: nil, new: nil, added: nil, removed: nil},
+		{old: []int{1, 2}, new: []int{1, 2}, added: nil, removed: nil},
+		{old: nil, new: []int{3, 300}, added: []int{3, 300}, removed: nil},
+		{old: []int{3, 300}, new: nil, added: nil, removed: []int{3, 300}},
+		{old: []int{0, 5, 64, 65}, new: []int{5, 6, 65, 129, 700},
+			added: []int{6, 129, 700}, removed: []int{0, 64}},
+	}
+	for testNum, test := range tests {
+		type diffResult struct {
+			name           string
+			added, removed []int
+		}
+		var results []diffResult
+		a, r := NewPointersFromIndices(test.old).Diff(NewPointersFromIndices(test.new))
+		results = append(results, diffResult{"Pointers", a, r})
+		a, r = NewBytesFromIndices(test.old).Diff(NewBytesFromIndices(test.new))
+		results = append(results, diffResult{"Bytes", a, r})
+		a, r = NewSparseFromIndices(test.old).Diff(NewSparseFromIndices(test.new))
+		results = append(results, diffResult{"Sparse", a, r})
+		for _, res := range results {
+			if !equalIndices(res.added, test.added) || !equalIndices(res.removed, test.removed) {
+				t.Errorf("Test %d bitset %s: got added %v removed %v expected %v %v",
+					testNum, res.name, res.added, res.removed,
+					test.added, test.removed)
+			}
+		}
+	}
+}
+
+func TestCommonPrefixSuffixLen(t *testing.T) {
+	// Lengths are given in bits for a bitset of 256 bits, which is a
+	// whole number of pointers on all supported architectures.
+	tests := []struct {
+		a, b           []int
+		prefix, suffix int
+	}{
+		{a: nil, b: nil, prefix: 256, suffix: 256},
+		{a: []int{5, 255}, b: []int{5, 255}, prefix: 256, suffix: 256},
+		{a: []int{0}, b: nil, prefix: 0, suffix: 255},
+		{a: []int{255}, b: nil, prefix: 255, suffix: 0},
+		{a: []int{1, 100, 200}, b: []int{1, 101, 200}, prefix: 100, suffix: 154},
+	}
+	for testNum, test := range tests {
+		pa, pb := NewPointers(256), NewPointers(256)
+		ba, bb := NewBytes(256), NewBytes(256)
+		for _, i := range test.a {
+			pa.Set(i)
+			ba.Set(i)
+		}
+		for _, i := range test.b {
+			pb.Set(i)
+			bb.Set(i)
+		}
+		results := []struct {
+			name           string
+			prefix, suffix int
+		}{
+			{"Pointers", pa.CommonPrefixLen(pb), pa.CommonSuffixLen(pb)},
+			{"Bytes", ba.CommonPrefixLen(bb), ba.CommonSuffixLen(bb)},
+		}
+		for _, r := range results {
+			if r.prefix != test.prefix || r.suffix != test.suffix {
+				t.Errorf("Test %d bitset %s: got prefix %d suffix %d expected %d %d",
+					testNum, r.name, r.prefix, r.suffix, test.prefix,
+					test.suffix)
+			}
+		}
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+)
+
+// A Compressor returns a writer which compresses all data written to it and
+// writes the result to w.  Closing the returned writer must flush any
+// buffered data to w, but must not close w.
+//
+// Compressors for formats outside of the standard library may be adapted
+// from their packages without this package depending on them.  For example,
+// using github.com/klauspost/compress/zstd:
+//
+//	func(w io.Writer) (io.WriteCloser, error) { return zstd.NewWriter(w) }
+type Compressor func(w io.Writer) (io.WriteCloser, error)
+
+// A Decompressor returns a reader of the data decompressed from r.  If the
+// returned reader implements io.Closer, it is closed after reading.
+type Decompressor func(r io.Reader) (io.Reader, error)
+
+// Gzip is a Compressor using the gzip format at the default compression
+// level.
+func Gzip(w io.Writer) (io.WriteCloser, error) {
+	return gzip.NewWriter(w), nil
+}
+
+// Gunzip is a Decompressor for the gzip format.
+func Gunzip(r io.Reader) (io.Reader, error) {
+	return gzip.NewReader(r)
+}
+
+// WriteToCompressed writes the streamed encoding of bs to w, compressed by c.
+// bs is typically a Pointers, Bytes, or *Dense bitset.  Large bitsets with
+// few set bits are highly compressible.  It returns the number of compressed
+// bytes written to w.
+func WriteToCompressed(w io.Writer, bs io.WriterTo, c Compressor) (int64, error) {
+	cw := &countingWriter{w: w}
+	zw, err := c(cw)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := bs.WriteTo(zw); err != nil {
+		zw.Close()
+		return cw.n, err
+	}
+	err = zw.Close()
+	return cw.n, err
+}
+
+// ReadFromCompressed replaces the contents of bs with a streamed bitset
+// decompressed by d from r.  bs is typically a *Pointers, *Bytes, or *Dense
+// bitset.  The decompressed stream is read to its end, allowing the
+// decompressor to verify any trailing checksum, before bs is modified, so on
+// error bs is not modified.  Wrapping bs in Limited bounds the size of the
+// decompressed bitset, which should be done when reading from untrusted
+// sources; a decompressed stream longer than any bitset within the limit
+// results in ErrTooLarge without reading further.  If the decompressed stream
+// holds any data after the bitset, ErrInvalidLength is returned.  It returns
+// the number of compressed bytes read from r, which may include data beyond
+// the end of the compressed stream buffered by the decompressor.
+func ReadFromCompressed(r io.Reader, bs io.ReaderFrom, d Decompressor) (int64, error) {
+	cr := &countingReader{r: r}
+	zr, err := d(cr)
+	if err != nil {
+		return cr.n, err
+	}
+	if c, ok := zr.(io.Closer); ok {
+		defer c.Close()
+	}
+	src := zr
+	limit := int64(-1)
+	if l, ok := bs.(Limited); ok {
+		// The longest stream within the limit is its header followed
+		// by the bytes of MaxBits bits.
+		limit = binary.MaxVarintLen64 + int64(l.MaxBits>>byteShift) + 1
+		src = io.LimitReader(zr, limit+1)
+	}
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return cr.n, err
+	}
+	if int64(len(data)) > limit && limit != -1 {
+		return cr.n, ErrTooLarge
+	}
+	// Data following the bitset is detected from its header before bs is
+	// modified.  Malformed headers are reported by ReadFrom.
+	if numBits, n := binary.Uvarint(data); n > 0 {
+		size := numBits>>byteShift + min(numBits&byteModMask, 1)
+		if uint64(len(data)-n) > size {
+			return cr.n, ErrInvalidLength
+		}
+	}
+	if _, err := bs.ReadFrom(bytes.NewReader(data)); err != nil {
+		return cr.n, err
+	}
+	return cr.n, nil
+}
+
+// countingWriter is an io.Writer which records the number of bytes written
+// to the underlying writer.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"testing"
+
+	. "github.com/jrick/bitset"
+)
+
+func TestCompressed(t *testing.T) {
+	deflate := func(w io.Writer) (io.WriteCloser, error) {
+		return flate.NewWriter(w, flate.BestCompression)
+	}
+	inflate := func(r io.Reader) (io.Reader, error) {
+		return flate.NewReader(r), nil
+	}
+	tests := []struct {
+		name string
+		c    Compressor
+		d    Decompressor
+	}{
+		{"gzip", Gzip, Gunzip},
+		{"flate", deflate, inflate},
+	}
+
+	const numBits = 1 << 16
+	p := NewPointers(numBits)
+	for _, i := range []int{3, 1000, 40000, numBits - 1} {
+		p.Set(i)
+	}
+	for _, test := range tests {
+		var buf bytes.Buffer
+		n, err := WriteToCompressed(&buf, p, test.c)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if n != int64(buf.Len()) {
+			t.Errorf("%s: wrote %d bytes but returned %d", test.name, buf.Len(), n)
+		}
+		if buf.Len() > numBits/8/100 {
+			t.Errorf("%s: compressed %d bytes to %d bytes", test.name,
+				numBits/8, buf.Len())
+		}
+
+		var got Pointers
+		n, err = ReadFromCompressed(bytes.NewReader(buf.Bytes()), &got, test.d)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if n != int64(buf.Len()) {
+			t.Errorf("%s: read %d bytes expected %d", test.name, n, buf.Len())
+		}
+		if !got.Equal(p) {
+			t.Errorf("%s: round trip mismatch", test.name)
+		}
+
+		var limited Bytes
+		_, err = ReadFromCompressed(bytes.NewReader(buf.Bytes()),
+			Limited{&limited, numBits - 1}, test.d)
+		if err != ErrTooLarge {
+			t.Errorf("%s: got error %v expected %v", test.name, err, ErrTooLarge)
+		}
+	}
+}
+
+func TestCompressedErrors(t *testing.T) {
+	s := NewBytesFromIndices([]int{1, 2, 3})
+
+	// Data following the bitset in the decompressed stream.
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	s.WriteTo(zw)
+	zw.Write([]byte{0})
+	zw.Close()
+	got := Bytes{0xaa}
+	if _, err := ReadFromCompressed(&buf, &got, Gunzip); err != ErrInvalidLength {
+		t.Errorf("trailing data: got error %v expected %v", err, ErrInvalidLength)
+	}
+	if !bytes.Equal(got, []byte{0xaa}) {
+		t.Errorf("trailing data: bitset modified on error")
+	}
+
+	// A corrupted checksum is detected before the bitset is modified.
+	buf.Reset()
+	if _, err := WriteToCompressed(&buf, s, Gzip); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	data[len(data)-8] ^= 0xff
+	if _, err := ReadFromCompressed(bytes.NewReader(data), &got, Gunzip); err != gzip.ErrChecksum {
+		t.Errorf("corrupt checksum: got error %v expected %v", err, gzip.ErrChecksum)
+	}
+	if !bytes.Equal(got, []byte{0xaa}) {
+		t.Errorf("corrupt checksum: bitset modified on error")
+	}
+
+	// A limited read stops once the stream exceeds any bitset within the
+	// limit.
+	buf.Reset()
+	zw = gzip.NewWriter(&buf)
+	s.WriteTo(zw)
+	zw.Write(make([]byte, 1<<20))
+	zw.Close()
+	if _, err := ReadFromCompressed(&buf, Limited{&got, 64}, Gunzip); err != ErrTooLarge {
+		t.Errorf("limited: got error %v expected %v", err, ErrTooLarge)
+	}
+
+	if _, err := ReadFromCompressed(bytes.NewReader([]byte("not a gzip stream")), &got, Gunzip); err != gzip.ErrHeader {
+		t.Errorf("bad header: got error %v expected %v", err, gzip.ErrHeader)
+	}
+}
@@ -0,0 +1,293 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+)
+
+// Errors returned when decoding a bitset container.
+var (
+	// ErrInvalidContainer describes an error where the data being decoded
+	// is not a bitset container or its header is malformed.
+	ErrInvalidContainer = errors.New("bitset: invalid container")
+
+	// ErrUnsupportedVersion describes an error where a container was
+	// written using a newer version of the format than this package can
+	// read.
+	ErrUnsupportedVersion = errors.New("bitset: unsupported container version")
+
+	// ErrChecksumMismatch describes an error where the checksum recorded by
+	// a container does not match its contents, indicating corruption.
+	ErrChecksumMismatch = errors.New("bitset: container checksum mismatch")
+
+	// ErrUnsupportedType describes an error where a bitset of a type
+	// which cannot be handled by an encoding was passed to Encode or
+	// wrapped by JSONIndexes.
+	ErrUnsupportedType = errors.New("bitset: unsupported bitset type")
+)
+
+// A container is a self-describing encoding of any bitset type, intended for
+// storing bitsets in files.  It is written by Encode as:
+//
+//	[4]byte  magic "BSET"
+//	byte     format version, currently 1
+//	byte     kind of bitset (see below)
+//	byte     number of bytes per word of the encoded bitset type
+//	uvarint  number of bits, n
+//	bytes    (n+7)/8 bytes using the layout of a Bytes bitset
+//	uint32   little endian CRC-32 (IEEE) of every preceding byte
+//
+// As the bits are stored using the byte layout, a container may be decoded on
+// a machine with a different pointer size than the one which wrote it; the
+// recorded word size is informational only.
+//
+// A Sparse bitset is instead stored using its sparse encoding (see
+// Sparse.MarshalBinary), so that its size is proportional to the number of
+// set bits rather than its highest set bit.  In place of the number of bits
+// and the bytes, its container records:
+//
+//	uvarint  length of the sparse encoding in bytes, n
+//	bytes    the n bytes of the sparse encoding
+//
+// Decoders reject containers with a version greater than they support, so
+// any incompatible change to the format must increment the version.
+const (
+	containerVersion = 1
+	containerHdrLen  = 7
+)
+
+var containerMagic = [4]byte{'B', 'S', 'E', 'T'}
+
+// Kinds of bitsets recorded by a container.
+const (
+	kindBytes = iota + 1
+	kindPointers
+	kindSparse
+	kindWords64
+	kindWords32
+	kindDense
+	kindBits64
+)
+
+// Encode writes bs to w as a container.  bs must be a Pointers, Bytes,
+// Sparse, Words64, Words32, *Dense, or *Bits64 bitset, or a pointer to one of
+// the slice or map types; otherwise ErrUnsupportedType is returned.
+func Encode(w io.Writer, bs BitSet) error {
+	if s, ok := bs.(*Sparse); ok {
+		bs = *s
+	}
+	var kind, wordSize byte
+	var n int
+	var data []byte
+	if s, ok := bs.(Sparse); ok {
+		data = s.appendSparse(nil)
+		kind, wordSize, n = kindSparse, ptrBits/8, len(data)
+	} else {
+		var err error
+		kind, wordSize, n, data, err = encodedParts(bs, maxInt)
+		if err != nil {
+			return err
+		}
+	}
+
+	buf := make([]byte, 0, containerHdrLen+binary.MaxVarintLen64+len(data)+4)
+	buf = append(buf, containerMagic[:]...)
+	buf = append(buf, containerVersion, kind, wordSize)
+	buf = binary.AppendUvarint(buf, uint64(n))
+	buf = append(buf, data...)
+	buf = binary.LittleEndian.AppendUint32(buf, crc32.ChecksumIEEE(buf))
+	_, err := w.Write(buf)
+	return err
+}
+
+// Decode reads a container written by Encode from r and returns the bitset
+// it holds, which has the same type that was encoded (Pointers, Bytes,
+// Sparse, Words64, Words32, *Dense, or *Bits64).  Truncated containers
+// result in io.ErrUnexpectedEOF, and corrupted containers in
+// ErrChecksumMismatch.
+func Decode(r io.Reader) (BitSet, error) {
+	return DecodeLimit(r, maxInt)
+}
+
+// DecodeLimit is like Decode, but returns ErrTooLarge without reading the
+// bitset's data if the container records more than maxBits bits.  It should
+// be preferred over Decode when reading containers from untrusted sources.
+func DecodeLimit(r io.Reader, maxBits int) (BitSet, error) {
+	crc := crc32.NewIEEE()
+	tr := io.TeeReader(r, crc)
+
+	var hdr [containerHdrLen]byte
+	if _, err := io.ReadFull(tr, hdr[:]); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(hdr[:4], containerMagic[:]) {
+		return nil, ErrInvalidContainer
+	}
+	if hdr[4] > containerVersion {
+		return nil, ErrUnsupportedVersion
+	}
+	kind := hdr[5]
+	if kind < kindBytes || kind > kindBits64 {
+		return nil, ErrInvalidContainer
+	}
+	var numBits int
+	var data Bytes
+	var err error
+	if kind == kindSparse {
+		data, err = readSparsePayload(tr, maxBits)
+	} else {
+		numBits, data, _, err = readStream(tr, maxBits)
+	}
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	var sum [4]byte
+	if _, err := io.ReadFull(r, sum[:]); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	if binary.LittleEndian.Uint32(sum[:]) != crc.Sum32() {
+		return nil, ErrChecksumMismatch
+	}
+
+	switch kind {
+	case kindBytes:
+		return data, nil
+	case kindPointers:
+		var p Pointers
+		p.setBytes(data)
+		return p, nil
+	case kindSparse:
+		var s Sparse
+		if err := s.setSparse(data, maxBits); err != nil {
+			return nil, err
+		}
+		return s, nil
+	case kindWords64:
+		return NewWords64FromBytes(data), nil
+	case kindWords32:
+		return NewWords32FromBytes(data), nil
+	case kindDense:
+		d := &Dense{n: numBits}
+		d.p.setBytes(data)
+		return d, nil
+	default: // kindBits64
+		if numBits > 64 {
+			return nil, ErrInvalidContainer
+		}
+		var word [8]byte
+		copy(word[:], data)
+		b := Bits64(binary.LittleEndian.Uint64(word[:]))
+		return &b, nil
+	}
+}
+
+// readSparsePayload reads the length and sparse encoding of a Sparse bitset
+// stored in a container.  Lengths which exceed that of the longest sparse
+// encoding of a bitset of maxBits bits result in ErrTooLarge.
+func readSparsePayload(r io.Reader, maxBits int) ([]byte, error) {
+	cr := &countingReader{r: r}
+	n, err := binary.ReadUvarint(cr)
+	if err != nil {
+		if cr.n == binary.MaxVarintLen64 {
+			err = ErrInvalidContainer
+		}
+		return nil, err
+	}
+	// Each nonzero 64-bit word is encoded with a key of at most
+	// MaxVarintLen64 bytes.
+	words := uint64(maxBits>>6) + 1
+	if n > binary.MaxVarintLen64+words*(binary.MaxVarintLen64+8) {
+		return nil, ErrTooLarge
+	}
+	return readChunked(cr, int(n))
+}
+
+// encodedParts returns the container kind and word size of bs, its length in
+// bits, and its bits using the layout of a Bytes bitset.  If bs is not a type
+// which can be encoded, ErrUnsupportedType is returned.  The bits of a Sparse
+// bitset extend through its highest nonzero pointer, and if they would not
+// fit in maxBytes bytes, ErrIndexOutOfRange is returned instead of
+// allocating them.
+func encodedParts(bs BitSet, maxBytes int) (kind, wordSize byte, numBits int, data []byte, err error) {
+	switch v := bs.(type) {
+	case *Bytes:
+		return encodedParts(*v, maxBytes)
+	case *Pointers:
+		return encodedParts(*v, maxBytes)
+	case *Sparse:
+		return encodedParts(*v, maxBytes)
+	case *Words64:
+		return encodedParts(*v, maxBytes)
+	case *Words32:
+		return encodedParts(*v, maxBytes)
+	case Bytes:
+		kind, wordSize, numBits, data = kindBytes, 1, len(v)<<byteShift, v
+	case Pointers:
+		kind, wordSize, numBits = kindPointers, ptrBits/8, len(v)<<ptrShift
+		data = v.appendBytes(nil)
+	case Sparse:
+		n, ok := v.extent()
+		if !ok || n>>byteShift > maxBytes {
+			return 0, 0, 0, nil, ErrIndexOutOfRange
+		}
+		kind, wordSize, numBits = kindSparse, ptrBits/8, n
+		data = v.pointers().appendBytes(nil)
+	case Words64:
+		kind, wordSize, numBits, data = kindWords64, 8, len(v)<<6, v.Bytes()
+	case Words32:
+		kind, wordSize, numBits, data = kindWords32, 4, len(v)<<5, v.Bytes()
+	case *Dense:
+		kind, wordSize, numBits = kindDense, ptrBits/8, v.n
+		data = v.p.appendBytes(nil)[:(v.n+byteModMask)>>byteShift]
+	case *Bits64:
+		kind, wordSize, numBits = kindBits64, 8, 64
+		data = binary.LittleEndian.AppendUint64(nil, uint64(*v))
+	default:
+		return 0, 0, 0, nil, ErrUnsupportedType
+	}
+	return kind, wordSize, numBits, data, nil
+}
+
+// pointers returns a Pointers bitset holding the same bits as s, sized to
+// include the highest nonzero pointer of s.  The extent of s must be
+// representable, and small enough to allocate.
+func (s Sparse) pointers() Pointers {
+	numBits, _ := s.extent()
+	p := make(Pointers, numBits>>ptrShift)
+	for k, ptr := range s {
+		if ptr != 0 {
+			p[k] = ptr
+		}
+	}
+	return p
+}
+
+// extent returns the number of bits of s up to and including its highest
+// nonzero pointer, without allocating.  If this is not representable as an
+// int, as is the case after setting a negative index, maxInt and false are
+// returned.
+func (s Sparse) extent() (int, bool) {
+	n := 0
+	for k, ptr := range s {
+		if ptr != 0 && k >= n {
+			n = k + 1
+		}
+	}
+	if n > maxInt>>ptrShift {
+		return maxInt, false
+	}
+	return n << ptrShift, true
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset_test
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	. "github.com/jrick/bitset"
+)
+
+func TestContainer(t *testing.T) {
+	set := []int{0, 9, 31, 32, 63}
+	b := NewBytesFromIndices(set)
+	d := NewDense(70)
+	bits := Bits64(0)
+	for _, i := range set {
+		d.Set(i)
+		bits.Set(i)
+	}
+	sparse := NewSparseFromIndices(append(set, 1000))
+	// The dense form of a Sparse bitset with a high index could never be
+	// allocated.
+	high := NewSparseFromIndices([]int{3, int(^uint(0) >> 2)})
+	tests := []struct {
+		name string
+		bs   BitSet
+		exp  BitSet
+	}{
+		{"Bytes", b, b},
+		{"*Bytes", &b, b},
+		{"Pointers", NewPointersFromIndices(set), NewPointersFromIndices(set)},
+		{"Sparse", sparse, sparse},
+		{"Sparse high", high, high},
+		{"Words64", NewWords64FromBytes(b), NewWords64FromBytes(b)},
+		{"Words32", NewWords32FromBytes(b), NewWords32FromBytes(b)},
+		{"Dense", d, d},
+		{"Bits64", &bits, &bits},
+	}
+	for _, test := range tests {
+		var buf bytes.Buffer
+		if err := Encode(&buf, test.bs); err != nil {
+			t.Fatalf("bitset %s: Encode: %v", test.name, err)
+		}
+		enc := buf.Bytes()
+		if len(enc) > 64 {
+			t.Errorf("bitset %s: encoded to %d bytes", test.name, len(enc))
+		}
+		if string(enc[:4]) != "BSET" || enc[4] != 1 {
+			t.Errorf("bitset %s: bad header %x", test.name, enc[:7])
+		}
+		got, err := Decode(bytes.NewReader(enc))
+		if err != nil {
+			t.Fatalf("bitset %s: Decode: %v", test.name, err)
+		}
+		if !reflect.DeepEqual(got, test.exp) {
+			t.Errorf("bitset %s: decoded %#v expected %#v", test.name, got, test.exp)
+		}
+
+		// Every truncation is detected.
+		for n := 0; n < len(enc); n++ {
+			_, err := Decode(bytes.NewReader(enc[:n]))
+			if err != io.ErrUnexpectedEOF && !(n == 0 && err == io.EOF) {
+				t.Errorf("bitset %s: truncated to %d bytes got error %v",
+					test.name, n, err)
+			}
+		}
+
+		// Every single bit flip after the header is detected.
+		for i := 7; i < len(enc); i++ {
+			corrupt := append([]byte(nil), enc...)
+			corrupt[i] ^= 0x10
+			if _, err := Decode(bytes.NewReader(corrupt)); err == nil {
+				t.Errorf("bitset %s: corruption at byte %d not detected",
+					test.name, i)
+			}
+		}
+	}
+}
+
+func TestContainerErrors(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Encode(&buf, NewBytes(8)); err != nil {
+		t.Fatal(err)
+	}
+	enc := buf.Bytes()
+	tests := []struct {
+		name   string
+		modify func(b []byte)
+		err    error
+	}{
+		{"magic", func(b []byte) { b[0] = 'X' }, ErrInvalidContainer},
+		{"version", func(b []byte) { b[4] = 2 }, ErrUnsupportedVersion},
+		{"kind", func(b []byte) { b[5] = 0 }, ErrInvalidContainer},
+		{"payload", func(b []byte) { b[8] = 1 }, ErrChecksumMismatch},
+		{"checksum", func(b []byte) { b[len(b)-1] ^= 1 }, ErrChecksumMismatch},
+	}
+	for _, test := range tests {
+		b := append([]byte(nil), enc...)
+		test.modify(b)
+		if _, err := Decode(bytes.NewReader(b)); err != test.err {
+			t.Errorf("%s: got error %v expected %v", test.name, err, test.err)
+		}
+	}
+
+	if err := Encode(&buf, new(AutoGrowBytes)); err != ErrUnsupportedType {
+		t.Errorf("Encode unsupported type: got error %v expected %v", err,
+			ErrUnsupportedType)
+	}
+}
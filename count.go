@@ -0,0 +1,328 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+import "math/bits"
+
+// Count returns the total number of set bits in the bitset.
+func (p Pointers) Count() int {
+	n := 0
+	for _, ptr := range p {
+		n += bits.OnesCount(uint(ptr))
+	}
+	return n
+}
+
+// Count returns the total number of set bits in the bitset.
+func (s Bytes) Count() int {
+	n := 0
+	for _, b := range s {
+		n += bits.OnesCount8(b)
+	}
+	return n
+}
+
+// CountRange returns the number of set bits in the half-open interval
+// [start, end).  Only the pointers at the boundaries of the range are
+// masked; all pointers in between are counted whole.  This method will panic
+// if end results in a pointer index that exceeds the number of pointers held
+// by the bitset.
+func (p Pointers) CountRange(start, end int) int {
+	if start >= end {
+		return 0
+	}
+	first := uint(start) >> ptrShift
+	last := uint(end-1) >> ptrShift
+	lowMask := ^uintptr(0) << (uint(start) & ptrModMask)
+	highMask := ^uintptr(0) >> (ptrModMask - uint(end-1)&ptrModMask)
+	if first == last {
+		return bits.OnesCount(uint(p[first] & lowMask & highMask))
+	}
+	n := bits.OnesCount(uint(p[first] & lowMask))
+	for _, ptr := range p[first+1 : last] {
+		n += bits.OnesCount(uint(ptr))
+	}
+	return n + bits.OnesCount(uint(p[last]&highMask))
+}
+
+// CountRange returns the number of set bits in the half-open interval
+// [start, end).  Only the bytes at the boundaries of the range are masked;
+// all bytes in between are counted whole.  This method will panic if end
+// results in a byte index that exceeds the number of bytes held by the
+// bitset.
+func (s Bytes) CountRange(start, end int) int {
+	if start >= end {
+		return 0
+	}
+	first := uint(start) >> byteShift
+	last := uint(end-1) >> byteShift
+	lowMask := byte(0xff) << (uint(start) & byteModMask)
+	highMask := byte(0xff) >> (byteModMask - uint(end-1)&byteModMask)
+	if first == last {
+		return bits.OnesCount8(s[first] & lowMask & highMask)
+	}
+	n := bits.OnesCount8(s[first] & lowMask)
+	for _, b := range s[first+1 : last] {
+		n += bits.OnesCount8(b)
+	}
+	return n + bits.OnesCount8(s[last]&highMask)
+}
+
+// Count returns the total number of set bits in the bitset.
+func (s Sparse) Count() int {
+	n := 0
+	for _, ptr := range s {
+		n += bits.OnesCount(uint(ptr))
+	}
+	return n
+}
+
+// Any returns whether any bit in the bitset is set.
+func (p Pointers) Any() bool {
+	for _, ptr := range p {
+		if ptr != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// None returns whether no bits in the bitset are set.
+func (p Pointers) None() bool {
+	return !p.Any()
+}
+
+// All returns whether every bit in the range [0, numBits) is set.  Bits at or
+// beyond numBits are not considered.  This method will panic if numBits
+// results in a pointer index that exceeds the number of pointers held by the
+// bitset.
+func (p Pointers) All(numBits int) bool {
+	full := uint(numBits) >> ptrShift
+	for _, ptr := range p[:full] {
+		if ptr != ^uintptr(0) {
+			return false
+		}
+	}
+	rem := uint(numBits) & ptrModMask
+	if rem == 0 {
+		return true
+	}
+	mask := uintptr(1)<<rem - 1
+	return p[full]&mask == mask
+}
+
+// Any returns whether any bit in the bitset is set.
+func (s Bytes) Any() bool {
+	for _, b := range s {
+		if b != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// None returns whether no bits in the bitset are set.
+func (s Bytes) None() bool {
+	return !s.Any()
+}
+
+// All returns whether every bit in the range [0, numBits) is set.  Bits at or
+// beyond numBits are not considered.  This method will panic if numBits
+// results in a byte index that exceeds the number of bytes held by the
+// bitset.
+func (s Bytes) All(numBits int) bool {
+	full := uint(numBits) >> byteShift
+	for _, b := range s[:full] {
+		if b != 0xff {
+			return false
+		}
+	}
+	rem := uint(numBits) & byteModMask
+	if rem == 0 {
+		return true
+	}
+	mask := byte(1)<<rem - 1
+	return s[full]&mask == mask
+}
+
+// Any returns whether any bit in the bitset is set.
+func (s Sparse) Any() bool {
+	for _, ptr := range s {
+		if ptr != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// None returns whether no bits in the bitset are set.
+func (s Sparse) None() bool {
+	return !s.Any()
+}
+
+// All returns whether every bit in the range [0, numBits) is set.  Bits at or
+// beyond numBits are not considered.
+func (s Sparse) All(numBits int) bool {
+	full := int(uint(numBits) >> ptrShift)
+	for k := 0; k < full; k++ {
+		if s[k] != ^uintptr(0) {
+			return false
+		}
+	}
+	rem := uint(numBits) & ptrModMask
+	if rem == 0 {
+		return true
+	}
+	mask := uintptr(1)<<rem - 1
+	return s[full]&mask == mask
+}
+
+// CountZeros returns the number of unset bits in the range [0, numBits).
+// Bits at or beyond numBits are not considered.  This method will panic if
+// numBits results in a pointer index that exceeds the number of pointers held
+// by the bitset.
+func (p Pointers) CountZeros(numBits int) int {
+	return numBits - p.CountRange(0, numBits)
+}
+
+// CountZeros returns the number of unset bits in the range [0, numBits).
+// Bits at or beyond numBits are not considered.  This method will panic if
+// numBits results in a byte index that exceeds the number of bytes held by
+// the bitset.
+func (s Bytes) CountZeros(numBits int) int {
+	return numBits - s.CountRange(0, numBits)
+}
+
+// CountZeros returns the number of unset bits in the range [0, numBits).
+// Bits at or beyond numBits are not considered.
+func (s Sparse) CountZeros(numBits int) int {
+	full := int(uint(numBits) >> ptrShift)
+	mask := uintptr(1)<<(uint(numBits)&ptrModMask) - 1
+	n := numBits
+	for k, ptr := range s {
+		switch {
+		case k < full:
+			n -= bits.OnesCount(uint(ptr))
+		case k == full:
+			n -= bits.OnesCount(uint(ptr & mask))
+		}
+	}
+	return n
+}
+
+// AndCount returns the number of bits set in both p and other, without
+// allocating the intersection.
+func (p Pointers) AndCount(other Pointers) int {
+	n := 0
+	for i, ptr := range other[:min(len(p), len(other))] {
+		n += bits.OnesCount(uint(p[i] & ptr))
+	}
+	return n
+}
+
+// OrCount returns the number of bits set in either p or other, without
+// allocating the union.
+func (p Pointers) OrCount(other Pointers) int {
+	if len(p) < len(other) {
+		p, other = other, p
+	}
+	n := 0
+	for i, ptr := range other {
+		n += bits.OnesCount(uint(p[i] | ptr))
+	}
+	return n + p[len(other):].Count()
+}
+
+// XorCount returns the number of bits set in exactly one of p or other,
+// without allocating the symmetric difference.
+func (p Pointers) XorCount(other Pointers) int {
+	if len(p) < len(other) {
+		p, other = other, p
+	}
+	n := 0
+	for i, ptr := range other {
+		n += bits.OnesCount(uint(p[i] ^ ptr))
+	}
+	return n + p[len(other):].Count()
+}
+
+// AndCount returns the number of bits set in both s and other, without
+// allocating the intersection.
+func (s Bytes) AndCount(other Bytes) int {
+	n := 0
+	for i, b := range other[:min(len(s), len(other))] {
+		n += bits.OnesCount8(s[i] & b)
+	}
+	return n
+}
+
+// OrCount returns the number of bits set in either s or other, without
+// allocating the union.
+func (s Bytes) OrCount(other Bytes) int {
+	if len(s) < len(other) {
+		s, other = other, s
+	}
+	n := 0
+	for i, b := range other {
+		n += bits.OnesCount8(s[i] | b)
+	}
+	return n + s[len(other):].Count()
+}
+
+// XorCount returns the number of bits set in exactly one of s or other,
+// without allocating the symmetric difference.
+func (s Bytes) XorCount(other Bytes) int {
+	if len(s) < len(other) {
+		s, other = other, s
+	}
+	n := 0
+	for i, b := range other {
+		n += bits.OnesCount8(s[i] ^ b)
+	}
+	return n + s[len(other):].Count()
+}
+
+// AndCount returns the number of bits set in both s and other, without
+// allocating the intersection.  Only the smaller of the two maps is iterated.
+func (s Sparse) AndCount(other Sparse) int {
+	if len(other) < len(s) {
+		s, other = other, s
+	}
+	n := 0
+	for k, ptr := range s {
+		n += bits.OnesCount(uint(ptr & other[k]))
+	}
+	return n
+}
+
+// OrCount returns the number of bits set in either s or other, without
+// allocating the union.
+func (s Sparse) OrCount(other Sparse) int {
+	return s.Count() + other.Count() - s.AndCount(other)
+}
+
+// XorCount returns the number of bits set in exactly one of s or other,
+// without allocating the symmetric difference.
+func (s Sparse) XorCount(other Sparse) int {
+	return s.Count() + other.Count() - 2*s.AndCount(other)
+}
+
+// HammingDistance returns the number of bit positions at which p and other
+// differ.  It is equivalent to XorCount.
+func (p Pointers) HammingDistance(other Pointers) int {
+	return p.XorCount(other)
+}
+
+// HammingDistance returns the number of bit positions at which s and other
+// differ.  It is equivalent to XorCount.
+func (s Bytes) HammingDistance(other Bytes) int {
+	return s.XorCount(other)
+}
+
+// HammingDistance returns the number of bit positions at which s and other
+// differ.  It is equivalent to XorCount.
+func (s Sparse) HammingDistance(other Sparse) int {
+	return s.XorCount(other)
+}
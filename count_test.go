@@ -0,0 +1,231 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset_test
+
+import (
+	"testing"
+
+	. "github.com/jrick/bitset"
+)
+
+type counter interface {
+	BitSet
+	Count() int
+}
+
+func TestCount(t *testing.T) {
+	tests := []struct {
+		numBits int
+		set     []int
+	}{
+		{numBits: 0, set: nil},
+		{numBits: 1, set: []int{0}},
+		{numBits: 8, set: []int{0, 7}},
+		{numBits: 64, set: []int{0, 8, 16, 31, 32, 63}},
+		{numBits: 1024, set: []int{1, 2, 3, 500, 1000, 1023}},
+	}
+
+	for testNum, test := range tests {
+		for _, c := range []struct {
+			name string
+			bs   counter
+		}{
+			{"Pointers", NewPointers(test.numBits)},
+			{"Bytes", NewBytes(test.numBits)},
+			{"Sparse", make(Sparse)},
+		} {
+			for _, i := range test.set {
+				c.bs.Set(i)
+			}
+			if got := c.bs.Count(); got != len(test.set) {
+				t.Errorf("Test %d bitset %s: got count %d expected %d",
+					testNum, c.name, got, len(test.set))
+			}
+		}
+	}
+}
+
+func TestCountRange(t *testing.T) {
+	const numBits = 200
+	set := []int{0, 1, 7, 8, 31, 32, 63, 64, 65, 100, 127, 128, 199}
+	bitsets := []struct {
+		name string
+		bs   interface {
+			BitSet
+			CountRange(start, end int) int
+		}
+	}{
+		{"Pointers", NewPointers(numBits)},
+		{"Bytes", NewBytes(numBits)},
+	}
+	for _, nbs := range bitsets {
+		for _, i := range set {
+			nbs.bs.Set(i)
+		}
+		for start := 0; start <= numBits; start++ {
+			exp := 0
+			for end := start; end <= numBits; end++ {
+				if end > start && nbs.bs.Get(end-1) {
+					exp++
+				}
+				got := nbs.bs.CountRange(start, end)
+				if got != exp {
+					t.Fatalf("bitset %s: CountRange(%d, %d) got %d expected %d",
+						nbs.name, start, end, got, exp)
+				}
+			}
+		}
+	}
+}
+
+func TestAnyNoneAll(t *testing.T) {
+	type predicates interface {
+		BitSet
+		Any() bool
+		None() bool
+		All(numBits int) bool
+	}
+	tests := []struct {
+		numBits int
+		set     []int
+		any     bool
+		all     bool
+	}{
+		{numBits: 0, set: nil, any: false, all: true},
+		{numBits: 8, set: nil, any: false, all: false},
+		{numBits: 8, set: []int{3}, any: true, all: false},
+		{numBits: 3, set: []int{0, 1, 2}, any: true, all: true},
+		{numBits: 64, set: []int{63}, any: true, all: false},
+		{numBits: 70, set: seq(0, 70), any: true, all: true},
+		{numBits: 70, set: seq(0, 69), any: true, all: false},
+		{numBits: 128, set: seq(0, 128), any: true, all: true},
+	}
+
+	for testNum, test := range tests {
+		for _, c := range []struct {
+			name string
+			bs   predicates
+		}{
+			{"Pointers", NewPointers(test.numBits)},
+			{"Bytes", NewBytes(test.numBits)},
+			{"Sparse", make(Sparse)},
+		} {
+			for _, i := range test.set {
+				c.bs.Set(i)
+			}
+			if got := c.bs.Any(); got != test.any {
+				t.Errorf("Test %d bitset %s: Any got %v expected %v",
+					testNum, c.name, got, test.any)
+			}
+			if got := c.bs.None(); got == test.any {
+				t.Errorf("Test %d bitset %s: None got %v expected %v",
+					testNum, c.name, got, !test.any)
+			}
+			if got := c.bs.All(test.numBits); got != test.all {
+				t.Errorf("Test %d bitset %s: All got %v expected %v",
+					testNum, c.name, got, test.all)
+			}
+		}
+	}
+}
+
+// seq returns the integers in the range [start, end).
+func seq(start, end int) []int {
+	s := make([]int, 0, end-start)
+	for i := start; i < end; i++ {
+		s = append(s, i)
+	}
+	return s
+}
+
+func TestCountZeros(t *testing.T) {
+	tests := []struct {
+		capBits int
+		numBits int
+		set     []int
+		exp     int
+	}{
+		{capBits: 0, numBits: 0, set: nil, exp: 0},
+		{capBits: 8, numBits: 5, set: nil, exp: 5},
+		{capBits: 8, numBits: 5, set: []int{0, 4, 5, 7}, exp: 3},
+		{capBits: 64, numBits: 64, set: []int{0, 63}, exp: 62},
+		{capBits: 128, numBits: 70, set: []int{1, 69, 70, 127}, exp: 68},
+	}
+
+	for testNum, test := range tests {
+		for _, c := range []struct {
+			name string
+			bs   interface {
+				BitSet
+				CountZeros(numBits int) int
+			}
+		}{
+			{"Pointers", NewPointers(test.capBits)},
+			{"Bytes", NewBytes(test.capBits)},
+			{"Sparse", make(Sparse)},
+		} {
+			for _, i := range test.set {
+				c.bs.Set(i)
+			}
+			if got := c.bs.CountZeros(test.numBits); got != test.exp {
+				t.Errorf("Test %d bitset %s: got %d expected %d",
+					testNum, c.name, got, test.exp)
+			}
+		}
+	}
+}
+
+func TestFusedCounts(t *testing.T) {
+	tests := []struct {
+		a, b         []int
+		and, or, xor int
+	}{
+		{a: nil, b: nil, and: 0, or: 0, xor: 0},
+		{a: []int{1, 2, 3}, b: nil, and: 0, or: 3, xor: 3},
+		{a: []int{1, 2, 3}, b: []int{2, 3, 4}, and: 2, or: 4, xor: 2},
+		{a: []int{0, 64, 500}, b: []int{64, 65, 1000, 1001}, and: 1, or: 6, xor: 5},
+	}
+	for testNum, test := range tests {
+		pa, pb := NewPointersFromIndices(test.a), NewPointersFromIndices(test.b)
+		ba, bb := NewBytesFromIndices(test.a), NewBytesFromIndices(test.b)
+		sa, sb := NewSparseFromIndices(test.a), NewSparseFromIndices(test.b)
+		results := []struct {
+			name         string
+			and, or, xor int
+		}{
+			{"Pointers", pa.AndCount(pb), pa.OrCount(pb), pa.XorCount(pb)},
+			{"Pointers reversed", pb.AndCount(pa), pb.OrCount(pa), pb.XorCount(pa)},
+			{"Bytes", ba.AndCount(bb), ba.OrCount(bb), ba.XorCount(bb)},
+			{"Bytes reversed", bb.AndCount(ba), bb.OrCount(ba), bb.XorCount(ba)},
+			{"Sparse", sa.AndCount(sb), sa.OrCount(sb), sa.XorCount(sb)},
+		}
+		for _, r := range results {
+			if r.and != test.and || r.or != test.or || r.xor != test.xor {
+				t.Errorf("Test %d bitset %s: got and/or/xor %d/%d/%d expected %d/%d/%d",
+					testNum, r.name, r.and, r.or, r.xor,
+					test.and, test.or, test.xor)
+			}
+		}
+	}
+}
+
+func TestHammingDistance(t *testing.T) {
+	a := []int{0, 1, 63, 64, 200}
+	b := []int{1, 63, 65, 700}
+	const exp = 5
+	results := []struct {
+		name string
+		got  int
+	}{
+		{"Pointers", NewPointersFromIndices(a).HammingDistance(NewPointersFromIndices(b))},
+		{"Bytes", NewBytesFromIndices(a).HammingDistance(NewBytesFromIndices(b))},
+		{"Sparse", NewSparseFromIndices(a).HammingDistance(NewSparseFromIndices(b))},
+	}
+	for _, r := range results {
+		if r.got != exp {
+			t.Errorf("bitset %s: got %d expected %d", r.name, r.got, exp)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+// CountedPointers is a Pointers bitset which additionally tracks the number of
+// set bits as bits are set and unset.  This makes Count an O(1) operation at
+// the cost of an additional load and compare for every mutation.  It is
+// intended for workloads which query the cardinality of the set nearly as
+// often as they modify it.
+//
+// The underlying Pointers is not exposed, as modifying it directly would
+// invalidate the cached count.
+type CountedPointers struct {
+	p     Pointers
+	count int
+}
+
+// NewCountedPointers returns a new counted bitset that is capable of holding
+// numBits number of binary values.  All bits are initially unset.
+func NewCountedPointers(numBits int) *CountedPointers {
+	return &CountedPointers{p: NewPointers(numBits)}
+}
+
+// Get returns whether the bit at index i is set or not.  This method will
+// panic if the index results in a pointer index that exceeds the number of
+// pointers held by the bitset.
+func (c *CountedPointers) Get(i int) bool {
+	return c.p.Get(i)
+}
+
+// Set sets the bit at index i, incrementing the count if the bit was not
+// previously set.  This method will panic if the index results in a pointer
+// index that exceeds the number of pointers held by the bitset.
+func (c *CountedPointers) Set(i int) {
+	ptr := &c.p[uint(i)>>ptrShift]
+	mask := uintptr(1) << (uint(i) & ptrModMask)
+	if *ptr&mask == 0 {
+		*ptr |= mask
+		c.count++
+	}
+}
+
+// Unset unsets the bit at index i, decrementing the count if the bit was
+// previously set.  This method will panic if the index results in a pointer
+// index that exceeds the number of pointers held by the bitset.
+func (c *CountedPointers) Unset(i int) {
+	ptr := &c.p[uint(i)>>ptrShift]
+	mask := uintptr(1) << (uint(i) & ptrModMask)
+	if *ptr&mask != 0 {
+		*ptr &^= mask
+		c.count--
+	}
+}
+
+// SetBool sets or unsets the bit at index i depending on the value of b.
+// This method will panic if the index results in a pointer index that exceeds
+// the number of pointers held by the bitset.
+func (c *CountedPointers) SetBool(i int, b bool) {
+	if b {
+		c.Set(i)
+		return
+	}
+	c.Unset(i)
+}
+
+// Count returns the total number of set bits in the bitset.
+func (c *CountedPointers) Count() int {
+	return c.count
+}
+
+// Grow ensures that the bitset is large enough to hold numBits number of
+// bits.  Newly allocated bits are unset and do not change the count.
+func (c *CountedPointers) Grow(numBits int) {
+	c.p.Grow(numBits)
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset_test
+
+import (
+	"testing"
+
+	. "github.com/jrick/bitset"
+)
+
+func TestCountedPointers(t *testing.T) {
+	c := NewCountedPointers(100)
+	ops := []struct {
+		bit   int
+		val   bool
+		count int
+	}{
+		{bit: 0, val: true, count: 1},
+		{bit: 0, val: true, count: 1},
+		{bit: 64, val: true, count: 2},
+		{bit: 99, val: true, count: 3},
+		{bit: 1, val: false, count: 3},
+		{bit: 64, val: false, count: 2},
+		{bit: 64, val: false, count: 2},
+		{bit: 0, val: false, count: 1},
+	}
+	for opNum, op := range ops {
+		c.SetBool(op.bit, op.val)
+		if got := c.Get(op.bit); got != op.val {
+			t.Errorf("Op %d: bit %d got %v expected %v", opNum,
+				op.bit, got, op.val)
+		}
+		if got := c.Count(); got != op.count {
+			t.Errorf("Op %d: got count %d expected %d", opNum, got,
+				op.count)
+		}
+	}
+
+	c.Grow(1000)
+	c.Set(999)
+	if got := c.Count(); got != 2 {
+		t.Errorf("Grown set: got count %d expected 2", got)
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/bits"
+)
+
+// ErrInvalidDelta describes an error where a delta could not be applied to a
+// bitset because it was truncated or otherwise malformed.
+var ErrInvalidDelta = errors.New("bitset: invalid delta")
+
+// maxInt is the largest value representable by an int.
+const maxInt = int(^uint(0) >> 1)
+
+// A delta describes the changes between two versions of a bitset in terms
+// of the bitsets' byte representations, where byte j holds bits 8j through
+// 8j+7 with the least significant bit first.  This is the layout of a Bytes
+// bitset, and the encoding is therefore portable between Pointers and Bytes
+// bitsets and between machines with differing pointer sizes.  It is encoded
+// as:
+//
+//	uvarint  number of bytes in the newer bitset
+//	repeated for each changed byte, in increasing order:
+//	  uvarint  number of unchanged bytes since the previous changed byte
+//	  byte     the XOR of the older and newer byte
+//
+// Bytes of the older bitset beyond the length of the newer one are dropped by
+// the length header and do not appear as changes.
+
+// Delta returns the encoded changes needed to turn s into newer.  Applying
+// the result to a copy of s with ApplyDelta produces a bitset equal to newer.
+func (s Bytes) Delta(newer Bytes) []byte {
+	d := binary.AppendUvarint(nil, uint64(len(newer)))
+	next := 0
+	for i, b := range newer {
+		if i < len(s) {
+			b ^= s[i]
+		}
+		if b == 0 {
+			continue
+		}
+		d = binary.AppendUvarint(d, uint64(i-next))
+		d = append(d, b)
+		next = i + 1
+	}
+	return d
+}
+
+// ApplyDelta applies changes previously encoded by Delta, resizing s to the
+// length of the newer bitset.  If the delta is malformed, ErrInvalidDelta is
+// returned and s is not modified.
+func (s *Bytes) ApplyDelta(delta []byte) error {
+	return s.applyDelta(delta, maxInt)
+}
+
+// applyDelta applies a delta describing a bitset of no more than maxBits
+// bits.  Larger bitsets result in ErrTooLarge.
+func (s *Bytes) applyDelta(delta []byte, maxBits int) error {
+	n, err := checkDelta(delta, maxBits)
+	if err != nil {
+		return err
+	}
+	bs := *s
+	if n <= len(bs) {
+		bs = bs[:n]
+	} else {
+		bs = append(bs, make(Bytes, n-len(bs))...)
+	}
+	forEachDelta(delta, func(i int, x byte) {
+		bs[i] ^= x
+	})
+	*s = bs
+	return nil
+}
+
+// Delta returns the encoded changes needed to turn p into newer.  Applying
+// the result to a copy of p with ApplyDelta produces a bitset equal to newer.
+// The encoding is identical to that of the Bytes bitset with the same bits
+// set.
+func (p Pointers) Delta(newer Pointers) []byte {
+	const ptrBytes = ptrBits / 8
+	d := binary.AppendUvarint(nil, uint64(len(newer)*ptrBytes))
+	next := 0
+	for w, ptr := range newer {
+		if w < len(p) {
+			ptr ^= p[w]
+		}
+		for ptr != 0 {
+			// Shift of the lowest byte with any changed bits.
+			sh := uint(bits.TrailingZeros(uint(ptr))) &^ byteModMask
+			i := w*ptrBytes + int(sh>>byteShift)
+			d = binary.AppendUvarint(d, uint64(i-next))
+			d = append(d, byte(ptr>>sh))
+			next = i + 1
+			ptr &^= 0xff << sh
+		}
+	}
+	return d
+}
+
+// ApplyDelta applies changes previously encoded by Delta, resizing p to hold
+// the number of bytes of the newer bitset rounded up to whole pointers.  If
+// the delta is malformed, ErrInvalidDelta is returned and p is not modified.
+func (p *Pointers) ApplyDelta(delta []byte) error {
+	return p.applyDelta(delta, maxInt)
+}
+
+// applyDelta applies a delta describing a bitset of no more than maxBits
+// bits.  Larger bitsets result in ErrTooLarge.
+func (p *Pointers) applyDelta(delta []byte, maxBits int) error {
+	const ptrBytes = ptrBits / 8
+	n, err := checkDelta(delta, maxBits)
+	if err != nil {
+		return err
+	}
+	ptrs := *p
+	numPtrs := (n + ptrBytes - 1) / ptrBytes
+	if numPtrs <= len(ptrs) {
+		ptrs = ptrs[:numPtrs]
+	} else {
+		ptrs = append(ptrs, make(Pointers, numPtrs-len(ptrs))...)
+	}
+	// Bytes past the newer bitset's length which remain in the last
+	// pointer were dropped by the delta and must read as unset.
+	ptrs.clearFrom(n << byteShift)
+	forEachDelta(delta, func(i int, x byte) {
+		ptrs[i/ptrBytes] ^= uintptr(x) << (uint(i%ptrBytes) << byteShift)
+	})
+	*p = ptrs
+	return nil
+}
+
+// checkDelta validates an encoded delta, returning the byte length of the
+// newer bitset it describes.  Newer bitsets of more than maxBits bits result
+// in ErrTooLarge.
+func checkDelta(delta []byte, maxBits int) (int, error) {
+	n, r := binary.Uvarint(delta)
+	if r <= 0 || n > uint64(maxInt) {
+		return 0, ErrInvalidDelta
+	}
+	if n > uint64(maxBits>>byteShift) {
+		return 0, ErrTooLarge
+	}
+	delta = delta[r:]
+	next := uint64(0)
+	for len(delta) != 0 {
+		gap, r := binary.Uvarint(delta)
+		if r <= 0 || r >= len(delta) || gap >= n-next {
+			return 0, ErrInvalidDelta
+		}
+		next += gap + 1
+		delta = delta[r+1:]
+	}
+	return int(n), nil
+}
+
+// forEachDelta calls fn with the byte index and XOR mask of each change in a
+// delta which has already been validated by checkDelta.
+func forEachDelta(delta []byte, fn func(i int, x byte)) {
+	_, r := binary.Uvarint(delta)
+	delta = delta[r:]
+	next := 0
+	for len(delta) != 0 {
+		gap, r := binary.Uvarint(delta)
+		i := next + int(gap)
+		fn(i, delta[r])
+		next = i + 1
+		delta = delta[r+1:]
+	}
+}
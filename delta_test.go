@@ -0,0 +1,80 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset_test
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/jrick/bitset"
+)
+
+func TestDelta(t *testing.T) {
+	tests := []struct {
+		old, new []int
+	}{
+		{old: nil, new: nil},
+		{old: nil, new: []int{0, 7, 8, 300}},
+		{old: []int{0, 7, 8, 300}, new: nil},
+		{old: []int{1, 2, 3, 64, 1000}, new: []int{1, 3, 65, 1000}},
+		{old: []int{5000}, new: []int{5}},
+		{old: []int{0, 8, 15, 63}, new: []int{0}},
+	}
+	for testNum, test := range tests {
+		ob, nb := NewBytesFromIndices(test.old), NewBytesFromIndices(test.new)
+		op, np := NewPointersFromIndices(test.old), NewPointersFromIndices(test.new)
+
+		bd := ob.Delta(nb)
+		pd := op.Delta(np)
+		if err := ob.ApplyDelta(bd); err != nil {
+			t.Errorf("Test %d bitset Bytes: apply: %v", testNum, err)
+			continue
+		}
+		if !bytes.Equal(ob, nb) {
+			t.Errorf("Test %d bitset Bytes: got %v expected %v",
+				testNum, ob.ToSlice(), nb.ToSlice())
+		}
+		if err := op.ApplyDelta(pd); err != nil {
+			t.Errorf("Test %d bitset Pointers: apply: %v", testNum, err)
+			continue
+		}
+		if !op.Equal(np) || len(op) != len(np) {
+			t.Errorf("Test %d bitset Pointers: got %v expected %v",
+				testNum, op.ToSlice(), np.ToSlice())
+		}
+
+		// Deltas are interchangeable between representations.
+		cross := NewBytesFromIndices(test.old)
+		if err := cross.ApplyDelta(pd); err != nil || !cross.EqualPointers(np) {
+			t.Errorf("Test %d: Pointers delta applied to Bytes: got %v (err %v) expected %v",
+				testNum, cross.ToSlice(), err, np.ToSlice())
+		}
+		crossp := NewPointersFromIndices(test.old)
+		if err := crossp.ApplyDelta(bd); err != nil || !nb.EqualPointers(crossp) {
+			t.Errorf("Test %d: Bytes delta applied to Pointers: got %v (err %v) expected %v",
+				testNum, crossp.ToSlice(), err, nb.ToSlice())
+		}
+	}
+}
+
+func TestDeltaInvalid(t *testing.T) {
+	tests := [][]byte{
+		nil,
+		{0x80},       // truncated length
+		{1, 0},       // truncated change
+		{1, 1, 0xff}, // change beyond length
+		{2, 0, 1, 0, 1, 0, 1},
+	}
+	for testNum, delta := range tests {
+		s := NewBytesFromIndices([]int{1})
+		if err := s.ApplyDelta(delta); err != ErrInvalidDelta {
+			t.Errorf("Test %d: got error %v expected %v", testNum, err,
+				ErrInvalidDelta)
+		}
+		if !bytes.Equal(s, []byte{2}) {
+			t.Errorf("Test %d: bitset modified on error", testNum)
+		}
+	}
+}
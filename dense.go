@@ -0,0 +1,167 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+import "iter"
+
+// Dense is a pointer-backed bitset which records its logical length in bits.
+// A Pointers bitset can only describe its capacity as a whole number of
+// pointers, so operations that must know where the set ends, such as
+// Complement or All, require the caller to pass the bit length and are prone
+// to acting on unused tail bits.  Dense remembers the length it was created
+// with and maintains the invariant that no bit at or beyond that length is
+// ever set.
+//
+// Unlike Pointers, every index passed to a Dense is checked against its
+// logical length, and out of range indexes cause a panic even when they would
+// fall within the final pointer.
+type Dense struct {
+	p Pointers
+	n int
+}
+
+// NewDense returns a new bitset with a logical length of numBits bits, all of
+// which are unset.
+func NewDense(numBits int) *Dense {
+	return &Dense{p: NewPointers(numBits), n: numBits}
+}
+
+// Len returns the logical length of the bitset in bits.
+func (d *Dense) Len() int {
+	return d.n
+}
+
+// Pointers returns the Pointers bitset backing d.  The result shares memory
+// with d, and any bits set at or beyond d.Len() through it will violate the
+// invariants of the Dense bitset.
+func (d *Dense) Pointers() Pointers {
+	return d.p
+}
+
+// check panics if i is not a valid index of d.
+func (d *Dense) check(i int) {
+	if uint(i) >= uint(d.n) {
+		panic("bitset: index out of range")
+	}
+}
+
+// Get returns whether the bit at index i is set or not.  This method will
+// panic if i is not in the range [0, d.Len()).
+func (d *Dense) Get(i int) bool {
+	d.check(i)
+	return d.p.Get(i)
+}
+
+// Set sets the bit at index i.  This method will panic if i is not in the
+// range [0, d.Len()).
+func (d *Dense) Set(i int) {
+	d.check(i)
+	d.p.Set(i)
+}
+
+// Unset unsets the bit at index i.  This method will panic if i is not in the
+// range [0, d.Len()).
+func (d *Dense) Unset(i int) {
+	d.check(i)
+	d.p.Unset(i)
+}
+
+// SetBool sets or unsets the bit at index i depending on the value of b.
+// This method will panic if i is not in the range [0, d.Len()).
+func (d *Dense) SetBool(i int, b bool) {
+	d.check(i)
+	d.p.SetBool(i, b)
+}
+
+// Flip toggles the bit at index i.  This method will panic if i is not in the
+// range [0, d.Len()).
+func (d *Dense) Flip(i int) {
+	d.check(i)
+	d.p.Flip(i)
+}
+
+// Resize changes the logical length of d to numBits bits.  When growing, the
+// new bits are unset.  When shrinking, bits at or beyond numBits are
+// discarded.
+func (d *Dense) Resize(numBits int) {
+	if numBits < d.n {
+		d.p.Truncate(numBits)
+	} else {
+		d.p.Grow(numBits)
+	}
+	d.n = numBits
+}
+
+// Clone returns a copy of d which does not share memory with d.
+func (d *Dense) Clone() *Dense {
+	return &Dense{p: d.p.Clone(), n: d.n}
+}
+
+// Count returns the number of set bits in the bitset.
+func (d *Dense) Count() int {
+	return d.p.Count()
+}
+
+// CountZeros returns the number of unset bits in the bitset.
+func (d *Dense) CountZeros() int {
+	return d.n - d.p.Count()
+}
+
+// Any returns whether any bit in the bitset is set.
+func (d *Dense) Any() bool {
+	return d.p.Any()
+}
+
+// None returns whether no bits in the bitset are set.
+func (d *Dense) None() bool {
+	return d.p.None()
+}
+
+// All returns whether every bit in the bitset is set.
+func (d *Dense) All() bool {
+	return d.p.All(d.n)
+}
+
+// SetAll sets every bit in the bitset.
+func (d *Dense) SetAll() {
+	d.p.SetAll(d.n)
+}
+
+// ClearAll unsets every bit in the bitset.
+func (d *Dense) ClearAll() {
+	d.p.ClearAll()
+}
+
+// Complement inverts every bit in the bitset.
+func (d *Dense) Complement() {
+	d.p.Complement(d.n)
+}
+
+// Equal returns whether d and other have the same logical length and the
+// same bits set.
+func (d *Dense) Equal(other *Dense) bool {
+	return d.n == other.n && d.p.Equal(other.p)
+}
+
+// NextSet returns the index of the first set bit at or after index i, or -1
+// if there is no such bit.
+func (d *Dense) NextSet(i int) int {
+	return d.p.NextSet(i)
+}
+
+// NextClear returns the index of the first unset bit at or after index i, or
+// -1 if every bit from i through the end of the bitset is set.
+func (d *Dense) NextClear(i int) int {
+	if j := d.p.NextClear(i); j < d.n {
+		return j
+	}
+	return -1
+}
+
+// Ones returns an iterator over the indexes of all set bits in increasing
+// order.
+func (d *Dense) Ones() iter.Seq[int] {
+	return d.p.Ones()
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset_test
+
+import (
+	"testing"
+
+	. "github.com/jrick/bitset"
+)
+
+var _ BitSet = (*Dense)(nil)
+
+func TestDense(t *testing.T) {
+	for _, numBits := range []int{0, 1, 7, 64, 70, 130} {
+		d := NewDense(numBits)
+		if d.Len() != numBits || d.Any() || d.CountZeros() != numBits {
+			t.Errorf("NewDense(%d): len %d any %v zeros %d", numBits,
+				d.Len(), d.Any(), d.CountZeros())
+		}
+		d.Complement()
+		if d.Count() != numBits || !d.All() || d.NextClear(0) != -1 {
+			t.Errorf("Dense(%d) complement: count %d all %v nextclear %d",
+				numBits, d.Count(), d.All(), d.NextClear(0))
+		}
+		if numBits == 0 {
+			continue
+		}
+		d.Unset(numBits - 1)
+		if d.All() || d.NextClear(0) != numBits-1 {
+			t.Errorf("Dense(%d): All after unset", numBits)
+		}
+		d.ClearAll()
+		d.SetAll()
+		if d.Count() != numBits {
+			t.Errorf("Dense(%d) SetAll: count %d", numBits, d.Count())
+		}
+
+		d.Resize(numBits / 2)
+		if d.Count() != numBits/2 {
+			t.Errorf("Dense(%d) shrunk: count %d expected %d", numBits,
+				d.Count(), numBits/2)
+		}
+		d.Resize(numBits)
+		if d.Count() != numBits/2 || d.Get(numBits-1) {
+			t.Errorf("Dense(%d) regrown: count %d", numBits, d.Count())
+		}
+	}
+}
+
+func TestDenseBounds(t *testing.T) {
+	d := NewDense(10)
+	for _, i := range []int{-1, 10, 63} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Set(%d) on Dense of length 10 did not panic", i)
+				}
+			}()
+			d.Set(i)
+		}()
+	}
+}
@@ -17,5 +17,18 @@
 // This package contains three bitset implementations: Pointers for efficiency,
 // Bytes for situations where bitsets must be serialized or deserialized,
 // and Sparse for when memory efficiency is the most important factor when
-// working with sparse datasets.
+// working with sparse datasets.  Dense wraps a Pointers bitset together with
+// its logical length in bits for callers who would otherwise need to track
+// the length separately.  AutoGrowPointers and AutoGrowBytes grow themselves
+// when a bit is set beyond their allocated range, rather than panicking.
+// Words64 and Words32 use fixed 64-bit and 32-bit words respectively, and
+// have the same layout on every platform.
+//
+// Binary set operations between Pointers or Bytes bitsets of differing
+// lengths never panic.  The in-place operations (And, Or, Xor, and AndNot)
+// keep the length of the receiver: missing pointers or bytes of the operand
+// are treated as zero, and any beyond the end of the receiver are ignored.
+// OrGrow and XorGrow instead grow the receiver to the length of the operand
+// so that no bits are dropped, and AndTruncate shrinks the receiver to the
+// shorter of the two lengths.
 package bitset
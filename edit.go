@@ -0,0 +1,449 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+// Append appends the first otherLen bits of other to p, placing them
+// immediately after the first numBits bits of p.  As p does not record its
+// own logical length, numBits must be provided by the caller.  p is grown as
+// necessary to hold numBits+otherLen bits, and any bits of p beyond the
+// appended range are unset.  When numBits is not a multiple of the pointer
+// size, each appended pointer is split across two pointers of p.  This
+// method will panic if otherLen results in a pointer index that exceeds the
+// number of pointers held by other.
+func (p *Pointers) Append(numBits int, other Pointers, otherLen int) {
+	end := numBits + otherLen
+	p.Grow(end)
+	dst := *p
+	dst.clearFrom(numBits)
+	base := int(uint(numBits) >> ptrShift)
+	sh := uint(numBits) & ptrModMask
+	nw := (otherLen + ptrModMask) >> ptrShift
+	for j, ptr := range other[:nw] {
+		if j == nw-1 {
+			if rem := uint(otherLen) & ptrModMask; rem != 0 {
+				ptr &= 1<<rem - 1
+			}
+		}
+		dst[base+j] |= ptr << sh
+		if sh != 0 && base+j+1 < len(dst) {
+			dst[base+j+1] |= ptr >> (ptrBits - sh)
+		}
+	}
+}
+
+// Append appends the first otherLen bits of other to s, placing them
+// immediately after the first numBits bits of s.  As s does not record its
+// own logical length, numBits must be provided by the caller.  s is grown as
+// necessary to hold numBits+otherLen bits, and any bits of s beyond the
+// appended range are unset.  When numBits is not a multiple of eight, each
+// appended byte is split across two bytes of s.  This method will panic if
+// otherLen results in a byte index that exceeds the number of bytes held by
+// other.
+func (s *Bytes) Append(numBits int, other Bytes, otherLen int) {
+	end := numBits + otherLen
+	s.Grow(end)
+	dst := *s
+	dst.clearFrom(numBits)
+	base := int(uint(numBits) >> byteShift)
+	sh := uint(numBits) & byteModMask
+	nb := (otherLen + byteModMask) >> byteShift
+	for j, b := range other[:nb] {
+		if j == nb-1 {
+			if rem := uint(otherLen) & byteModMask; rem != 0 {
+				b &= 1<<rem - 1
+			}
+		}
+		dst[base+j] |= b << sh
+		if sh != 0 && base+j+1 < len(dst) {
+			dst[base+j+1] |= b >> (8 - sh)
+		}
+	}
+}
+
+// Slice returns a new bitset holding the bits of p in the range [start, end),
+// shifted down so that the bit at index start is at index zero.  The result
+// does not share memory with p.  This method will panic if start is negative
+// or greater than end, or if end results in a pointer index that exceeds the
+// number of pointers held by the bitset.
+func (p Pointers) Slice(start, end int) Pointers {
+	src := p[:(end+ptrModMask)>>ptrShift]
+	out := NewPointers(end - start)
+	src.ShiftRightTo(out, start)
+	out.clearFrom(end - start)
+	return out
+}
+
+// Slice returns a new bitset holding the bits of s in the range [start, end),
+// shifted down so that the bit at index start is at index zero.  The result
+// does not share memory with s.  This method will panic if start is negative
+// or greater than end, or if end results in a byte index that exceeds the
+// number of bytes held by the bitset.
+func (s Bytes) Slice(start, end int) Bytes {
+	src := s[:(end+byteModMask)>>byteShift]
+	out := NewBytes(end - start)
+	src.ShiftRightTo(out, start)
+	out.clearFrom(end - start)
+	return out
+}
+
+// CopyRange copies n bits from src, beginning at index srcOff, into dst,
+// beginning at index dstOff, and returns n.  When dst and src are both
+// Pointers or both Bytes bitsets, the bits are moved a whole pointer or byte
+// at a time, shifting as needed when the offsets are not equally aligned.
+// Other combinations of bitsets are copied a bit at a time.
+//
+// If dst and src are the same bitset, the ranges may overlap, and the copy
+// behaves as if the source bits were first copied to a temporary buffer.
+// CopyRange will panic if either range exceeds the bits held by its bitset,
+// in which case dst may be partially modified.
+func CopyRange(dst BitSet, dstOff int, src BitSet, srcOff, n int) int {
+	if n <= 0 {
+		return 0
+	}
+	switch d := dst.(type) {
+	case Pointers:
+		if s, ok := src.(Pointers); ok {
+			copyChunks(dstOff, srcOff, n, ptrBits, func(do, so int, width uint) {
+				d.setBitsAt(do, width, s.bitsAt(so, width))
+			})
+			return n
+		}
+	case Bytes:
+		if s, ok := src.(Bytes); ok {
+			copyChunks(dstOff, srcOff, n, 8, func(do, so int, width uint) {
+				d.setBitsAt(do, width, s.bitsAt(so, width))
+			})
+			return n
+		}
+	}
+	copyChunks(dstOff, srcOff, n, 1, func(do, so int, _ uint) {
+		dst.SetBool(do, src.Get(so))
+	})
+	return n
+}
+
+// copyChunks calls fn with the destination and source offsets and width of
+// each chunk of at most chunkBits bits making up a copy of n bits.  Chunks
+// are visited from the end of the range to the start when dstOff is greater
+// than srcOff so that overlapping copies within a single bitset do not
+// overwrite source bits before they are read.
+func copyChunks(dstOff, srcOff, n, chunkBits int, fn func(do, so int, width uint)) {
+	if dstOff <= srcOff {
+		for k := 0; k < n; k += chunkBits {
+			fn(dstOff+k, srcOff+k, uint(min(chunkBits, n-k)))
+		}
+		return
+	}
+	for k := n; k > 0; k -= chunkBits {
+		width := min(chunkBits, k)
+		fn(dstOff+k-width, srcOff+k-width, uint(width))
+	}
+}
+
+// bitsAt returns the width bits of p beginning at index off, stitched
+// together from two pointers if they span a pointer boundary.  width must be
+// in the range [1, ptrBits].
+func (p Pointers) bitsAt(off int, width uint) uintptr {
+	w := uint(off) >> ptrShift
+	sh := uint(off) & ptrModMask
+	v := p[w] >> sh
+	if sh != 0 && sh+width > ptrBits {
+		v |= p[w+1] << (ptrBits - sh)
+	}
+	if width < ptrBits {
+		v &= 1<<width - 1
+	}
+	return v
+}
+
+// setBitsAt replaces the width bits of p beginning at index off with the low
+// width bits of v.  width must be in the range [1, ptrBits].
+func (p Pointers) setBitsAt(off int, width uint, v uintptr) {
+	w := uint(off) >> ptrShift
+	sh := uint(off) & ptrModMask
+	mask := ^uintptr(0)
+	if width < ptrBits {
+		mask = 1<<width - 1
+	}
+	v &= mask
+	p[w] = p[w]&^(mask<<sh) | v<<sh
+	if sh != 0 && sh+width > ptrBits {
+		p[w+1] = p[w+1]&^(mask>>(ptrBits-sh)) | v>>(ptrBits-sh)
+	}
+}
+
+// bitsAt returns the width bits of s beginning at index off, stitched
+// together from two bytes if they span a byte boundary.  width must be in the
+// range [1, 8].
+func (s Bytes) bitsAt(off int, width uint) byte {
+	i := uint(off) >> byteShift
+	sh := uint(off) & byteModMask
+	v := s[i] >> sh
+	if sh != 0 && sh+width > 8 {
+		v |= s[i+1] << (8 - sh)
+	}
+	if width < 8 {
+		v &= 1<<width - 1
+	}
+	return v
+}
+
+// setBitsAt replaces the width bits of s beginning at index off with the low
+// width bits of v.  width must be in the range [1, 8].
+func (s Bytes) setBitsAt(off int, width uint, v byte) {
+	i := uint(off) >> byteShift
+	sh := uint(off) & byteModMask
+	mask := byte(0xff)
+	if width < 8 {
+		mask = 1<<width - 1
+	}
+	v &= mask
+	s[i] = s[i]&^(mask<<sh) | v<<sh
+	if sh != 0 && sh+width > 8 {
+		s[i+1] = s[i+1]&^(mask>>(8-sh)) | v>>(8-sh)
+	}
+}
+
+// InsertBit inserts a bit with the value b at index i, moving every bit at or
+// above i up by one index.  If the highest bit of p is set, p is grown by a
+// pointer so that it is not shifted out of the bitset.  This method will
+// panic if the index results in a pointer index that exceeds the number of
+// pointers held by the bitset.
+func (p *Pointers) InsertBit(i int, b bool) {
+	ptrs := *p
+	w := int(uint(i) >> ptrShift)
+	sh := uint(i) & ptrModMask
+	_ = ptrs[w] // bounds check before any growth
+	if ptrs[len(ptrs)-1]>>ptrModMask != 0 {
+		ptrs = append(ptrs, 0)
+		*p = ptrs
+	}
+	for k := len(ptrs) - 1; k > w; k-- {
+		ptrs[k] = ptrs[k]<<1 | ptrs[k-1]>>ptrModMask
+	}
+	low := ptrs[w] & (1<<sh - 1)
+	ptrs[w] = low | (ptrs[w]&^low)<<1
+	if b {
+		ptrs[w] |= 1 << sh
+	}
+}
+
+// DeleteBit removes the bit at index i, moving every bit above i down by one
+// index.  The highest bit of p becomes unset.  This method will panic if the
+// index results in a pointer index that exceeds the number of pointers held
+// by the bitset.
+func (p Pointers) DeleteBit(i int) {
+	w := int(uint(i) >> ptrShift)
+	sh := uint(i) & ptrModMask
+	low := p[w] & (1<<sh - 1)
+	p[w] = low | p[w]>>1&^(1<<sh-1)
+	for k := w + 1; k < len(p); k++ {
+		p[k-1] |= p[k] << ptrModMask
+		p[k] >>= 1
+	}
+}
+
+// InsertBit inserts a bit with the value b at index i, moving every bit at or
+// above i up by one index.  If the highest bit of s is set, s is grown by a
+// byte so that it is not shifted out of the bitset.  This method will panic
+// if the index results in a byte index that exceeds the number of bytes held
+// by the bitset.
+func (s *Bytes) InsertBit(i int, b bool) {
+	bs := *s
+	j := int(uint(i) >> byteShift)
+	sh := uint(i) & byteModMask
+	_ = bs[j] // bounds check before any growth
+	if bs[len(bs)-1]>>byteModMask != 0 {
+		bs = append(bs, 0)
+		*s = bs
+	}
+	for k := len(bs) - 1; k > j; k-- {
+		bs[k] = bs[k]<<1 | bs[k-1]>>byteModMask
+	}
+	low := bs[j] & (1<<sh - 1)
+	bs[j] = low | (bs[j]&^low)<<1
+	if b {
+		bs[j] |= 1 << sh
+	}
+}
+
+// DeleteBit removes the bit at index i, moving every bit above i down by one
+// index.  The highest bit of s becomes unset.  This method will panic if the
+// index results in a byte index that exceeds the number of bytes held by the
+// bitset.
+func (s Bytes) DeleteBit(i int) {
+	j := int(uint(i) >> byteShift)
+	sh := uint(i) & byteModMask
+	low := s[j] & (1<<sh - 1)
+	s[j] = low | s[j]>>1&^(1<<sh-1)
+	for k := j + 1; k < len(s); k++ {
+		s[k-1] |= s[k] << byteModMask
+		s[k] >>= 1
+	}
+}
+
+// InsertRange inserts n unset bits at index i, moving every bit at or above
+// i up by n indexes.  p is grown as necessary so that no set bits are shifted
+// out of the bitset.  This method will panic if n is negative or if the index
+// results in a pointer index that exceeds the number of pointers held by the
+// bitset.
+func (p *Pointers) InsertRange(i, n int) {
+	total := len(*p) << ptrShift
+	if n < 0 || uint(i) >= uint(total) {
+		panic("bitset: insert range out of bounds")
+	}
+	if last := p.Last(); last >= i {
+		p.Grow(last + 1 + n)
+		total = len(*p) << ptrShift
+	}
+	ptrs := *p
+	CopyRange(ptrs, i+n, ptrs, i, total-i-n)
+	ptrs.clearRange(i, min(i+n, total))
+}
+
+// InsertFrom inserts the n bits of src beginning at index srcOff at index i of
+// p, moving every bit at or above i up by n indexes.  p is grown as necessary
+// as described by InsertRange.  src must not share memory with p.
+func (p *Pointers) InsertFrom(i int, src Pointers, srcOff, n int) {
+	p.InsertRange(i, n)
+	p.Grow(i + n)
+	CopyRange(*p, i, src, srcOff, n)
+}
+
+// DeleteRange removes the n bits in the range [i, i+n), moving every bit at
+// or above i+n down by n indexes.  The n highest bits of p become unset.  This
+// method will panic if the range exceeds the bits held by the bitset.
+func (p Pointers) DeleteRange(i, n int) {
+	total := len(p) << ptrShift
+	if i < 0 || n < 0 || i+n > total {
+		panic("bitset: delete range out of bounds")
+	}
+	CopyRange(p, i, p, i+n, total-i-n)
+	p.clearRange(total-n, total)
+}
+
+// clearRange unsets every bit of p in the range [start, end).
+func (p Pointers) clearRange(start, end int) {
+	for start < end {
+		width := min(ptrBits-start&ptrModMask, end-start)
+		p.setBitsAt(start, uint(width), 0)
+		start += width
+	}
+}
+
+// InsertRange inserts n unset bits at index i, moving every bit at or above
+// i up by n indexes.  s is grown as necessary so that no set bits are shifted
+// out of the bitset.  This method will panic if n is negative or if the index
+// results in a byte index that exceeds the number of bytes held by the
+// bitset.
+func (s *Bytes) InsertRange(i, n int) {
+	total := len(*s) << byteShift
+	if n < 0 || uint(i) >= uint(total) {
+		panic("bitset: insert range out of bounds")
+	}
+	if last := s.Last(); last >= i {
+		s.Grow(last + 1 + n)
+		total = len(*s) << byteShift
+	}
+	bs := *s
+	CopyRange(bs, i+n, bs, i, total-i-n)
+	bs.clearRange(i, min(i+n, total))
+}
+
+// InsertFrom inserts the n bits of src beginning at index srcOff at index i of
+// s, moving every bit at or above i up by n indexes.  s is grown as necessary
+// as described by InsertRange.  src must not share memory with s.
+func (s *Bytes) InsertFrom(i int, src Bytes, srcOff, n int) {
+	s.InsertRange(i, n)
+	s.Grow(i + n)
+	CopyRange(*s, i, src, srcOff, n)
+}
+
+// DeleteRange removes the n bits in the range [i, i+n), moving every bit at
+// or above i+n down by n indexes.  The n highest bits of s become unset.  This
+// method will panic if the range exceeds the bits held by the bitset.
+func (s Bytes) DeleteRange(i, n int) {
+	total := len(s) << byteShift
+	if i < 0 || n < 0 || i+n > total {
+		panic("bitset: delete range out of bounds")
+	}
+	CopyRange(s, i, s, i+n, total-i-n)
+	s.clearRange(total-n, total)
+}
+
+// clearRange unsets every bit of s in the range [start, end).
+func (s Bytes) clearRange(start, end int) {
+	for start < end {
+		width := min(8-start&byteModMask, end-start)
+		s.setBitsAt(start, uint(width), 0)
+		start += width
+	}
+}
+
+// SwapBits exchanges the values of the bits at indexes i and j.  This method
+// will panic if either index results in a pointer index that exceeds the
+// number of pointers held by the bitset.
+func (p Pointers) SwapBits(i, j int) {
+	bi, bj := p.Get(i), p.Get(j)
+	if bi != bj {
+		p.Flip(i)
+		p.Flip(j)
+	}
+}
+
+// SwapBits exchanges the values of the bits at indexes i and j.  This method
+// will panic if either index results in a byte index that exceeds the number
+// of bytes held by the bitset.
+func (s Bytes) SwapBits(i, j int) {
+	bi, bj := s.Get(i), s.Get(j)
+	if bi != bj {
+		s.Flip(i)
+		s.Flip(j)
+	}
+}
+
+// SwapBits exchanges the values of the bits at indexes i and j.
+func (s Sparse) SwapBits(i, j int) {
+	bi, bj := s.Get(i), s.Get(j)
+	if bi != bj {
+		s.Flip(i)
+		s.Flip(j)
+	}
+}
+
+// SwapRange exchanges the n bits of a beginning at index aOff with the n bits
+// of b beginning at index bOff.  As with CopyRange, bits are exchanged a
+// whole pointer or byte at a time when a and b are both Pointers or both Bytes
+// bitsets, and a bit at a time otherwise.  If a and b are the same bitset,
+// the two ranges must not overlap.  SwapRange will panic if either range
+// exceeds the bits held by its bitset, in which case both bitsets may be
+// partially modified.
+func SwapRange(a BitSet, aOff int, b BitSet, bOff, n int) {
+	switch a := a.(type) {
+	case Pointers:
+		if b, ok := b.(Pointers); ok {
+			copyChunks(aOff, bOff, n, ptrBits, func(ao, bo int, width uint) {
+				av, bv := a.bitsAt(ao, width), b.bitsAt(bo, width)
+				a.setBitsAt(ao, width, bv)
+				b.setBitsAt(bo, width, av)
+			})
+			return
+		}
+	case Bytes:
+		if b, ok := b.(Bytes); ok {
+			copyChunks(aOff, bOff, n, 8, func(ao, bo int, width uint) {
+				av, bv := a.bitsAt(ao, width), b.bitsAt(bo, width)
+				a.setBitsAt(ao, width, bv)
+				b.setBitsAt(bo, width, av)
+			})
+			return
+		}
+	}
+	copyChunks(aOff, bOff, n, 1, func(ao, bo int, _ uint) {
+		av, bv := a.Get(ao), b.Get(bo)
+		a.SetBool(ao, bv)
+		b.SetBool(bo, av)
+	})
+}
@@ -0,0 +1,357 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset_test
+
+import (
+	"testing"
+	"unsafe"
+
+	. "github.com/jrick/bitset"
+)
+
+func TestAppend(t *testing.T) {
+	tests := []struct {
+		a        []int
+		numBits  int
+		b        []int
+		otherLen int
+		exp      []int
+	}{
+		{a: nil, numBits: 0, b: nil, otherLen: 0, exp: nil},
+		{a: []int{0, 2}, numBits: 3, b: []int{0, 1}, otherLen: 2, exp: []int{0, 2, 3, 4}},
+		{a: []int{1, 10}, numBits: 5, b: []int{0, 4}, otherLen: 4, exp: []int{1, 5}},
+		{a: []int{63}, numBits: 64, b: []int{0, 63, 64}, otherLen: 65, exp: []int{63, 64, 127, 128}},
+		{a: []int{0}, numBits: 37, b: []int{0, 60, 70, 100}, otherLen: 100, exp: []int{0, 37, 97, 107}},
+	}
+	for testNum, test := range tests {
+		p := NewPointersFromIndices(test.a)
+		p.Append(test.numBits, NewPointersFromIndices(test.b), test.otherLen)
+		if got := p.ToSlice(); !equalIndices(got, test.exp) {
+			t.Errorf("Test %d bitset Pointers: got %v expected %v",
+				testNum, got, test.exp)
+		}
+		b := NewBytesFromIndices(test.a)
+		other := NewBytesFromIndices(test.b)
+		other.Grow(test.otherLen)
+		b.Append(test.numBits, other, test.otherLen)
+		if got := b.ToSlice(); !equalIndices(got, test.exp) {
+			t.Errorf("Test %d bitset Bytes: got %v expected %v",
+				testNum, got, test.exp)
+		}
+	}
+}
+
+func TestSlice(t *testing.T) {
+	set := []int{0, 3, 8, 63, 64, 65, 100, 190, 199}
+	p := NewPointers(200)
+	b := NewBytes(200)
+	p.SetMany(set)
+	b.SetMany(set)
+	for _, r := range [][2]int{{0, 0}, {0, 200}, {3, 4}, {1, 65}, {60, 101}, {64, 128}, {99, 191}, {199, 200}} {
+		start, end := r[0], r[1]
+		var exp []int
+		for _, i := range set {
+			if i >= start && i < end {
+				exp = append(exp, i-start)
+			}
+		}
+		ps := p.Slice(start, end)
+		if got := ps.ToSlice(); !equalIndices(got, exp) {
+			t.Errorf("Pointers Slice(%d, %d): got %v expected %v",
+				start, end, got, exp)
+		}
+		bs := b.Slice(start, end)
+		if got := bs.ToSlice(); !equalIndices(got, exp) {
+			t.Errorf("Bytes Slice(%d, %d): got %v expected %v",
+				start, end, got, exp)
+		}
+		if len(bs) != (end-start+7)/8 {
+			t.Errorf("Bytes Slice(%d, %d): got len %d", start, end, len(bs))
+		}
+	}
+}
+
+func TestCopyRange(t *testing.T) {
+	const numBits = 300
+	src := []int{0, 1, 5, 63, 64, 65, 100, 127, 128, 200, 250, 299}
+	dstSet := []int{2, 3, 70, 150, 151, 298}
+	tests := []struct{ dstOff, srcOff, n int }{
+		{0, 0, 0},
+		{0, 0, 300},
+		{1, 0, 299},
+		{0, 1, 299},
+		{37, 5, 200},
+		{5, 37, 200},
+		{64, 0, 128},
+		{100, 100, 64},
+		{3, 250, 50},
+	}
+	bitsets := func() []BitSet {
+		p, b, s := NewPointers(numBits), NewBytes(numBits), make(Sparse)
+		return []BitSet{p, b, s}
+	}
+	setAll := func(bs BitSet, set []int) BitSet {
+		for _, i := range set {
+			bs.Set(i)
+		}
+		return bs
+	}
+	for testNum, test := range tests {
+		// Expected results computed with a []bool.
+		srcBools := make([]bool, numBits)
+		for _, i := range src {
+			srcBools[i] = true
+		}
+		exp := make([]bool, numBits)
+		for _, i := range dstSet {
+			exp[i] = true
+		}
+		copy(exp[test.dstOff:test.dstOff+test.n], srcBools[test.srcOff:test.srcOff+test.n])
+
+		// Overlapping copy within a single bitset.
+		expSelf := append([]bool(nil), srcBools...)
+		copy(expSelf[test.dstOff:test.dstOff+test.n], srcBools[test.srcOff:test.srcOff+test.n])
+
+		for _, d := range bitsets() {
+			for _, s := range bitsets() {
+				setAll(d, dstSet)
+				setAll(s, src)
+				CopyRange(d, test.dstOff, s, test.srcOff, test.n)
+				for i := 0; i < numBits; i++ {
+					if d.Get(i) != exp[i] {
+						t.Errorf("Test %d %T<-%T: bit %d got %v expected %v",
+							testNum, d, s, i, d.Get(i), exp[i])
+						break
+					}
+				}
+				for i := 0; i < numBits; i++ {
+					d.Unset(i)
+					s.Unset(i)
+				}
+			}
+
+			setAll(d, src)
+			CopyRange(d, test.dstOff, d, test.srcOff, test.n)
+			for i := 0; i < numBits; i++ {
+				if d.Get(i) != expSelf[i] {
+					t.Errorf("Test %d %T overlapping: bit %d got %v expected %v",
+						testNum, d, i, d.Get(i), expSelf[i])
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestInsertDeleteBit(t *testing.T) {
+	const numBits = 192
+	set := []int{0, 5, 62, 63, 64, 100, 127, 128, 190}
+	for _, i := range []int{0, 1, 5, 7, 8, 63, 64, 65, 127, 128, 191} {
+		for _, val := range []bool{false, true} {
+			ref := make([]bool, numBits)
+			for _, j := range set {
+				ref[j] = true
+			}
+			ins := append(append(append([]bool(nil), ref[:i]...), val), ref[i:]...)
+			del := append(append([]bool(nil), ref[:i]...), ref[i+1:]...)
+			del = append(del, false)
+
+			p := NewPointers(numBits)
+			b := NewBytes(numBits)
+			p.SetMany(set)
+			b.SetMany(set)
+			p.InsertBit(i, val)
+			b.InsertBit(i, val)
+			for j, exp := range ins[:numBits] {
+				if p.Get(j) != exp || b.Get(j) != exp {
+					t.Fatalf("InsertBit(%d, %v): bit %d Pointers %v Bytes %v expected %v",
+						i, val, j, p.Get(j), b.Get(j), exp)
+				}
+			}
+
+			p = NewPointers(numBits)
+			b = NewBytes(numBits)
+			p.SetMany(set)
+			b.SetMany(set)
+			p.DeleteBit(i)
+			b.DeleteBit(i)
+			for j, exp := range del {
+				if p.Get(j) != exp || b.Get(j) != exp {
+					t.Fatalf("DeleteBit(%d): bit %d Pointers %v Bytes %v expected %v",
+						i, j, p.Get(j), b.Get(j), exp)
+				}
+			}
+		}
+	}
+
+	// The highest bit is kept by growing.
+	p := NewPointers(64)
+	p.Set(63)
+	p.InsertBit(0, false)
+	if !p.Get(64) {
+		t.Errorf("Pointers InsertBit dropped the highest bit")
+	}
+	b := NewBytes(8)
+	b.Set(7)
+	b.InsertBit(0, true)
+	if len(b) != 2 || !b.Get(8) || !b.Get(0) {
+		t.Errorf("Bytes InsertBit dropped the highest bit")
+	}
+}
+
+func TestInsertDeleteRange(t *testing.T) {
+	const numBits = 192
+	set := []int{0, 5, 62, 63, 64, 100, 127, 128, 190}
+	src := []int{0, 2, 3, 9, 60, 61}
+	for _, i := range []int{0, 3, 8, 63, 64, 100, 191} {
+		for _, n := range []int{0, 1, 7, 8, 9, 64, 65, 100} {
+			ref := make([]bool, numBits)
+			for _, j := range set {
+				ref[j] = true
+			}
+			srcRef := make([]bool, 70)
+			for _, j := range src {
+				srcRef[j] = true
+			}
+			zeros := make([]bool, n)
+			insZeros := append(append(append([]bool(nil), ref[:i]...), zeros...), ref[i:]...)
+			insFrom := append(append(append([]bool(nil), ref[:i]...), srcRef[1:1+n%69]...), ref[i:]...)
+
+			check := func(name string, bs BitSet, exp []bool) {
+				capBits := 0
+				switch bs := bs.(type) {
+				case Pointers:
+					capBits = len(bs) * int(unsafe.Sizeof(uintptr(0))) * 8
+				case Bytes:
+					capBits = len(bs) * 8
+				}
+				for j, e := range exp {
+					if j >= capBits {
+						if e {
+							t.Fatalf("%s(%d, %d) %T: set bit %d shifted out",
+								name, i, n, bs, j)
+						}
+						continue
+					}
+					if bs.Get(j) != e {
+						t.Fatalf("%s(%d, %d) %T: bit %d got %v expected %v",
+							name, i, n, bs, j, bs.Get(j), e)
+					}
+				}
+			}
+
+			p, b := NewPointers(numBits), NewBytes(numBits)
+			p.SetMany(set)
+			b.SetMany(set)
+			p.InsertRange(i, n)
+			b.InsertRange(i, n)
+			check("InsertRange", p, insZeros)
+			check("InsertRange", b, insZeros)
+
+			p, b = NewPointers(numBits), NewBytes(numBits)
+			p.SetMany(set)
+			b.SetMany(set)
+			ps, bs := NewPointers(70), NewBytes(70)
+			ps.SetMany(src)
+			bs.SetMany(src)
+			p.InsertFrom(i, ps, 1, n%69)
+			b.InsertFrom(i, bs, 1, n%69)
+			check("InsertFrom", p, insFrom)
+			check("InsertFrom", b, insFrom)
+
+			if i+n > numBits {
+				continue
+			}
+			del := append(append([]bool(nil), ref[:i]...), ref[i+n:]...)
+			del = append(del, zeros...)
+			p, b = NewPointers(numBits), NewBytes(numBits)
+			p.SetMany(set)
+			b.SetMany(set)
+			p.DeleteRange(i, n)
+			b.DeleteRange(i, n)
+			check("DeleteRange", p, del)
+			check("DeleteRange", b, del)
+		}
+	}
+}
+
+func TestSwapBits(t *testing.T) {
+	type swapper interface {
+		BitSet
+		SwapBits(i, j int)
+	}
+	for _, nbs := range []struct {
+		name string
+		bs   swapper
+	}{
+		{"Pointers", NewPointers(128)},
+		{"Bytes", NewBytes(128)},
+		{"Sparse", make(Sparse)},
+	} {
+		nbs.bs.Set(3)
+		nbs.bs.Set(100)
+		nbs.bs.SwapBits(3, 70)
+		nbs.bs.SwapBits(100, 101)
+		nbs.bs.SwapBits(5, 6)
+		for i, exp := range map[int]bool{3: false, 70: true, 100: false, 101: true, 5: false, 6: false} {
+			if got := nbs.bs.Get(i); got != exp {
+				t.Errorf("bitset %s: bit %d got %v expected %v",
+					nbs.name, i, got, exp)
+			}
+		}
+	}
+}
+
+func TestSwapRange(t *testing.T) {
+	const numBits = 200
+	aSet := []int{0, 1, 5, 63, 64, 65, 100, 150, 199}
+	bSet := []int{2, 3, 70, 71, 130, 198}
+	tests := []struct{ aOff, bOff, n int }{
+		{0, 0, 200},
+		{3, 70, 100},
+		{64, 0, 64},
+		{17, 131, 69},
+	}
+	bitsets := func() []BitSet {
+		return []BitSet{NewPointers(numBits), NewBytes(numBits), make(Sparse)}
+	}
+	for testNum, test := range tests {
+		aRef, bRef := make([]bool, numBits), make([]bool, numBits)
+		for _, i := range aSet {
+			aRef[i] = true
+		}
+		for _, i := range bSet {
+			bRef[i] = true
+		}
+		aExp := append([]bool(nil), aRef...)
+		bExp := append([]bool(nil), bRef...)
+		copy(aExp[test.aOff:test.aOff+test.n], bRef[test.bOff:])
+		copy(bExp[test.bOff:test.bOff+test.n], aRef[test.aOff:])
+
+		for _, a := range bitsets() {
+			for _, b := range bitsets() {
+				for _, i := range aSet {
+					a.Set(i)
+				}
+				for _, i := range bSet {
+					b.Set(i)
+				}
+				SwapRange(a, test.aOff, b, test.bOff, test.n)
+				for i := 0; i < numBits; i++ {
+					if a.Get(i) != aExp[i] || b.Get(i) != bExp[i] {
+						t.Errorf("Test %d %T<->%T: bit %d got %v/%v expected %v/%v",
+							testNum, a, b, i, a.Get(i), b.Get(i),
+							aExp[i], bExp[i])
+						break
+					}
+				}
+				for i := 0; i < numBits; i++ {
+					a.Unset(i)
+					b.Unset(i)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,412 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"math/bits"
+)
+
+// ErrInvalidSparse describes an error where an encoded Sparse bitset could
+// not be decoded because it was truncated or otherwise malformed.
+var ErrInvalidSparse = errors.New("bitset: invalid sparse encoding")
+
+// The binary encoding of every bitset type is the layout of a Bytes bitset:
+// byte j holds bits 8j through 8j+7, with the least significant bit first.
+// Multi-byte words are therefore written in little endian order, and the
+// encoding of a bitset is independent of the machine pointer size.  A
+// Pointers bitset encodes to a whole number of pointers, so a bitset encoded
+// on a 64-bit machine and decoded on a 32-bit machine holds the same bits in
+// twice as many pointers.  The exception is Sparse, which uses the sparse
+// encoding described below.
+
+// MarshalBinary returns a copy of the bytes of s.  It implements the
+// encoding.BinaryMarshaler interface and never returns an error.
+func (s Bytes) MarshalBinary() ([]byte, error) {
+	return append([]byte(nil), s...), nil
+}
+
+// AppendBinary appends the bytes of s to b and returns the extended slice.
+// It implements the encoding.BinaryAppender interface and never returns an
+// error.
+func (s Bytes) AppendBinary(b []byte) ([]byte, error) {
+	return append(b, s...), nil
+}
+
+// AppendText appends the lowercase hexadecimal encoding of the bytes of s to
+// b and returns the extended slice.  It implements the encoding.TextAppender
+// interface and never returns an error.
+func (s Bytes) AppendText(b []byte) ([]byte, error) {
+	return hex.AppendEncode(b, s), nil
+}
+
+// MarshalText returns the lowercase hexadecimal encoding of the bytes of s.
+// It implements the encoding.TextMarshaler interface and never returns an
+// error.
+func (s Bytes) MarshalText() ([]byte, error) {
+	return s.AppendText(nil)
+}
+
+// UnmarshalText replaces the contents of s with the bytes decoded from the
+// hexadecimal text.  Upper and lower case digits are accepted.  It implements
+// the encoding.TextUnmarshaler interface.  If the text is not valid
+// hexadecimal, an error from the encoding/hex package is returned and s is
+// not modified.
+func (s *Bytes) UnmarshalText(text []byte) error {
+	b, err := hex.AppendDecode(nil, text)
+	if err != nil {
+		return err
+	}
+	*s = b
+	return nil
+}
+
+// UnmarshalBinary replaces the contents of s with a copy of data.  It
+// implements the encoding.BinaryUnmarshaler interface and never returns an
+// error.
+func (s *Bytes) UnmarshalBinary(data []byte) error {
+	*s = append((*s)[:0], data...)
+	return nil
+}
+
+// MarshalBinary encodes p using the layout of a Bytes bitset, writing each
+// pointer in little endian order.  It implements the
+// encoding.BinaryMarshaler interface and never returns an error.
+func (p Pointers) MarshalBinary() ([]byte, error) {
+	return p.appendBytes(make([]byte, 0, len(p)*(ptrBits/8))), nil
+}
+
+// AppendBinary appends the binary encoding of p to b and returns the
+// extended slice.  It implements the encoding.BinaryAppender interface and
+// never returns an error.
+func (p Pointers) AppendBinary(b []byte) ([]byte, error) {
+	return p.appendBytes(b), nil
+}
+
+// AppendText appends the lowercase hexadecimal encoding of the binary
+// encoding of p to b and returns the extended slice.  It implements the
+// encoding.TextAppender interface and never returns an error.
+func (p Pointers) AppendText(b []byte) ([]byte, error) {
+	var buf [ptrBits / 8]byte
+	for _, ptr := range p {
+		for i := range buf {
+			buf[i] = byte(ptr >> (uint(i) << byteShift))
+		}
+		b = hex.AppendEncode(b, buf[:])
+	}
+	return b, nil
+}
+
+// MarshalText returns the lowercase hexadecimal encoding of the binary
+// encoding of p.  It implements the encoding.TextMarshaler interface and never
+// returns an error.
+func (p Pointers) MarshalText() ([]byte, error) {
+	return p.AppendText(make([]byte, 0, len(p)*(ptrBits/4)))
+}
+
+// UnmarshalText replaces the contents of p with the bits of the binary
+// encoding decoded from the hexadecimal text.  Upper and lower case digits
+// are accepted.  It implements the encoding.TextUnmarshaler interface.  If the
+// text is not valid hexadecimal, an error from the encoding/hex package is
+// returned and p is not modified.
+func (p *Pointers) UnmarshalText(text []byte) error {
+	b, err := hex.AppendDecode(nil, text)
+	if err != nil {
+		return err
+	}
+	p.setBytes(b)
+	return nil
+}
+
+// UnmarshalBinary replaces the contents of p with the bits encoded in data,
+// using the layout of a Bytes bitset.  p is resized to hold every byte of
+// data, rounded up to whole pointers.  It implements the
+// encoding.BinaryUnmarshaler interface and never returns an error.
+func (p *Pointers) UnmarshalBinary(data []byte) error {
+	p.setBytes(data)
+	return nil
+}
+
+// appendBytes appends the little endian bytes of every pointer of p to dst
+// and returns the extended slice.
+func (p Pointers) appendBytes(dst []byte) []byte {
+	for _, ptr := range p {
+		if ptrBits == 64 {
+			dst = binary.LittleEndian.AppendUint64(dst, uint64(ptr))
+		} else {
+			dst = binary.LittleEndian.AppendUint32(dst, uint32(ptr))
+		}
+	}
+	return dst
+}
+
+// setBytes replaces the contents of p with the bits of the Bytes layout
+// data, reusing the capacity of p if possible.
+func (p *Pointers) setBytes(data []byte) {
+	const ptrBytes = ptrBits / 8
+	n := (len(data) + ptrBytes - 1) / ptrBytes
+	ptrs := *p
+	if cap(ptrs) < n {
+		ptrs = make(Pointers, n)
+	}
+	ptrs = ptrs[:n]
+	for w := range ptrs {
+		ptrs[w] = Bytes(data).ptrAt(w)
+	}
+	*p = ptrs
+}
+
+// MarshalBinary encodes w using the layout of a Bytes bitset, writing each
+// word in little endian order.  It implements the encoding.BinaryMarshaler
+// interface and never returns an error.
+func (w Words64) MarshalBinary() ([]byte, error) {
+	return w.Bytes(), nil
+}
+
+// AppendBinary appends the binary encoding of w to b and returns the
+// extended slice.  It implements the encoding.BinaryAppender interface and
+// never returns an error.
+func (w Words64) AppendBinary(b []byte) ([]byte, error) {
+	for _, word := range w {
+		b = binary.LittleEndian.AppendUint64(b, word)
+	}
+	return b, nil
+}
+
+// AppendText appends the lowercase hexadecimal encoding of the binary
+// encoding of w to b and returns the extended slice.  It implements the
+// encoding.TextAppender interface and never returns an error.
+func (w Words64) AppendText(b []byte) ([]byte, error) {
+	var buf [8]byte
+	for _, word := range w {
+		binary.LittleEndian.PutUint64(buf[:], word)
+		b = hex.AppendEncode(b, buf[:])
+	}
+	return b, nil
+}
+
+// MarshalText returns the lowercase hexadecimal encoding of the binary
+// encoding of w.  It implements the encoding.TextMarshaler interface and never
+// returns an error.
+func (w Words64) MarshalText() ([]byte, error) {
+	return w.AppendText(nil)
+}
+
+// UnmarshalText replaces the contents of w with the bits of the binary
+// encoding decoded from the hexadecimal text.  Upper and lower case digits
+// are accepted.  It implements the encoding.TextUnmarshaler interface.  If the
+// text is not valid hexadecimal, an error from the encoding/hex package is
+// returned and w is not modified.
+func (w *Words64) UnmarshalText(text []byte) error {
+	b, err := hex.AppendDecode(nil, text)
+	if err != nil {
+		return err
+	}
+	*w = NewWords64FromBytes(b)
+	return nil
+}
+
+// UnmarshalBinary replaces the contents of w with the bits encoded in data,
+// using the layout of a Bytes bitset.  w is resized to hold every byte of
+// data, rounded up to whole words.  It implements the
+// encoding.BinaryUnmarshaler interface and never returns an error.
+func (w *Words64) UnmarshalBinary(data []byte) error {
+	*w = NewWords64FromBytes(data)
+	return nil
+}
+
+// MarshalBinary encodes w using the layout of a Bytes bitset, writing each
+// word in little endian order.  It implements the encoding.BinaryMarshaler
+// interface and never returns an error.
+func (w Words32) MarshalBinary() ([]byte, error) {
+	return w.Bytes(), nil
+}
+
+// AppendBinary appends the binary encoding of w to b and returns the
+// extended slice.  It implements the encoding.BinaryAppender interface and
+// never returns an error.
+func (w Words32) AppendBinary(b []byte) ([]byte, error) {
+	for _, word := range w {
+		b = binary.LittleEndian.AppendUint32(b, word)
+	}
+	return b, nil
+}
+
+// AppendText appends the lowercase hexadecimal encoding of the binary
+// encoding of w to b and returns the extended slice.  It implements the
+// encoding.TextAppender interface and never returns an error.
+func (w Words32) AppendText(b []byte) ([]byte, error) {
+	var buf [4]byte
+	for _, word := range w {
+		binary.LittleEndian.PutUint32(buf[:], word)
+		b = hex.AppendEncode(b, buf[:])
+	}
+	return b, nil
+}
+
+// MarshalText returns the lowercase hexadecimal encoding of the binary
+// encoding of w.  It implements the encoding.TextMarshaler interface and never
+// returns an error.
+func (w Words32) MarshalText() ([]byte, error) {
+	return w.AppendText(nil)
+}
+
+// UnmarshalText replaces the contents of w with the bits of the binary
+// encoding decoded from the hexadecimal text.  Upper and lower case digits
+// are accepted.  It implements the encoding.TextUnmarshaler interface.  If the
+// text is not valid hexadecimal, an error from the encoding/hex package is
+// returned and w is not modified.
+func (w *Words32) UnmarshalText(text []byte) error {
+	b, err := hex.AppendDecode(nil, text)
+	if err != nil {
+		return err
+	}
+	*w = NewWords32FromBytes(b)
+	return nil
+}
+
+// UnmarshalBinary replaces the contents of w with the bits encoded in data,
+// using the layout of a Bytes bitset.  w is resized to hold every byte of
+// data, rounded up to whole words.  It implements the
+// encoding.BinaryUnmarshaler interface and never returns an error.
+func (w *Words32) UnmarshalBinary(data []byte) error {
+	*w = NewWords32FromBytes(data)
+	return nil
+}
+
+// The sparse encoding of a Sparse bitset records only its nonzero words, and
+// its size is therefore proportional to the number of set bits rather than
+// the highest set bit.  To be independent of the machine pointer size, the
+// bitset is described as 64-bit words, where word k holds bits 64k through
+// 64k+63 with the least significant bit first.  It is encoded as:
+//
+//	uvarint            number of nonzero words, n
+//	n uvarints         word keys in increasing order, each after the first
+//	                   encoded as its distance from the previous key less one
+//	n little endian    the nonzero words, in the same order as their keys
+//	uint64s
+//
+// Unlike ranging over the map, the encoding is deterministic: two Sparse
+// bitsets with the same bits set always produce identical encodings.  Zero
+// words are never encoded, and decoding rejects them.
+
+// MarshalBinary returns the sparse encoding of s.  It implements the
+// encoding.BinaryMarshaler interface and never returns an error.
+func (s Sparse) MarshalBinary() ([]byte, error) {
+	return s.appendSparse(nil), nil
+}
+
+// AppendBinary appends the sparse encoding of s to b and returns the extended
+// slice.  It implements the encoding.BinaryAppender interface and never
+// returns an error.
+func (s Sparse) AppendBinary(b []byte) ([]byte, error) {
+	return s.appendSparse(b), nil
+}
+
+// UnmarshalBinary replaces the contents of s with the bits of the sparse
+// encoding data, allocating the map if s is nil.  It implements the
+// encoding.BinaryUnmarshaler interface.  If the encoding is malformed,
+// ErrInvalidSparse is returned and s is not modified.
+func (s *Sparse) UnmarshalBinary(data []byte) error {
+	return s.setSparse(data, maxInt)
+}
+
+// words64 returns the keys and values of the nonzero 64-bit words of s in
+// increasing key order.
+func (s Sparse) words64() (keys []int, words []uint64) {
+	for _, k := range s.sortedKeys() {
+		ptr := s[k]
+		if ptr == 0 {
+			continue
+		}
+		// Negative indexes are stored by Sparse.Set as keys whose
+		// offsets overflow an int.
+		off := uint(k) << ptrShift
+		k64, sh := int(off>>6), off&63
+		if len(keys) != 0 && keys[len(keys)-1] == k64 {
+			words[len(words)-1] |= uint64(ptr) << sh
+			continue
+		}
+		keys = append(keys, k64)
+		words = append(words, uint64(ptr)<<sh)
+	}
+	return keys, words
+}
+
+// appendSparse appends the sparse encoding of s to dst and returns the
+// extended slice.
+func (s Sparse) appendSparse(dst []byte) []byte {
+	keys, words := s.words64()
+	dst = binary.AppendUvarint(dst, uint64(len(keys)))
+	prev := -1
+	for _, k := range keys {
+		dst = binary.AppendUvarint(dst, uint64(k-prev-1))
+		prev = k
+	}
+	for _, w := range words {
+		dst = binary.LittleEndian.AppendUint64(dst, w)
+	}
+	return dst
+}
+
+// setSparse replaces the contents of s with the bits of the sparse encoding
+// data.  If the encoding is malformed, ErrInvalidSparse is returned, and if
+// maxBits is less than maxInt and any set bit is at or beyond maxBits,
+// ErrTooLarge is returned.  In either case s is not modified.
+func (s *Sparse) setSparse(data []byte, maxBits int) error {
+	n, r := binary.Uvarint(data)
+	// Each word requires at least nine bytes, which bounds the allocation
+	// made for a hostile count.
+	if r <= 0 || n > uint64(len(data)-r)/9 {
+		return ErrInvalidSparse
+	}
+	data = data[r:]
+	// Keys extend through the word holding the largest index as an
+	// unsigned integer, which is where negative indexes are stored.
+	const maxKey = uint64(^uint(0) >> 6)
+	keys := make([]int, n)
+	next := uint64(0)
+	for i := range keys {
+		gap, r := binary.Uvarint(data)
+		if r <= 0 || next > maxKey || gap > maxKey-next {
+			return ErrInvalidSparse
+		}
+		keys[i] = int(next + gap)
+		next += gap + 1
+		data = data[r:]
+	}
+	if uint64(len(data)) != 8*n {
+		return ErrInvalidSparse
+	}
+	m := make(Sparse, n)
+	for _, k := range keys {
+		w := binary.LittleEndian.Uint64(data)
+		if w == 0 {
+			return ErrInvalidSparse
+		}
+		// Without a limit, negative indexes are accepted.
+		last := uint64(k)<<6 + uint64(63-bits.LeadingZeros64(w))
+		if maxBits != maxInt && last >= uint64(maxBits) {
+			return ErrTooLarge
+		}
+		data = data[8:]
+		for sh := uint(0); sh < 64; sh += ptrBits {
+			if ptr := uintptr(w >> sh); ptr != 0 {
+				m[int((uint(k)<<6+sh)>>ptrShift)] = ptr
+			}
+		}
+	}
+	if *s == nil {
+		*s = m
+		return nil
+	}
+	clear(*s)
+	for k, ptr := range m {
+		(*s)[k] = ptr
+	}
+	return nil
+}
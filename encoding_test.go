@@ -0,0 +1,222 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset_test
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/hex"
+	"testing"
+
+	. "github.com/jrick/bitset"
+)
+
+type binaryBitSet interface {
+	BitSet
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+}
+
+func TestMarshalBinary(t *testing.T) {
+	set := []int{0, 9, 31, 32, 63, 64, 127}
+	exp := NewBytesFromIndices(set)
+
+	for _, c := range []struct {
+		name string
+		bs   binaryBitSet
+		new  func() binaryBitSet
+	}{
+		{"Bytes", &exp, func() binaryBitSet { return new(Bytes) }},
+		{"Pointers", ptrTo(NewPointersFromIndices(set)), func() binaryBitSet { return new(Pointers) }},
+		{"Words64", ptrTo(NewWords64FromBytes(exp)), func() binaryBitSet { return new(Words64) }},
+		{"Words32", ptrTo(NewWords32FromBytes(exp)), func() binaryBitSet { return new(Words32) }},
+	} {
+		data, err := c.bs.MarshalBinary()
+		if err != nil {
+			t.Fatalf("bitset %s: MarshalBinary: %v", c.name, err)
+		}
+		// Every encoding is the Bytes layout, possibly padded with zero
+		// bytes to a whole number of words.
+		if !bytes.Equal(bytes.TrimRight(data, "\x00"), exp) {
+			t.Errorf("bitset %s: got encoding %x expected %x", c.name, data, exp)
+		}
+
+		decoded := c.new()
+		if err := decoded.UnmarshalBinary(data); err != nil {
+			t.Fatalf("bitset %s: UnmarshalBinary: %v", c.name, err)
+		}
+		for i := 0; i < len(exp)*8; i++ {
+			if decoded.Get(i) != exp.Get(i) {
+				t.Errorf("bitset %s: decoded bit %d got %v expected %v",
+					c.name, i, decoded.Get(i), exp.Get(i))
+			}
+		}
+	}
+}
+
+func TestSparseMarshalBinary(t *testing.T) {
+	tests := []struct {
+		set []int
+		exp []byte
+	}{
+		{set: nil, exp: []byte{0}},
+		{set: []int{0, 63}, exp: []byte{1, 0, 1, 0, 0, 0, 0, 0, 0, 0x80}},
+		{set: []int{1, 1 << 20}, exp: []byte{2, 0, 0xff, 0x7f,
+			2, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0}},
+	}
+	for testNum, test := range tests {
+		s := NewSparseFromIndices(test.set)
+		data, err := s.MarshalBinary()
+		if err != nil {
+			t.Fatalf("Test %d: MarshalBinary: %v", testNum, err)
+		}
+		if !bytes.Equal(data, test.exp) {
+			t.Errorf("Test %d: got encoding %x expected %x", testNum, data, test.exp)
+		}
+		appended, _ := s.AppendBinary([]byte{0xff})
+		if !bytes.Equal(appended[1:], data) || appended[0] != 0xff {
+			t.Errorf("Test %d: AppendBinary got %x", testNum, appended)
+		}
+
+		var decoded Sparse
+		if err := decoded.UnmarshalBinary(data); err != nil {
+			t.Fatalf("Test %d: UnmarshalBinary: %v", testNum, err)
+		}
+		if !decoded.Equal(s) {
+			t.Errorf("Test %d: decoded %v expected %v", testNum, decoded, s)
+		}
+	}
+
+	// Negative indexes, which are stored beyond the largest int, round
+	// trip without a limit.
+	s := NewSparseFromIndices([]int{-1, -64, 5})
+	data, _ := s.MarshalBinary()
+	var decoded Sparse
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("negative indexes: UnmarshalBinary: %v", err)
+	}
+	if !decoded.Equal(s) || !decoded.Get(-1) || !decoded.Get(-64) {
+		t.Errorf("negative indexes: decoded %v expected %v", decoded, s)
+	}
+	if err := (Limited{&decoded, 1 << 20}).UnmarshalBinary(data); err != ErrTooLarge {
+		t.Errorf("negative indexes: Limited got error %v expected %v", err, ErrTooLarge)
+	}
+}
+
+func TestUnmarshalBinaryReplaces(t *testing.T) {
+	p := NewPointersFromIndices([]int{0, 500})
+	if err := p.UnmarshalBinary([]byte{0x02}); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := p.ToSlice(), []int{1}; !equalIndices(got, exp) || len(p) != 1 {
+		t.Errorf("Pointers: got %v in %d pointers expected %v in 1", got, len(p), exp)
+	}
+	b := NewBytesFromIndices([]int{0, 500})
+	if err := b.UnmarshalBinary([]byte{0x02}); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := b.ToSlice(), []int{1}; !equalIndices(got, exp) || len(b) != 1 {
+		t.Errorf("Bytes: got %v in %d bytes expected %v in 1", got, len(b), exp)
+	}
+}
+
+// ptrTo returns a pointer to a copy of v.
+func ptrTo[T any](v T) *T {
+	return &v
+}
+
+type appender interface {
+	encoding.BinaryMarshaler
+	encoding.BinaryAppender
+	encoding.TextAppender
+}
+
+func TestAppendBinaryText(t *testing.T) {
+	set := []int{0, 9, 31, 32, 63, 64, 127, 200}
+	b := NewBytesFromIndices(set)
+	for _, c := range []struct {
+		name string
+		bs   appender
+	}{
+		{"Bytes", b},
+		{"Pointers", NewPointersFromIndices(set)},
+		{"Words64", NewWords64FromBytes(b)},
+		{"Words32", NewWords32FromBytes(b)},
+	} {
+		data, _ := c.bs.MarshalBinary()
+		prefix := []byte("prefix")
+
+		got, err := c.bs.AppendBinary(append([]byte(nil), prefix...))
+		if err != nil {
+			t.Fatalf("bitset %s: AppendBinary: %v", c.name, err)
+		}
+		if exp := append(append([]byte(nil), prefix...), data...); !bytes.Equal(got, exp) {
+			t.Errorf("bitset %s: AppendBinary got %x expected %x", c.name, got, exp)
+		}
+
+		got, err = c.bs.AppendText(append([]byte(nil), prefix...))
+		if err != nil {
+			t.Fatalf("bitset %s: AppendText: %v", c.name, err)
+		}
+		if exp := string(prefix) + hex.EncodeToString(data); string(got) != exp {
+			t.Errorf("bitset %s: AppendText got %s expected %s", c.name, got, exp)
+		}
+
+		buf := make([]byte, 0, 2*len(data))
+		allocs := testing.AllocsPerRun(10, func() {
+			buf, _ = c.bs.AppendBinary(buf[:0])
+			buf, _ = c.bs.AppendText(buf[:0])
+		})
+		if allocs != 0 {
+			t.Errorf("bitset %s: %v allocations appending to a large enough buffer",
+				c.name, allocs)
+		}
+	}
+}
+
+type textBitSet interface {
+	BitSet
+	encoding.TextMarshaler
+	encoding.TextUnmarshaler
+}
+
+func TestMarshalText(t *testing.T) {
+	set := []int{0, 9, 31, 32, 63, 64, 127}
+	const exp = "01020080010000800100000000000080"
+	for _, c := range []struct {
+		name string
+		bs   textBitSet
+		new  func() textBitSet
+	}{
+		{"Bytes", ptrTo(NewBytesFromIndices(set)), func() textBitSet { return new(Bytes) }},
+		{"Pointers", ptrTo(NewPointersFromIndices(set)), func() textBitSet { return new(Pointers) }},
+		{"Words64", ptrTo(NewWords64FromBytes(NewBytesFromIndices(set))), func() textBitSet { return new(Words64) }},
+		{"Words32", ptrTo(NewWords32FromBytes(NewBytesFromIndices(set))), func() textBitSet { return new(Words32) }},
+	} {
+		text, err := c.bs.MarshalText()
+		if err != nil {
+			t.Fatalf("bitset %s: MarshalText: %v", c.name, err)
+		}
+		if string(text) != exp {
+			t.Errorf("bitset %s: got %s expected %s", c.name, text, exp)
+		}
+		decoded := c.new()
+		if err := decoded.UnmarshalText(bytes.ToUpper(text)); err != nil {
+			t.Fatalf("bitset %s: UnmarshalText: %v", c.name, err)
+		}
+		for i := 0; i < 128; i++ {
+			if decoded.Get(i) != c.bs.Get(i) {
+				t.Errorf("bitset %s: decoded bit %d got %v expected %v",
+					c.name, i, decoded.Get(i), c.bs.Get(i))
+			}
+		}
+		for _, bad := range []string{"0", "zz", "01 02"} {
+			if err := decoded.UnmarshalText([]byte(bad)); err == nil {
+				t.Errorf("bitset %s: invalid text %q decoded without error",
+					c.name, bad)
+			}
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"iter"
+	"strconv"
+)
+
+// summaryIndexes is the number of set bit indexes included in the summary
+// printed by the %v verb before the rest are elided.
+const summaryIndexes = 8
+
+// The bitset types implement fmt.Formatter with the following verbs:
+//
+//	%b      the bit string, as returned by BitString
+//	%x, %X  the lower or upper case hexadecimal encoding of the binary
+//	        encoding, as returned by MarshalText
+//	%v, %s  a summary of the length in bits, the number of set bits, and
+//	        the indexes of the first few set bits
+//
+// The length of a Pointers or Bytes bitset is its full capacity, and that of
+// a Sparse bitset extends through its highest nonzero pointer.  The summary
+// of a Sparse bitset is computed without allocating its dense form, and if
+// it holds negative indexes, which are stored beyond the largest int, its
+// length is reported as the largest int and the %b and %x verbs report an
+// error.  Any other verb is reported as a bad verb, as for the builtin types.
+
+// Format implements the fmt.Formatter interface.
+func (p Pointers) Format(f fmt.State, verb rune) {
+	formatBitSet(f, verb, "Pointers", p, len(p)<<ptrShift, p.Count(), p.Ones(),
+		p.appendBytes)
+}
+
+// Format implements the fmt.Formatter interface.
+func (s Bytes) Format(f fmt.State, verb rune) {
+	formatBitSet(f, verb, "Bytes", s, len(s)<<byteShift, s.Count(), s.Ones(),
+		func(b []byte) []byte { return append(b, s...) })
+}
+
+// Format implements the fmt.Formatter interface.
+func (s Sparse) Format(f fmt.State, verb rune) {
+	numBits, ok := s.extent()
+	switch verb {
+	case 'b', 'x', 'X':
+		if !ok {
+			fmt.Fprintf(f, "%%!%c(Sparse=too large)", verb)
+			return
+		}
+		p := s.pointers()
+		formatBitSet(f, verb, "Sparse", p, numBits, 0, nil, p.appendBytes)
+	default:
+		formatBitSet(f, verb, "Sparse", s, numBits, s.Count(), s.Ones(), nil)
+	}
+}
+
+// Format implements the fmt.Formatter interface.  The bit string and
+// summary of a Dense bitset use its logical length.
+func (d *Dense) Format(f fmt.State, verb rune) {
+	p := d.p
+	formatBitSet(f, verb, "Dense", p, d.n, p.Count(), p.Ones(),
+		func(b []byte) []byte {
+			return p.appendBytes(b)[:len(b)+(d.n+byteModMask)>>byteShift]
+		})
+}
+
+// formatBitSet formats a bitset of numBits bits for the verb.  appendBinary
+// appends the binary encoding of the bitset to a slice.
+func formatBitSet(f fmt.State, verb rune, name string, bs BitSet, numBits, count int,
+	ones iter.Seq[int], appendBinary func([]byte) []byte) {
+
+	var b []byte
+	switch verb {
+	case 'b':
+		b = appendBitString(make([]byte, 0, numBits), bs, numBits)
+	case 'x', 'X':
+		b = hex.AppendEncode(nil, appendBinary(nil))
+		if verb == 'X' {
+			b = bytes.ToUpper(b)
+		}
+	case 'v', 's':
+		b = append(b, name...)
+		b = append(b, '(')
+		b = strconv.AppendInt(b, int64(numBits), 10)
+		b = append(b, " bits, "...)
+		b = strconv.AppendInt(b, int64(count), 10)
+		b = append(b, " set"...)
+		n := 0
+		for i := range ones {
+			if n == summaryIndexes {
+				b = append(b, " ..."...)
+				break
+			}
+			if n == 0 {
+				b = append(b, ':')
+			}
+			b = append(b, ' ')
+			b = strconv.AppendInt(b, int64(i), 10)
+			n++
+		}
+		b = append(b, ')')
+	default:
+		fmt.Fprintf(f, "%%!%c(%s)", verb, name)
+		return
+	}
+	f.Write(b)
+}
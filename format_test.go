@@ -0,0 +1,60 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset_test
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/jrick/bitset"
+)
+
+func TestFormat(t *testing.T) {
+	b := NewBytesFromIndices([]int{0, 3, 15})
+	d := NewDense(10)
+	d.Set(1)
+	d.Set(9)
+	many := NewSparseFromIndices(seq(0, 10))
+	// Neither of these could be formatted from a dense copy.
+	highBits := int(^uint(0)>>2) + 1
+	high := NewSparseFromIndices([]int{1, highBits - 1})
+	negative := NewSparseFromIndices([]int{1, -1})
+	tests := []struct {
+		format string
+		arg    any
+		exp    string
+	}{
+		{"%b", b, "1001000000000001"},
+		{"%x", b, "0980"},
+		{"%X", b, "0980"},
+		{"%v", b, "Bytes(16 bits, 3 set: 0 3 15)"},
+		{"%s", NewBytes(8), "Bytes(8 bits, 0 set)"},
+		{"%v", NewPointersFromIndices([]int{63}), fmt.Sprintf("Pointers(%d bits, 1 set: 63)",
+			NewPointersFromIndices([]int{63}).Cap())},
+		{"%x", NewSparseFromIndices([]int{8, 12}), fmt.Sprintf("%x",
+			NewPointersFromIndices([]int{8, 12}))},
+		{"%v", many, fmt.Sprintf("Sparse(%d bits, 10 set: 0 1 2 3 4 5 6 7 ...)",
+			NewPointers(1).Cap())},
+		{"%b", d, "0100000001"},
+		{"%x", d, "0202"},
+		{"%v", d, "Dense(10 bits, 2 set: 1 9)"},
+		{"%d", b, "%!d(Bytes)"},
+		{"%v", high, fmt.Sprintf("Sparse(%d bits, 2 set: 1 %d)", highBits, highBits-1)},
+		{"%v", negative, fmt.Sprintf("Sparse(%d bits, 2 set: 1 -1)", int(^uint(0)>>1))},
+		{"%x", negative, "%!x(Sparse=too large)"},
+	}
+	for testNum, test := range tests {
+		if got := fmt.Sprintf(test.format, test.arg); got != test.exp {
+			t.Errorf("Test %d: %s got %q expected %q", testNum, test.format,
+				got, test.exp)
+		}
+	}
+	if got, exp := fmt.Sprintf("%X", NewBytesFromIndices([]int{4})), "10"; got != exp {
+		t.Errorf("%%X: got %q expected %q", got, exp)
+	}
+	if got, exp := fmt.Sprintf("%X", Bytes{0xab}), "AB"; got != exp {
+		t.Errorf("%%X: got %q expected %q", got, exp)
+	}
+}
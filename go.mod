@@ -0,0 +1,3 @@
+module github.com/jrick/bitset
+
+go 1.24
@@ -0,0 +1,44 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+// GobEncode returns the binary encoding of p.  It implements the
+// gob.GobEncoder interface and never returns an error.
+func (p Pointers) GobEncode() ([]byte, error) {
+	return p.MarshalBinary()
+}
+
+// GobDecode replaces the contents of p with the bits of the binary encoding
+// data.  It implements the gob.GobDecoder interface and never returns an
+// error.
+func (p *Pointers) GobDecode(data []byte) error {
+	return p.UnmarshalBinary(data)
+}
+
+// GobEncode returns the binary encoding of s.  It implements the
+// gob.GobEncoder interface and never returns an error.
+func (s Bytes) GobEncode() ([]byte, error) {
+	return s.MarshalBinary()
+}
+
+// GobDecode replaces the contents of s with a copy of data.  It implements the
+// gob.GobDecoder interface and never returns an error.
+func (s *Bytes) GobDecode(data []byte) error {
+	return s.UnmarshalBinary(data)
+}
+
+// GobEncode returns the sparse binary encoding of s.  It implements the
+// gob.GobEncoder interface and never returns an error.
+func (s Sparse) GobEncode() ([]byte, error) {
+	return s.MarshalBinary()
+}
+
+// GobDecode replaces the contents of s with the bits of the sparse binary
+// encoding data, allocating the map if s is nil.  It implements the
+// gob.GobDecoder interface.  If the encoding is malformed, ErrInvalidSparse is
+// returned and s is not modified.
+func (s *Sparse) GobDecode(data []byte) error {
+	return s.UnmarshalBinary(data)
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"math/rand"
+	"testing"
+
+	. "github.com/jrick/bitset"
+)
+
+func TestGob(t *testing.T) {
+	set := []int{0, 9, 63, 64, 1000, 1 << 20}
+	type snapshot struct {
+		P Pointers
+		B Bytes
+		S Sparse
+	}
+	in := snapshot{
+		P: NewPointersFromIndices(set[:5]),
+		B: NewBytesFromIndices(set[:5]),
+		S: NewSparseFromIndices(set),
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(in); err != nil {
+		t.Fatal(err)
+	}
+	var out snapshot
+	if err := gob.NewDecoder(&buf).Decode(&out); err != nil {
+		t.Fatal(err)
+	}
+	results := []struct {
+		name     string
+		got, exp []int
+	}{
+		{"Pointers", out.P.ToSlice(), set[:5]},
+		{"Bytes", out.B.ToSlice(), set[:5]},
+		{"Sparse", out.S.ToSlice(), set},
+	}
+	for _, r := range results {
+		if !equalIndices(r.got, r.exp) {
+			t.Errorf("%s: got %v expected %v", r.name, r.got, r.exp)
+		}
+	}
+}
+
+func TestSparseGobDeterministic(t *testing.T) {
+	var exp []byte
+	for n := 0; n < 20; n++ {
+		// Build equal sets in different insertion orders, leaving a
+		// zero pointer behind in some of them.
+		s := make(Sparse)
+		for _, i := range rand.New(rand.NewSource(int64(n))).Perm(2000) {
+			if i%7 == 0 {
+				s.Set(i)
+			}
+		}
+		for k := 0; k < n; k++ {
+			s[1000+k] = 0
+		}
+		enc, err := s.GobEncode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exp == nil {
+			exp = enc
+		} else if !bytes.Equal(enc, exp) {
+			t.Fatalf("encoding %d differs: %x != %x", n, enc, exp)
+		}
+
+		var decoded Sparse
+		if err := decoded.GobDecode(enc); err != nil {
+			t.Fatal(err)
+		}
+		if !decoded.Equal(s) {
+			t.Fatalf("decoded set %d not equal to original", n)
+		}
+	}
+}
+
+func TestSparseGobInvalid(t *testing.T) {
+	tests := [][]byte{
+		nil,
+		{1},
+		{1, 0, 1, 0, 0, 0, 0, 0, 0},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+		{2, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+		{0xff, 0xff, 0xff, 0xff, 0x0f},
+		{1, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f, 1, 0, 0, 0, 0, 0, 0, 0},
+	}
+	for testNum, data := range tests {
+		s := NewSparseFromIndices([]int{5})
+		if err := s.GobDecode(data); err != ErrInvalidSparse {
+			t.Errorf("Test %d: got error %v expected %v", testNum, err, ErrInvalidSparse)
+		}
+		if got := s.ToSlice(); !equalIndices(got, []int{5}) {
+			t.Errorf("Test %d: set modified by failed decode", testNum)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+import (
+	"bufio"
+	"io"
+	"iter"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ReadIndexList reads a plain text list of bit indexes from r, returning them
+// in the order read.  Indexes are decimal integers separated by any
+// combination of commas and whitespace, including newlines, so both one
+// index per line and comma separated lists are accepted.  The result may be
+// passed to any of the New*FromIndices functions.  Negative indexes result
+// in ErrIndexOutOfRange, and text which is not an integer results in a
+// *strconv.NumError describing it.
+func ReadIndexList(r io.Reader) ([]int, error) {
+	sc := bufio.NewScanner(r)
+	sc.Split(scanIndexes)
+	var indices []int
+	for sc.Scan() {
+		i, err := strconv.Atoi(sc.Text())
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 {
+			return nil, ErrIndexOutOfRange
+		}
+		indices = append(indices, i)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return indices, nil
+}
+
+// WriteIndexList writes every index yielded by ones to w in decimal, one per
+// line.  ones is typically the Ones method of a bitset.
+func WriteIndexList(w io.Writer, ones iter.Seq[int]) error {
+	bw := bufio.NewWriter(w)
+	var buf []byte
+	for i := range ones {
+		buf = strconv.AppendInt(buf[:0], int64(i), 10)
+		buf = append(buf, '\n')
+		if _, err := bw.Write(buf); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// scanIndexes is a bufio.SplitFunc which splits text into tokens separated by
+// commas and whitespace.
+func scanIndexes(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	isSep := func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	}
+	start := 0
+	for start < len(data) {
+		r, width := utf8.DecodeRune(data[start:])
+		if !isSep(r) {
+			break
+		}
+		start += width
+	}
+	for i := start; i < len(data); {
+		r, width := utf8.DecodeRune(data[i:])
+		if isSep(r) {
+			return i + width, data[start:i], nil
+		}
+		i += width
+	}
+	if atEOF && len(data) > start {
+		return len(data), data[start:], nil
+	}
+	return start, nil, nil
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset_test
+
+import (
+	"bytes"
+	"errors"
+	"iter"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	. "github.com/jrick/bitset"
+)
+
+func TestReadIndexList(t *testing.T) {
+	tests := []struct {
+		text string
+		exp  []int
+	}{
+		{text: "", exp: nil},
+		{text: "\n\n", exp: nil},
+		{text: "5", exp: []int{5}},
+		{text: "1\n2\n300\n", exp: []int{1, 2, 300}},
+		{text: "1,2, 300", exp: []int{1, 2, 300}},
+		{text: "\t7 ,, 3\r\n9,\n", exp: []int{7, 3, 9}},
+	}
+	for testNum, test := range tests {
+		got, err := ReadIndexList(strings.NewReader(test.text))
+		if err != nil {
+			t.Errorf("Test %d: unexpected error %v", testNum, err)
+			continue
+		}
+		if !slices.Equal(got, test.exp) {
+			t.Errorf("Test %d: got %v expected %v", testNum, got, test.exp)
+		}
+	}
+
+	if _, err := ReadIndexList(strings.NewReader("1,-2")); err != ErrIndexOutOfRange {
+		t.Errorf("negative: got error %v expected %v", err, ErrIndexOutOfRange)
+	}
+	_, err := ReadIndexList(strings.NewReader("1\n2x\n"))
+	var numErr *strconv.NumError
+	if !errors.As(err, &numErr) || numErr.Num != "2x" {
+		t.Errorf("invalid: got error %v", err)
+	}
+}
+
+func TestWriteIndexList(t *testing.T) {
+	set := []int{0, 9, 64, 1000}
+	const exp = "0\n9\n64\n1000\n"
+	for _, c := range []struct {
+		name string
+		ones iter.Seq[int]
+	}{
+		{"Pointers", NewPointersFromIndices(set).Ones()},
+		{"Bytes", NewBytesFromIndices(set).Ones()},
+		{"Sparse", NewSparseFromIndices(set).Ones()},
+	} {
+		var buf bytes.Buffer
+		if err := WriteIndexList(&buf, c.ones); err != nil {
+			t.Fatalf("bitset %s: %v", c.name, err)
+		}
+		if buf.String() != exp {
+			t.Errorf("bitset %s: got %q expected %q", c.name, buf.String(), exp)
+		}
+		got, err := ReadIndexList(&buf)
+		if err != nil || !slices.Equal(got, set) {
+			t.Errorf("bitset %s: round trip got %v, %v", c.name, got, err)
+		}
+	}
+}
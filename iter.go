@@ -0,0 +1,248 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+import (
+	"iter"
+	"math/bits"
+	"sort"
+)
+
+// Ones returns an iterator over the indexes of all set bits in increasing
+// order.  Bits modified during iteration may or may not be observed.
+func (p Pointers) Ones() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for w, ptr := range p {
+			for ptr != 0 {
+				if !yield(w<<ptrShift + bits.TrailingZeros(uint(ptr))) {
+					return
+				}
+				ptr &= ptr - 1
+			}
+		}
+	}
+}
+
+// Ones returns an iterator over the indexes of all set bits in increasing
+// order.  Bits modified during iteration may or may not be observed.
+func (s Bytes) Ones() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for i, b := range s {
+			for b != 0 {
+				if !yield(i<<byteShift + bits.TrailingZeros8(b)) {
+					return
+				}
+				b &= b - 1
+			}
+		}
+	}
+}
+
+// Ones returns an iterator over the indexes of all set bits.  Unlike ranging
+// over the map directly, indexes are guaranteed to be yielded in increasing
+// order.  To provide this ordering, the keys of the map are collected and
+// sorted each time iteration begins.  Pointers added to the map during
+// iteration are not observed.
+func (s Sparse) Ones() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for _, k := range s.sortedKeys() {
+			ptr := s[k]
+			for ptr != 0 {
+				if !yield(k<<ptrShift + bits.TrailingZeros(uint(ptr))) {
+					return
+				}
+				ptr &= ptr - 1
+			}
+		}
+	}
+}
+
+// sortedKeys returns the keys of all pointers in s in increasing order.
+func (s Sparse) sortedKeys() []int {
+	keys := make([]int, 0, len(s))
+	for k := range s {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+// ForEach calls fn with the index of each set bit in increasing order,
+// stopping early if fn returns false.
+func (p Pointers) ForEach(fn func(i int) bool) {
+	p.Ones()(fn)
+}
+
+// ForEach calls fn with the index of each set bit in increasing order,
+// stopping early if fn returns false.
+func (s Bytes) ForEach(fn func(i int) bool) {
+	s.Ones()(fn)
+}
+
+// ForEach calls fn with the index of each set bit in increasing order,
+// stopping early if fn returns false.
+func (s Sparse) ForEach(fn func(i int) bool) {
+	s.Ones()(fn)
+}
+
+// Backward returns an iterator over the indexes of all set bits in
+// decreasing order.  Bits modified during iteration may or may not be
+// observed.
+func (p Pointers) Backward() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for w := len(p) - 1; w >= 0; w-- {
+			for ptr := p[w]; ptr != 0; {
+				hi := ptrModMask - bits.LeadingZeros(uint(ptr))
+				if !yield(w<<ptrShift + hi) {
+					return
+				}
+				ptr &^= 1 << uint(hi)
+			}
+		}
+	}
+}
+
+// Backward returns an iterator over the indexes of all set bits in
+// decreasing order.  Bits modified during iteration may or may not be
+// observed.
+func (s Bytes) Backward() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for i := len(s) - 1; i >= 0; i-- {
+			for b := s[i]; b != 0; {
+				hi := byteModMask - bits.LeadingZeros8(b)
+				if !yield(i<<byteShift + hi) {
+					return
+				}
+				b &^= 1 << uint(hi)
+			}
+		}
+	}
+}
+
+// Backward returns an iterator over the indexes of all set bits in
+// decreasing order.  As with Ones, the keys of the map are collected and
+// sorted each time iteration begins.
+func (s Sparse) Backward() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		keys := s.sortedKeys()
+		for j := len(keys) - 1; j >= 0; j-- {
+			k := keys[j]
+			for ptr := s[k]; ptr != 0; {
+				hi := ptrModMask - bits.LeadingZeros(uint(ptr))
+				if !yield(k<<ptrShift + hi) {
+					return
+				}
+				ptr &^= 1 << uint(hi)
+			}
+		}
+	}
+}
+
+// Runs returns an iterator over the maximal runs of consecutive set bits in
+// increasing order, yielding the index of the first bit of each run and the
+// number of bits in the run.  Whole pointers are skipped when searching for
+// the start and end of each run.
+func (p Pointers) Runs() iter.Seq2[int, int] {
+	return func(yield func(int, int) bool) {
+		for i := 0; ; {
+			start := p.NextSet(i)
+			if start == -1 {
+				return
+			}
+			end := p.NextClear(start)
+			if end == -1 {
+				end = len(p) << ptrShift
+			}
+			if !yield(start, end-start) {
+				return
+			}
+			i = end
+		}
+	}
+}
+
+// Runs returns an iterator over the maximal runs of consecutive set bits in
+// increasing order, yielding the index of the first bit of each run and the
+// number of bits in the run.  Whole bytes are skipped when searching for the
+// start and end of each run.
+func (s Bytes) Runs() iter.Seq2[int, int] {
+	return func(yield func(int, int) bool) {
+		for i := 0; ; {
+			start := s.NextSet(i)
+			if start == -1 {
+				return
+			}
+			end := s.NextClear(start)
+			if end == -1 {
+				end = len(s) << byteShift
+			}
+			if !yield(start, end-start) {
+				return
+			}
+			i = end
+		}
+	}
+}
+
+// Runs returns an iterator over the maximal runs of consecutive set bits in
+// increasing order, yielding the index of the first bit of each run and the
+// number of bits in the run.
+func (s Sparse) Runs() iter.Seq2[int, int] {
+	return func(yield func(int, int) bool) {
+		for i := 0; ; {
+			start := s.NextSet(i)
+			if start == -1 {
+				return
+			}
+			end := s.NextClear(start)
+			if !yield(start, end-start) {
+				return
+			}
+			i = end
+		}
+	}
+}
+
+// AppendTo appends the indexes of all set bits to dst in increasing order and
+// returns the extended slice.
+func (p Pointers) AppendTo(dst []int) []int {
+	for i := range p.Ones() {
+		dst = append(dst, i)
+	}
+	return dst
+}
+
+// ToSlice returns the indexes of all set bits in increasing order.
+func (p Pointers) ToSlice() []int {
+	return p.AppendTo(make([]int, 0, p.Count()))
+}
+
+// AppendTo appends the indexes of all set bits to dst in increasing order and
+// returns the extended slice.
+func (s Bytes) AppendTo(dst []int) []int {
+	for i := range s.Ones() {
+		dst = append(dst, i)
+	}
+	return dst
+}
+
+// ToSlice returns the indexes of all set bits in increasing order.
+func (s Bytes) ToSlice() []int {
+	return s.AppendTo(make([]int, 0, s.Count()))
+}
+
+// AppendTo appends the indexes of all set bits to dst in increasing order and
+// returns the extended slice.
+func (s Sparse) AppendTo(dst []int) []int {
+	for i := range s.Ones() {
+		dst = append(dst, i)
+	}
+	return dst
+}
+
+// ToSlice returns the indexes of all set bits in increasing order.
+func (s Sparse) ToSlice() []int {
+	return s.AppendTo(make([]int, 0, s.Count()))
+}
@@ -0,0 +1,212 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset_test
+
+import (
+	"iter"
+	"reflect"
+	"testing"
+	"unsafe"
+
+	. "github.com/jrick/bitset"
+)
+
+type iterable interface {
+	BitSet
+	Ones() iter.Seq[int]
+}
+
+func iterables(numBits int) []struct {
+	name string
+	bs   iterable
+} {
+	return []struct {
+		name string
+		bs   iterable
+	}{
+		{"Pointers", NewPointers(numBits)},
+		{"Bytes", NewBytes(numBits)},
+		{"Sparse", make(Sparse)},
+	}
+}
+
+var iterSets = [][]int{
+	nil,
+	{0},
+	{0, 1, 2, 3},
+	{7, 8, 63, 64, 65, 128, 511},
+	seq(100, 300),
+}
+
+func TestOnes(t *testing.T) {
+	for setNum, set := range iterSets {
+		for _, c := range iterables(512) {
+			for _, i := range set {
+				c.bs.Set(i)
+			}
+			var got []int
+			for i := range c.bs.Ones() {
+				got = append(got, i)
+			}
+			if !reflect.DeepEqual(got, set) {
+				t.Errorf("Set %d bitset %s: got %v expected %v",
+					setNum, c.name, got, set)
+			}
+
+			// Stopping early must not yield further indexes.
+			n := 0
+			for range c.bs.Ones() {
+				n++
+				if n == 2 {
+					break
+				}
+			}
+			if exp := min(len(set), 2); n != exp {
+				t.Errorf("Set %d bitset %s: early break yielded %d indexes, expected %d",
+					setNum, c.name, n, exp)
+			}
+		}
+	}
+}
+
+func TestForEach(t *testing.T) {
+	for setNum, set := range iterSets {
+		for _, c := range iterables(512) {
+			bs := c.bs.(interface {
+				iterable
+				ForEach(fn func(i int) bool)
+			})
+			for _, i := range set {
+				bs.Set(i)
+			}
+			var got []int
+			bs.ForEach(func(i int) bool {
+				got = append(got, i)
+				return len(got) < 3
+			})
+			exp := set[:min(len(set), 3)]
+			if len(got) == 0 && len(exp) == 0 {
+				continue
+			}
+			if !reflect.DeepEqual(got, exp) {
+				t.Errorf("Set %d bitset %s: got %v expected %v",
+					setNum, c.name, got, exp)
+			}
+		}
+	}
+}
+
+func TestBackward(t *testing.T) {
+	for setNum, set := range iterSets {
+		for _, c := range iterables(512) {
+			bs := c.bs.(interface {
+				iterable
+				Backward() iter.Seq[int]
+			})
+			for _, i := range set {
+				bs.Set(i)
+			}
+			var exp []int
+			for j := len(set) - 1; j >= 0; j-- {
+				exp = append(exp, set[j])
+			}
+			var got []int
+			for i := range bs.Backward() {
+				got = append(got, i)
+			}
+			if !reflect.DeepEqual(got, exp) {
+				t.Errorf("Set %d bitset %s: got %v expected %v",
+					setNum, c.name, got, exp)
+			}
+		}
+	}
+}
+
+func TestRuns(t *testing.T) {
+	tests := []struct {
+		set  []int
+		runs [][2]int
+	}{
+		{set: nil, runs: nil},
+		{set: []int{0}, runs: [][2]int{{0, 1}}},
+		{set: []int{0, 1, 2, 5, 511}, runs: [][2]int{{0, 3}, {5, 1}, {511, 1}}},
+		{set: seq(60, 200), runs: [][2]int{{60, 140}}},
+		{set: seq(0, 512), runs: [][2]int{{0, 512}}},
+		{set: append(seq(0, 64), seq(65, 130)...), runs: [][2]int{{0, 64}, {65, 65}}},
+	}
+	for testNum, test := range tests {
+		for _, c := range iterables(512) {
+			bs := c.bs.(interface {
+				iterable
+				Runs() iter.Seq2[int, int]
+			})
+			for _, i := range test.set {
+				bs.Set(i)
+			}
+			var got [][2]int
+			for start, n := range bs.Runs() {
+				got = append(got, [2]int{start, n})
+			}
+			if !reflect.DeepEqual(got, test.runs) {
+				t.Errorf("Test %d bitset %s: got %v expected %v",
+					testNum, c.name, got, test.runs)
+			}
+		}
+	}
+}
+
+func TestToSlice(t *testing.T) {
+	type slicer interface {
+		iterable
+		ToSlice() []int
+		AppendTo(dst []int) []int
+	}
+	for setNum, set := range iterSets {
+		for _, c := range iterables(512) {
+			bs := c.bs.(slicer)
+			for _, i := range set {
+				bs.Set(i)
+			}
+			got := bs.ToSlice()
+			if len(got) != len(set) || (len(set) != 0 && !reflect.DeepEqual(got, set)) {
+				t.Errorf("Set %d bitset %s: ToSlice got %v expected %v",
+					setNum, c.name, got, set)
+			}
+			dst := []int{-1}
+			got = bs.AppendTo(dst)
+			if exp := append([]int{-1}, set...); !reflect.DeepEqual(got, exp) {
+				t.Errorf("Set %d bitset %s: AppendTo got %v expected %v",
+					setNum, c.name, got, exp)
+			}
+		}
+	}
+}
+
+func TestFromIndicesRoundTrip(t *testing.T) {
+	for setNum, set := range iterSets {
+		for _, c := range []struct {
+			name string
+			bs   interface{ ToSlice() []int }
+		}{
+			{"Pointers", NewPointersFromIndices(set)},
+			{"Bytes", NewBytesFromIndices(set)},
+			{"Sparse", NewSparseFromIndices(set)},
+		} {
+			got := c.bs.ToSlice()
+			if len(got) != len(set) || (len(set) != 0 && !reflect.DeepEqual(got, set)) {
+				t.Errorf("Set %d bitset %s: got %v expected %v",
+					setNum, c.name, got, set)
+			}
+		}
+	}
+
+	p := NewPointersFromIndices([]int{64})
+	if len(p) != 65/(8*int(unsafe.Sizeof(uintptr(0))))+1 {
+		t.Errorf("Pointers sized to %d pointers for max index 64", len(p))
+	}
+	if b := NewBytesFromIndices([]int{3, 15}); len(b) != 2 {
+		t.Errorf("Bytes sized to %d bytes for max index 15", len(b))
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+// A java.util.BitSet holds bit n in bit n%64 of the long at index n/64, and
+// its toByteArray method writes these longs in little endian order, trimmed
+// after the final byte with a set bit.  This is the layout of a Bytes bitset,
+// so conversions only copy and trim.  BitSet.valueOf accepts trailing zero
+// bytes and longs, so the untrimmed encoding of any bitset may also be
+// passed to it.
+
+// FromJavaBytes returns a Bytes bitset with the bits of a java.util.BitSet
+// encoded by its toByteArray method.  The result does not share memory with
+// b.
+func FromJavaBytes(b []byte) Bytes {
+	return append(Bytes(nil), b...)
+}
+
+// ToJavaBytes returns the encoding of s produced by the toByteArray method of
+// a java.util.BitSet with the same bits set, which may be decoded in Java by
+// BitSet.valueOf(byte[]).  The result is never nil.
+func ToJavaBytes(s Bytes) []byte {
+	n := len(s)
+	for n > 0 && s[n-1] == 0 {
+		n--
+	}
+	return append(make([]byte, 0, n), s[:n]...)
+}
+
+// FromJavaLongs returns a Words64 bitset with the bits of a java.util.BitSet
+// encoded by its toLongArray method.
+func FromJavaLongs(longs []int64) Words64 {
+	w := make(Words64, len(longs))
+	for i, l := range longs {
+		w[i] = uint64(l)
+	}
+	return w
+}
+
+// ToJavaLongs returns the encoding of w produced by the toLongArray method of
+// a java.util.BitSet with the same bits set, which may be decoded in Java by
+// BitSet.valueOf(long[]).  The result is never nil.
+func ToJavaLongs(w Words64) []int64 {
+	n := len(w)
+	for n > 0 && w[n-1] == 0 {
+		n--
+	}
+	longs := make([]int64, n)
+	for i, word := range w[:n] {
+		longs[i] = int64(word)
+	}
+	return longs
+}
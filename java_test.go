@@ -0,0 +1,63 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset_test
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+
+	. "github.com/jrick/bitset"
+)
+
+// The expected encodings of these tests follow the documented behavior of
+// java.util.BitSet.toByteArray and toLongArray.
+
+func TestJava(t *testing.T) {
+	tests := []struct {
+		set   []int
+		bytes []byte
+		longs []int64
+	}{
+		{set: nil, bytes: []byte{}, longs: []int64{}},
+		{set: []int{0}, bytes: []byte{0x01}, longs: []int64{1}},
+		{set: []int{1, 8, 15}, bytes: []byte{0x02, 0x81}, longs: []int64{0x8102}},
+		{set: []int{63}, bytes: []byte{0, 0, 0, 0, 0, 0, 0, 0x80},
+			longs: []int64{-1 << 63}},
+		{set: []int{0, 64}, bytes: []byte{1, 0, 0, 0, 0, 0, 0, 0, 1},
+			longs: []int64{1, 1}},
+	}
+	for testNum, test := range tests {
+		// Convert from bitsets with trailing zero bytes and words,
+		// which Java never produces.
+		s := NewBytesFromIndices(test.set)
+		s.Grow(len(s)*8 + 128)
+		if got := ToJavaBytes(s); !bytes.Equal(got, test.bytes) || got == nil {
+			t.Errorf("Test %d: ToJavaBytes got %x expected %x", testNum,
+				got, test.bytes)
+		}
+		w := NewWords64FromBytes(s)
+		if got := ToJavaLongs(w); !slices.Equal(got, test.longs) || got == nil {
+			t.Errorf("Test %d: ToJavaLongs got %x expected %x", testNum,
+				got, test.longs)
+		}
+
+		if got := slices.Collect(FromJavaBytes(test.bytes).Ones()); !slices.Equal(got, test.set) {
+			t.Errorf("Test %d: FromJavaBytes got %v expected %v", testNum,
+				got, test.set)
+		}
+		if got := slices.Collect(FromJavaLongs(test.longs).Ones()); !slices.Equal(got, test.set) {
+			t.Errorf("Test %d: FromJavaLongs got %v expected %v", testNum,
+				got, test.set)
+		}
+	}
+
+	b := []byte{0xff}
+	s := FromJavaBytes(b)
+	s.Unset(0)
+	if b[0] != 0xff {
+		t.Errorf("FromJavaBytes shares memory with its argument")
+	}
+}
@@ -0,0 +1,251 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+import (
+	"bytes"
+	"encoding/json"
+	"iter"
+	"strconv"
+)
+
+// Bitsets are represented in JSON in one of two forms:
+//
+//   - A base64 string (standard encoding, with padding) of the bitset's
+//     bytes, using the layout of a Bytes bitset.  This is compact for dense
+//     sets and is the form produced by Pointers and Bytes.
+//   - An array of the indexes of all set bits, in increasing order.  This is
+//     readable and compact for sparse sets, and is the form produced by
+//     Sparse and by any bitset wrapped in JSONIndexes.
+//
+// The UnmarshalJSON methods accept either form regardless of the form
+// produced when marshaling.  A JSON null leaves the bitset unmodified.
+//
+// Unlike the base64 form, the memory needed to decode an array of indexes
+// into a Pointers or Bytes bitset is not proportional to the length of the
+// encoding, as a single large index requires a bitset large enough to hold
+// it.  Unless a larger limit is given by Limited, indexes decoded into these
+// types must be less than 2^28, and larger indexes result in ErrTooLarge.
+// Indexes are never negative, so Sparse bitsets with a bit set at a negative
+// index cannot be marshaled as an array of indexes.
+
+// MarshalJSON encodes s as a base64 JSON string.  It implements the
+// json.Marshaler interface.
+func (s Bytes) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]byte(s))
+}
+
+// UnmarshalJSON replaces the contents of s with the bits encoded by a base64
+// JSON string or an array of indexes.  It implements the json.Unmarshaler
+// interface.
+func (s *Bytes) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, maxInt, s)
+}
+
+// MarshalJSON encodes p as a base64 JSON string of its binary encoding.  It
+// implements the json.Marshaler interface.
+func (p Pointers) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.appendBytes(nil))
+}
+
+// UnmarshalJSON replaces the contents of p with the bits encoded by a base64
+// JSON string or an array of indexes.  It implements the json.Unmarshaler
+// interface.
+func (p *Pointers) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, maxInt, p)
+}
+
+// MarshalJSON encodes s as a JSON array of the indexes of its set bits.  If
+// any bit is set at a negative index, ErrIndexOutOfRange is returned.  It
+// implements the json.Marshaler interface.
+func (s Sparse) MarshalJSON() ([]byte, error) {
+	return marshalIndexes(s.Ones(), maxInt)
+}
+
+// UnmarshalJSON replaces the contents of s with the bits encoded by a base64
+// JSON string or an array of indexes, allocating the map if s is nil.  It
+// implements the json.Unmarshaler interface.
+func (s *Sparse) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, maxInt, s)
+}
+
+// JSONIndexes wraps a bitset so that it is marshaled to JSON as an array of
+// the indexes of its set bits rather than in its default form.  The wrapped
+// bitset must be a Pointers, Bytes, or Sparse bitset, or a pointer to one of
+// these types.  Unmarshaling requires a pointer for Pointers and Bytes, and
+// either a pointer or a non-nil map for Sparse.  Any other type causes
+// ErrUnsupportedType to be returned.
+type JSONIndexes struct {
+	BitSet BitSet
+}
+
+// MarshalJSON encodes the wrapped bitset as a JSON array of the indexes of
+// its set bits.  Indexes which the wrapped type would not accept when
+// unmarshaling cause an error to be returned, so that the encoding always
+// round-trips: ErrTooLarge for indexes of Pointers and Bytes bitsets at or
+// beyond 2^28, and ErrIndexOutOfRange for negative indexes of Sparse bitsets.
+// It implements the json.Marshaler interface.
+func (j JSONIndexes) MarshalJSON() ([]byte, error) {
+	var ones iter.Seq[int]
+	switch bs := j.BitSet.(type) {
+	case Pointers:
+		ones = bs.Ones()
+	case *Pointers:
+		ones = bs.Ones()
+	case Bytes:
+		ones = bs.Ones()
+	case *Bytes:
+		ones = bs.Ones()
+	case Sparse:
+		ones = bs.Ones()
+	case *Sparse:
+		ones = bs.Ones()
+	default:
+		return nil, ErrUnsupportedType
+	}
+	return marshalIndexes(ones, indexLimit(j.BitSet, maxInt))
+}
+
+// UnmarshalJSON decodes a base64 JSON string or an array of indexes into the
+// wrapped bitset.  It implements the json.Unmarshaler interface.
+func (j JSONIndexes) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, maxInt, j.BitSet)
+}
+
+// marshalIndexes returns a JSON array of every index yielded by ones.
+// Negative indexes result in ErrIndexOutOfRange, and indexes not less than
+// maxBits result in ErrTooLarge.
+func marshalIndexes(ones iter.Seq[int], maxBits int) ([]byte, error) {
+	b := []byte{'['}
+	for i := range ones {
+		if i < 0 {
+			return nil, ErrIndexOutOfRange
+		}
+		if i >= maxBits {
+			return nil, ErrTooLarge
+		}
+		if len(b) != 1 {
+			b = append(b, ',')
+		}
+		b = strconv.AppendInt(b, int64(i), 10)
+	}
+	return append(b, ']'), nil
+}
+
+// unmarshalJSON decodes data as either a base64 JSON string or an array of
+// indexes, replacing the contents of bs.  A JSON null leaves bs unmodified.
+// Negative indexes result in ErrIndexOutOfRange, and encodings of more than
+// maxBits bits result in ErrTooLarge.
+func unmarshalJSON(data []byte, maxBits int, bs BitSet) error {
+	fromBytes, fromIndexes, ok := replacers(bs)
+	if !ok {
+		return ErrUnsupportedType
+	}
+	data = bytes.TrimSpace(data)
+	switch {
+	case bytes.Equal(data, []byte("null")):
+		return nil
+	case len(data) != 0 && data[0] == '[':
+		var indices []int
+		if err := json.Unmarshal(data, &indices); err != nil {
+			return err
+		}
+		if err := checkIndexes(indices, indexLimit(bs, maxBits)); err != nil {
+			return err
+		}
+		fromIndexes(indices)
+		return nil
+	default:
+		// The decoded bytes are no larger than data, so they may be
+		// checked against the limit after decoding.
+		var b []byte
+		if err := json.Unmarshal(data, &b); err != nil {
+			return err
+		}
+		if len(b) > maxBits>>byteShift {
+			return ErrTooLarge
+		}
+		fromBytes(b)
+		return nil
+	}
+}
+
+// checkIndexes returns ErrIndexOutOfRange if any decoded index is negative,
+// or ErrTooLarge if any is not less than maxBits.
+func checkIndexes(indices []int, maxBits int) error {
+	for _, i := range indices {
+		if i < 0 {
+			return ErrIndexOutOfRange
+		}
+		if i >= maxBits {
+			return ErrTooLarge
+		}
+	}
+	return nil
+}
+
+// defaultIndexLimit is the limit on the indexes decoded into Pointers and
+// Bytes bitsets when decoding is not otherwise limited.
+const defaultIndexLimit = 1 << 28
+
+// indexLimit returns the limit on indexes decoded into bs when decoding is
+// limited to maxBits bits.  Without a limit, indexes decoded into the dense
+// types are limited to defaultIndexLimit, so that a single hostile index
+// cannot require an allocation of up to 2^60 bytes.  Dense bitsets passed by
+// value share the limit of their pointer types.
+func indexLimit(bs BitSet, maxBits int) int {
+	switch bs.(type) {
+	case Pointers, *Pointers, Bytes, *Bytes:
+		if maxBits == maxInt {
+			return defaultIndexLimit
+		}
+	}
+	return maxBits
+}
+
+// replacers returns functions which replace the contents of bs with the bits
+// of a decoded byte layout or list of indexes.  bs must be a pointer to a
+// Pointers, Bytes, or Sparse bitset, or a non-nil Sparse; otherwise ok is
+// false.  A nil Sparse is allocated through a pointer.
+func replacers(bs BitSet) (fromBytes func([]byte), fromIndexes func([]int), ok bool) {
+	switch bs := bs.(type) {
+	case *Bytes:
+		return func(b []byte) {
+				*bs = append(Bytes(nil), b...)
+			}, func(indices []int) {
+				*bs = NewBytesFromIndices(indices)
+			}, true
+	case *Pointers:
+		return bs.setBytes, func(indices []int) {
+			*bs = NewPointersFromIndices(indices)
+		}, true
+	case Sparse:
+		if bs == nil {
+			return nil, nil, false
+		}
+		return replacers(&bs)
+	case *Sparse:
+		reset := func() Sparse {
+			if *bs == nil {
+				*bs = make(Sparse)
+			}
+			clear(*bs)
+			return *bs
+		}
+		return func(b []byte) {
+				m := reset()
+				for i := range Bytes(b).Ones() {
+					m.Set(i)
+				}
+			}, func(indices []int) {
+				m := reset()
+				for _, i := range indices {
+					m.Set(i)
+				}
+			}, true
+	default:
+		return nil, nil, false
+	}
+}
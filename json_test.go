@@ -0,0 +1,152 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset_test
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	. "github.com/jrick/bitset"
+)
+
+func TestJSON(t *testing.T) {
+	set := []int{0, 9, 63, 64}
+	type doc struct {
+		B Bytes
+		P Pointers
+		S Sparse
+		I JSONIndexes
+	}
+	in := doc{
+		B: NewBytesFromIndices(set),
+		P: NewPointersFromIndices(set),
+		S: NewSparseFromIndices(set),
+		I: JSONIndexes{NewBytesFromIndices(set)},
+	}
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var generic map[string]any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := generic["B"], "AQIAAAAAAIAB"; got != exp {
+		t.Errorf("Bytes: got %v expected %v", got, exp)
+	}
+	if _, ok := generic["P"].(string); !ok {
+		t.Errorf("Pointers: got %v expected a base64 string", generic["P"])
+	}
+	for _, key := range []string{"S", "I"} {
+		if got, _ := json.Marshal(generic[key]); string(got) != "[0,9,63,64]" {
+			t.Errorf("%s: got %s expected [0,9,63,64]", key, got)
+		}
+	}
+
+	var b Bytes
+	var p Pointers
+	out := doc{I: JSONIndexes{&b}}
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	results := []struct {
+		name string
+		got  []int
+	}{
+		{"Bytes", out.B.ToSlice()},
+		{"Pointers", out.P.ToSlice()},
+		{"Sparse", out.S.ToSlice()},
+		{"JSONIndexes", b.ToSlice()},
+	}
+	for _, r := range results {
+		if !equalIndices(r.got, set) {
+			t.Errorf("%s: got %v expected %v", r.name, r.got, set)
+		}
+	}
+
+	// Either form is accepted when unmarshaling.
+	if err := json.Unmarshal([]byte(`[1, 100]`), &p); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := p.ToSlice(), []int{1, 100}; !equalIndices(got, exp) {
+		t.Errorf("Pointers from indexes: got %v expected %v", got, exp)
+	}
+	if err := json.Unmarshal([]byte(`"AQIAAAAAAIAB"`), &out.S); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.S.ToSlice(); !equalIndices(got, set) {
+		t.Errorf("Sparse from base64: got %v expected %v", got, set)
+	}
+	if err := json.Unmarshal([]byte(`null`), &p); err != nil || p.Count() != 2 {
+		t.Errorf("null modified bitset or failed: %v", err)
+	}
+	if err := json.Unmarshal([]byte(`[3, -1]`), &p); err != ErrIndexOutOfRange {
+		t.Errorf("negative index: got error %v expected %v", err, ErrIndexOutOfRange)
+	}
+	if _, err := json.Marshal(JSONIndexes{new(Bits64)}); err == nil {
+		t.Errorf("unsupported type marshaled without error")
+	}
+}
+
+func TestJSONHugeIndex(t *testing.T) {
+	huge := int(^uint(0)>>1) - 1
+	data := []byte("[" + strconv.Itoa(huge) + "]")
+	var b Bytes
+	if err := json.Unmarshal(data, &b); err != ErrTooLarge {
+		t.Errorf("Bytes: got error %v expected %v", err, ErrTooLarge)
+	}
+	var p Pointers
+	if err := json.Unmarshal([]byte("[268435456]"), &p); err != ErrTooLarge {
+		t.Errorf("Pointers: got error %v expected %v", err, ErrTooLarge)
+	}
+	if err := json.Unmarshal([]byte("[268435455]"), &p); err != nil || !p.Get(268435455) {
+		t.Errorf("Pointers: unexpected error %v", err)
+	}
+
+	// Sparse bitsets need no dense allocation.
+	var s Sparse
+	if err := json.Unmarshal(data, &s); err != nil || !s.Get(huge) {
+		t.Errorf("Sparse: unexpected error %v", err)
+	}
+
+	// An explicit limit replaces the default.
+	if err := (Limited{&b, 1 << 29}).UnmarshalJSON([]byte("[268435456]")); err != nil {
+		t.Errorf("Limited: unexpected error %v", err)
+	}
+}
+
+func TestJSONUnencodableIndex(t *testing.T) {
+	// Indexes which would be rejected when unmarshaling into the same type
+	// are rejected when marshaling.
+	p := NewPointers(1<<28 + 1)
+	p.Set(1 << 28)
+	if _, err := (JSONIndexes{p}).MarshalJSON(); err != ErrTooLarge {
+		t.Errorf("Pointers: got error %v expected %v", err, ErrTooLarge)
+	}
+	p.Unset(1 << 28)
+	p.Set(1<<28 - 1)
+	data, err := JSONIndexes{&p}.MarshalJSON()
+	if err != nil {
+		t.Fatalf("Pointers: unexpected error %v", err)
+	}
+	var p2 Pointers
+	if err := (JSONIndexes{&p2}).UnmarshalJSON(data); err != nil || !p2.Get(1<<28-1) {
+		t.Errorf("Pointers: round trip failed (err %v)", err)
+	}
+
+	s := NewSparseFromIndices([]int{3})
+	s.Set(-5)
+	if _, err := s.MarshalJSON(); err != ErrIndexOutOfRange {
+		t.Errorf("Sparse: got error %v expected %v", err, ErrIndexOutOfRange)
+	}
+	if _, err := (JSONIndexes{&s}).MarshalJSON(); err != ErrIndexOutOfRange {
+		t.Errorf("JSONIndexes: got error %v expected %v", err, ErrIndexOutOfRange)
+	}
+	s.Unset(-5)
+	if data, err := s.MarshalJSON(); err != nil || string(data) != "[3]" {
+		t.Errorf("Sparse: got %s (err %v) expected [3]", data, err)
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+import (
+	"encoding/hex"
+	"errors"
+	"io"
+)
+
+// ErrTooLarge describes an error where an encoded bitset could not be decoded
+// because it describes more bits than permitted by a size limit.
+var ErrTooLarge = errors.New("bitset: encoded bitset exceeds size limit")
+
+// Limited wraps a bitset so that it may be decoded from untrusted input
+// without risking unbounded memory use.  Each decoding method behaves like
+// the method of the same name of the wrapped bitset, but returns ErrTooLarge,
+// leaving the bitset unmodified, if the input describes more than MaxBits
+// bits.  Encodings using the layout of a Bytes bitset are measured by their
+// length in bits, and encodings of indexes by their largest index plus one.
+// Length headers are checked before any memory is allocated for the data
+// they describe.
+//
+// The wrapped bitset must be a pointer to the type being decoded.  Each
+// method documents the types it supports, and returns ErrUnsupportedType for
+// any other.
+type Limited struct {
+	BitSet  BitSet
+	MaxBits int
+}
+
+// ReadFrom replaces the contents of the wrapped *Pointers, *Bytes, or *Dense
+// bitset with a streamed bitset read from r.  It implements the
+// io.ReaderFrom interface.
+func (l Limited) ReadFrom(r io.Reader) (int64, error) {
+	// The type is checked before reading so that nothing is consumed from
+	// r when it is unsupported.
+	switch l.BitSet.(type) {
+	case *Pointers, *Bytes, *Dense:
+	default:
+		return 0, ErrUnsupportedType
+	}
+	numBits, data, n, err := readStream(r, l.MaxBits)
+	if err != nil {
+		return n, err
+	}
+	switch bs := l.BitSet.(type) {
+	case *Pointers:
+		bs.setBytes(data)
+	case *Bytes:
+		*bs = data
+	case *Dense:
+		bs.p.setBytes(data)
+		bs.n = numBits
+	}
+	return n, nil
+}
+
+// UnmarshalBinary replaces the contents of the wrapped *Pointers, *Bytes,
+// *Sparse, *Words64, or *Words32 bitset with the bits encoded in data.  It
+// implements the encoding.BinaryUnmarshaler interface.
+func (l Limited) UnmarshalBinary(data []byte) error {
+	if s, ok := l.BitSet.(*Sparse); ok {
+		return s.setSparse(data, l.MaxBits)
+	}
+	if !l.binaryType() {
+		return ErrUnsupportedType
+	}
+	if len(data) > l.MaxBits>>byteShift {
+		return ErrTooLarge
+	}
+	return l.BitSet.(interface{ UnmarshalBinary([]byte) error }).UnmarshalBinary(data)
+}
+
+// UnmarshalText replaces the contents of the wrapped *Pointers, *Bytes,
+// *Words64, or *Words32 bitset with the bits of the hexadecimal text.  It
+// implements the encoding.TextUnmarshaler interface.
+func (l Limited) UnmarshalText(text []byte) error {
+	if !l.binaryType() {
+		return ErrUnsupportedType
+	}
+	// Each hexadecimal digit encodes four bits.
+	if len(text) > l.MaxBits>>2 {
+		return ErrTooLarge
+	}
+	b, err := hex.AppendDecode(nil, text)
+	if err != nil {
+		return err
+	}
+	return l.BitSet.(interface{ UnmarshalBinary([]byte) error }).UnmarshalBinary(b)
+}
+
+// UnmarshalJSON replaces the contents of the wrapped bitset with the bits
+// encoded by a base64 JSON string or an array of indexes.  The wrapped bitset
+// must be a *Pointers, *Bytes, or *Sparse, or a non-nil Sparse.  It
+// implements the json.Unmarshaler interface.
+func (l Limited) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, l.MaxBits, l.BitSet)
+}
+
+// UnmarshalCBOR replaces the contents of the wrapped bitset with the bits
+// encoded by a CBOR byte string or array of indexes.  The wrapped bitset must
+// be a *Pointers, *Bytes, or *Sparse, or a non-nil Sparse.
+func (l Limited) UnmarshalCBOR(data []byte) error {
+	return unmarshalCBOR(data, l.MaxBits, l.BitSet)
+}
+
+// UnmarshalBSONValue replaces the contents of the wrapped *Pointers, *Bytes,
+// *Sparse, or *Dense bitset with the bits of a BSON binary value.  It matches
+// the bson.ValueUnmarshaler interface of the MongoDB Go driver.
+func (l Limited) UnmarshalBSONValue(typ byte, data []byte) error {
+	switch l.BitSet.(type) {
+	case *Pointers, *Bytes, *Sparse, *Dense:
+		return unmarshalBSON(typ, data, l.MaxBits, l.BitSet)
+	default:
+		return ErrUnsupportedType
+	}
+}
+
+// UnmarshalYAML replaces the contents of the wrapped bitset with the bits
+// encoded by a hexadecimal string or a sequence of indexes.  The wrapped
+// bitset must be a *Pointers, *Bytes, or *Sparse, or a non-nil Sparse.
+func (l Limited) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalYAML(unmarshal, l.MaxBits, l.BitSet)
+}
+
+// GobDecode replaces the contents of the wrapped *Pointers, *Bytes, or
+// *Sparse bitset with the bits of its gob encoding data.  It implements the
+// gob.GobDecoder interface.
+func (l Limited) GobDecode(data []byte) error {
+	switch l.BitSet.(type) {
+	case *Pointers, *Bytes, *Sparse:
+		return l.UnmarshalBinary(data)
+	default:
+		return ErrUnsupportedType
+	}
+}
+
+// ApplyDelta applies changes previously encoded by Delta to the wrapped
+// *Pointers or *Bytes bitset.  The limit applies to the size of the newer
+// bitset described by the delta.
+func (l Limited) ApplyDelta(delta []byte) error {
+	switch bs := l.BitSet.(type) {
+	case *Pointers:
+		return bs.applyDelta(delta, l.MaxBits)
+	case *Bytes:
+		return bs.applyDelta(delta, l.MaxBits)
+	default:
+		return ErrUnsupportedType
+	}
+}
+
+// binaryType returns whether the wrapped bitset is decoded from the layout of
+// a Bytes bitset by its UnmarshalBinary method.
+func (l Limited) binaryType() bool {
+	switch l.BitSet.(type) {
+	case *Pointers, *Bytes, *Words64, *Words32:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	. "github.com/jrick/bitset"
+)
+
+func TestLimited(t *testing.T) {
+	const maxBits = 16
+	small := NewBytesFromIndices([]int{0, 15})
+	large := NewBytesFromIndices([]int{0, 16})
+	smallSparse := NewSparseFromIndices([]int{0, 15})
+	largeSparse := NewSparseFromIndices([]int{0, 16})
+
+	stream := func(s Bytes) []byte {
+		var buf bytes.Buffer
+		s.WriteTo(&buf)
+		return buf.Bytes()
+	}
+	readFrom := func(l Limited, data []byte) error {
+		_, err := l.ReadFrom(bytes.NewReader(data))
+		return err
+	}
+	mustMarshal := func(b []byte, err error) []byte {
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+
+	tests := []struct {
+		name   string
+		decode func(l Limited, data []byte) error
+		small  []byte
+		large  []byte
+	}{
+		{"ReadFrom", readFrom, stream(small), stream(large)},
+		{"UnmarshalBinary", Limited.UnmarshalBinary, small, large},
+		{"UnmarshalText", Limited.UnmarshalText,
+			mustMarshal(small.MarshalText()), mustMarshal(large.MarshalText())},
+		{"UnmarshalJSON bytes", Limited.UnmarshalJSON,
+			mustMarshal(small.MarshalJSON()), mustMarshal(large.MarshalJSON())},
+		{"UnmarshalJSON indexes", Limited.UnmarshalJSON,
+			[]byte("[0,15]"), []byte("[0,16]")},
+		{"UnmarshalCBOR bytes", Limited.UnmarshalCBOR,
+			mustMarshal(small.MarshalCBOR()), mustMarshal(large.MarshalCBOR())},
+		{"UnmarshalCBOR indexes", Limited.UnmarshalCBOR,
+			mustMarshal(smallSparse.MarshalCBOR()), mustMarshal(largeSparse.MarshalCBOR())},
+		{"GobDecode", Limited.GobDecode, small, large},
+		{"ApplyDelta", Limited.ApplyDelta, Bytes(nil).Delta(small),
+			Bytes(nil).Delta(large)},
+	}
+	for _, test := range tests {
+		var s Bytes
+		if err := test.decode(Limited{&s, maxBits}, test.small); err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+			continue
+		}
+		if !bytes.Equal(s, small) {
+			t.Errorf("%s: got %x expected %x", test.name, s, small)
+		}
+		if err := test.decode(Limited{&s, maxBits}, test.large); err != ErrTooLarge {
+			t.Errorf("%s: got error %v expected %v", test.name, err, ErrTooLarge)
+		}
+		if !bytes.Equal(s, small) {
+			t.Errorf("%s: bitset modified on error", test.name)
+		}
+
+		var p Pointers
+		if err := test.decode(Limited{&p, maxBits}, test.small); err != nil {
+			t.Errorf("%s Pointers: unexpected error %v", test.name, err)
+		} else if p.Count() != 2 || !p.Get(0) || !p.Get(15) {
+			t.Errorf("%s Pointers: got %v", test.name, p)
+		}
+		if err := test.decode(Limited{&p, maxBits}, test.large); err != ErrTooLarge {
+			t.Errorf("%s Pointers: got error %v expected %v", test.name,
+				err, ErrTooLarge)
+		}
+	}
+
+	gob, _ := smallSparse.GobEncode()
+	largeGob, _ := largeSparse.GobEncode()
+	var sp Sparse
+	if err := (Limited{&sp, maxBits}).GobDecode(gob); err != nil || !sp.Equal(smallSparse) {
+		t.Errorf("Sparse GobDecode: got %v, %v", sp, err)
+	}
+	if err := (Limited{&sp, maxBits}).GobDecode(largeGob); err != ErrTooLarge {
+		t.Errorf("Sparse GobDecode: got error %v expected %v", err, ErrTooLarge)
+	}
+	if !sp.Equal(smallSparse) {
+		t.Errorf("Sparse GobDecode: bitset modified on error")
+	}
+
+	if err := (Limited{Sparse{}, maxBits}).UnmarshalBinary(small); err != ErrUnsupportedType {
+		t.Errorf("unsupported type: got error %v expected %v", err,
+			ErrUnsupportedType)
+	}
+
+	// Nothing is read for an unsupported type.
+	r := bytes.NewReader(stream(small))
+	if _, err := (Limited{&Sparse{}, maxBits}).ReadFrom(r); err != ErrUnsupportedType {
+		t.Errorf("unsupported ReadFrom: got error %v expected %v", err,
+			ErrUnsupportedType)
+	}
+	if n := r.Size() - int64(r.Len()); n != 0 {
+		t.Errorf("unsupported ReadFrom: consumed %d bytes", n)
+	}
+}
+
+func TestHostileLength(t *testing.T) {
+	// A header claiming 2^30 bits which is not followed by any data.
+	hdr := binary.AppendUvarint(nil, 1<<30)
+
+	var s Bytes
+	if _, err := (Limited{&s, 1 << 20}).ReadFrom(bytes.NewReader(hdr)); err != ErrTooLarge {
+		t.Errorf("Limited: got error %v expected %v", err, ErrTooLarge)
+	}
+	// Without a limit, the missing data is detected before the claimed
+	// length is allocated.
+	if _, err := s.ReadFrom(bytes.NewReader(hdr)); err != io.ErrUnexpectedEOF {
+		t.Errorf("Bytes: got error %v expected %v", err, io.ErrUnexpectedEOF)
+	}
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, NewBytes(1024)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DecodeLimit(bytes.NewReader(buf.Bytes()), 1023); err != ErrTooLarge {
+		t.Errorf("DecodeLimit: got error %v expected %v", err, ErrTooLarge)
+	}
+	if _, err := DecodeLimit(bytes.NewReader(buf.Bytes()), 1024); err != nil {
+		t.Errorf("DecodeLimit: unexpected error %v", err)
+	}
+
+	// A Sparse container is limited by its highest set bit.
+	buf.Reset()
+	if err := Encode(&buf, NewSparseFromIndices([]int{1 << 20})); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DecodeLimit(bytes.NewReader(buf.Bytes()), 1<<20); err != ErrTooLarge {
+		t.Errorf("DecodeLimit Sparse: got error %v expected %v", err, ErrTooLarge)
+	}
+	if _, err := DecodeLimit(bytes.NewReader(buf.Bytes()), 1<<20+1); err != nil {
+		t.Errorf("DecodeLimit Sparse: unexpected error %v", err)
+	}
+	hostile := append([]byte("BSET\x01\x03\x08"), 0xff, 0xff, 0xff, 0xff, 0x0f)
+	if _, err := DecodeLimit(bytes.NewReader(hostile), 1<<20); err != ErrTooLarge {
+		t.Errorf("DecodeLimit Sparse length: got error %v expected %v", err, ErrTooLarge)
+	}
+}
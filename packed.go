@@ -0,0 +1,118 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// ErrInvalidPackedArray describes an error where an encoded PackedArray could
+// not be decoded because it was truncated or otherwise malformed.
+var ErrInvalidPackedArray = errors.New("bitset: invalid packed array")
+
+// PackedArray is a fixed-length array of unsigned integers which are each
+// stored using the same number of bits.  Element i occupies bits i*width
+// through i*width+width-1 of a Bytes bitset, with the least significant bit
+// of the element first, so an array of n elements requires only
+// (n*width+7)/8 bytes.
+//
+// Every index passed to a PackedArray is checked against its length, and
+// out of range indexes cause a panic.
+type PackedArray struct {
+	s     Bytes
+	width int
+	n     int
+}
+
+// NewPackedArray returns a new array of n elements, each width bits wide and
+// initially zero.  This function will panic if width is not in the range
+// [1, 64] or if n is negative.
+func NewPackedArray(n, width int) *PackedArray {
+	if width < 1 || width > 64 {
+		panic("bitset: invalid packed array width")
+	}
+	if n < 0 || n > maxInt/width {
+		panic("bitset: invalid packed array length")
+	}
+	return &PackedArray{s: NewBytes(n * width), width: width, n: n}
+}
+
+// Len returns the number of elements in the array.
+func (a *PackedArray) Len() int {
+	return a.n
+}
+
+// Width returns the number of bits used to store each element.
+func (a *PackedArray) Width() int {
+	return a.width
+}
+
+// Bytes returns the Bytes bitset backing a.  The result shares memory with a.
+func (a *PackedArray) Bytes() Bytes {
+	return a.s
+}
+
+// check panics if i is not a valid index of a.
+func (a *PackedArray) check(i int) {
+	if uint(i) >= uint(a.n) {
+		panic("bitset: index out of range")
+	}
+}
+
+// Get returns the element at index i.  This method will panic if i is not in
+// the range [0, a.Len()).
+func (a *PackedArray) Get(i int) uint64 {
+	a.check(i)
+	return a.s.uintAt(i*a.width, uint(a.width))
+}
+
+// Set stores v as the element at index i.  This method will panic if i is not
+// in the range [0, a.Len()) or if v cannot be represented using a.Width()
+// bits.
+func (a *PackedArray) Set(i int, v uint64) {
+	a.check(i)
+	if a.width < 64 && v>>uint(a.width) != 0 {
+		panic("bitset: value overflows packed array width")
+	}
+	a.s.setUintAt(i*a.width, uint(a.width), v)
+}
+
+// MarshalBinary encodes the array as the uvarint element width, followed by
+// the uvarint number of elements, followed by the packed bytes.  It
+// implements the encoding.BinaryMarshaler interface and never returns an
+// error.
+func (a *PackedArray) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, 2*binary.MaxVarintLen64+len(a.s))
+	b = binary.AppendUvarint(b, uint64(a.width))
+	b = binary.AppendUvarint(b, uint64(a.n))
+	return append(b, a.s...), nil
+}
+
+// UnmarshalBinary decodes an array encoded by MarshalBinary, replacing the
+// contents of a.  It implements the encoding.BinaryUnmarshaler interface.
+// If the encoding is malformed, ErrInvalidPackedArray is returned and a is
+// not modified.
+func (a *PackedArray) UnmarshalBinary(data []byte) error {
+	width, r := binary.Uvarint(data)
+	if r <= 0 || width < 1 || width > 64 {
+		return ErrInvalidPackedArray
+	}
+	data = data[r:]
+	n, r := binary.Uvarint(data)
+	if r <= 0 || n > uint64(maxInt)/width {
+		return ErrInvalidPackedArray
+	}
+	data = data[r:]
+	numBits := int(n * width)
+	if len(data) != (numBits+byteModMask)>>byteShift {
+		return ErrInvalidPackedArray
+	}
+	s := make(Bytes, len(data))
+	copy(s, data)
+	s.clearFrom(numBits)
+	*a = PackedArray{s: s, width: int(width), n: int(n)}
+	return nil
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset_test
+
+import (
+	"testing"
+
+	. "github.com/jrick/bitset"
+)
+
+func TestPackedArray(t *testing.T) {
+	for _, width := range []int{1, 3, 7, 8, 13, 32, 63, 64} {
+		const n = 100
+		a := NewPackedArray(n, width)
+		if a.Len() != n || a.Width() != width {
+			t.Errorf("width %d: got len %d width %d", width, a.Len(), a.Width())
+		}
+		if got, exp := len(a.Bytes()), (n*width+7)/8; got != exp {
+			t.Errorf("width %d: got %d bytes expected %d", width, got, exp)
+		}
+		maxVal := ^uint64(0) >> uint(64-width)
+		val := func(i int) uint64 { return uint64(i) * 0x9e3779b97f4a7c15 & maxVal }
+		for i := 0; i < n; i++ {
+			a.Set(i, val(i))
+		}
+		a.Set(n-1, maxVal)
+		for i := 0; i < n-1; i++ {
+			if got := a.Get(i); got != val(i) {
+				t.Errorf("width %d: element %d got %#x expected %#x",
+					width, i, got, val(i))
+			}
+		}
+		if got := a.Get(n - 1); got != maxVal {
+			t.Errorf("width %d: last element got %#x expected %#x", width, got, maxVal)
+		}
+
+		enc, err := a.MarshalBinary()
+		if err != nil {
+			t.Fatalf("width %d: MarshalBinary: %v", width, err)
+		}
+		var b PackedArray
+		if err := b.UnmarshalBinary(enc); err != nil {
+			t.Fatalf("width %d: UnmarshalBinary: %v", width, err)
+		}
+		if b.Len() != n || b.Width() != width {
+			t.Fatalf("width %d: decoded len %d width %d", width, b.Len(), b.Width())
+		}
+		for i := 0; i < n; i++ {
+			if b.Get(i) != a.Get(i) {
+				t.Errorf("width %d: decoded element %d got %#x expected %#x",
+					width, i, b.Get(i), a.Get(i))
+			}
+		}
+	}
+}
+
+func TestPackedArrayPanics(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func()
+	}{
+		{"zero width", func() { NewPackedArray(1, 0) }},
+		{"wide", func() { NewPackedArray(1, 65) }},
+		{"negative length", func() { NewPackedArray(-1, 8) }},
+		{"get out of range", func() { NewPackedArray(4, 8).Get(4) }},
+		{"set negative", func() { NewPackedArray(4, 8).Set(-1, 0) }},
+		{"set overflow", func() { NewPackedArray(4, 3).Set(0, 8) }},
+	}
+	for _, test := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: did not panic", test.name)
+				}
+			}()
+			test.fn()
+		}()
+	}
+}
+
+func TestPackedArrayInvalid(t *testing.T) {
+	tests := [][]byte{
+		nil,
+		{0, 0},
+		{65, 0},
+		{8},
+		{8, 2, 1},
+		{8, 2, 1, 2, 3},
+		{3, 3},
+		{64, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01},
+	}
+	for testNum, enc := range tests {
+		a := NewPackedArray(1, 8)
+		a.Set(0, 42)
+		if err := a.UnmarshalBinary(enc); err != ErrInvalidPackedArray {
+			t.Errorf("Test %d: got error %v expected %v", testNum, err,
+				ErrInvalidPackedArray)
+		}
+		if a.Len() != 1 || a.Get(0) != 42 {
+			t.Errorf("Test %d: array modified by failed decode", testNum)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// ErrInvalidProto describes an error where a BitSet protocol buffer message
+// could not be decoded because it was malformed, or where its data does not
+// hold exactly the number of bits recorded by its bit length.
+var ErrInvalidProto = errors.New("bitset: invalid protocol buffer message")
+
+// Field numbers and wire types of the BitSet message defined by bitset.proto.
+const (
+	protoBitLength = 1
+	protoData      = 2
+	protoWordSize  = 3
+
+	protoVarint = 0
+	protoI64    = 1
+	protoLen    = 2
+	protoI32    = 5
+)
+
+// protoMaxData is the largest length of a protocol buffer bytes field.
+const protoMaxData = 1<<31 - 1
+
+// ProtoBitSet is the Go representation of the BitSet message defined by
+// bitset.proto, which allows bitsets to be embedded in protocol buffer APIs
+// with the same meaning in every language.  Its fields correspond to the
+// fields of the message of the same names, and may be copied to and from the
+// message type generated from bitset.proto by protoc.  The MarshalBinary and
+// UnmarshalBinary methods encode the message using the protocol buffer wire
+// format, and may be used where generated code is not available.
+type ProtoBitSet struct {
+	BitLength uint64
+	Data      []byte
+	WordSize  uint32
+}
+
+// ToProto returns the ProtoBitSet message describing bs, which must be a
+// Pointers, Bytes, Sparse, Words64, Words32, *Dense, or *Bits64 bitset, or a
+// pointer to one of the slice or map types; otherwise ErrUnsupportedType is
+// returned.  The message describes a Sparse bitset through its highest
+// nonzero pointer.  If those bits exceed the 2 GiB limit of a protocol buffer
+// bytes field, as they do for any Sparse bitset holding a negative index,
+// ErrIndexOutOfRange is returned without allocating them.
+func ToProto(bs BitSet) (*ProtoBitSet, error) {
+	_, wordSize, numBits, data, err := encodedParts(bs, protoMaxData)
+	if err != nil {
+		return nil, err
+	}
+	return &ProtoBitSet{
+		BitLength: uint64(numBits),
+		Data:      append([]byte(nil), data...),
+		WordSize:  uint32(wordSize),
+	}, nil
+}
+
+// FromProto returns a Dense bitset with the length and bits described by m.
+// If the data of m does not hold exactly (BitLength+7)/8 bytes, or any bit
+// at or beyond BitLength is set, ErrInvalidProto is returned.  The word size
+// is not checked.
+func FromProto(m *ProtoBitSet) (*Dense, error) {
+	if m.BitLength > uint64(maxInt-byteModMask) {
+		return nil, ErrInvalidProto
+	}
+	n := int(m.BitLength)
+	if len(m.Data) != (n+byteModMask)>>byteShift {
+		return nil, ErrInvalidProto
+	}
+	if n&byteModMask != 0 && m.Data[len(m.Data)-1]>>(uint(n)&byteModMask) != 0 {
+		return nil, ErrInvalidProto
+	}
+	d := &Dense{n: n}
+	d.p.setBytes(m.Data)
+	return d, nil
+}
+
+// MarshalBinary encodes m using the protocol buffer wire format.  As with
+// proto3 encoders, fields holding their zero value are omitted.  It
+// implements the encoding.BinaryMarshaler interface.
+func (m *ProtoBitSet) MarshalBinary() ([]byte, error) {
+	var buf []byte
+	if m.BitLength != 0 {
+		buf = binary.AppendUvarint(buf, protoBitLength<<3|protoVarint)
+		buf = binary.AppendUvarint(buf, m.BitLength)
+	}
+	if len(m.Data) != 0 {
+		buf = binary.AppendUvarint(buf, protoData<<3|protoLen)
+		buf = binary.AppendUvarint(buf, uint64(len(m.Data)))
+		buf = append(buf, m.Data...)
+	}
+	if m.WordSize != 0 {
+		buf = binary.AppendUvarint(buf, protoWordSize<<3|protoVarint)
+		buf = binary.AppendUvarint(buf, uint64(m.WordSize))
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary replaces m with the message decoded from the protocol
+// buffer wire format.  As with other protocol buffer decoders, unknown fields
+// are skipped and the last occurrence of a repeated field is used.  If the
+// encoding is malformed, ErrInvalidProto is returned and m is not modified.
+// It implements the encoding.BinaryUnmarshaler interface.
+func (m *ProtoBitSet) UnmarshalBinary(data []byte) error {
+	var v ProtoBitSet
+	for len(data) != 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return ErrInvalidProto
+		}
+		data = data[n:]
+		field, wire := key>>3, key&7
+		if field == 0 {
+			return ErrInvalidProto
+		}
+		var x uint64
+		var b []byte
+		switch wire {
+		case protoVarint:
+			x, n = binary.Uvarint(data)
+			if n <= 0 {
+				return ErrInvalidProto
+			}
+		case protoI64:
+			n = 8
+		case protoI32:
+			n = 4
+		case protoLen:
+			var l uint64
+			l, n = binary.Uvarint(data)
+			if n <= 0 || l > uint64(len(data)-n) {
+				return ErrInvalidProto
+			}
+			b = data[n : n+int(l)]
+			n += int(l)
+		default:
+			// Groups are not used by the message.
+			return ErrInvalidProto
+		}
+		if n > len(data) {
+			return ErrInvalidProto
+		}
+		data = data[n:]
+
+		switch {
+		case field == protoBitLength && wire == protoVarint:
+			v.BitLength = x
+		case field == protoData && wire == protoLen:
+			v.Data = b
+		case field == protoWordSize && wire == protoVarint:
+			// uint32 fields are truncated from their varint.
+			v.WordSize = uint32(x)
+		case field <= protoWordSize:
+			return ErrInvalidProto
+		}
+	}
+	v.Data = append([]byte(nil), v.Data...)
+	*m = v
+	return nil
+}
@@ -0,0 +1,150 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset_test
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/jrick/bitset"
+)
+
+func TestProto(t *testing.T) {
+	d := NewDense(12)
+	d.Set(0)
+	d.Set(11)
+	w := Words32{0x80000001}
+	tests := []struct {
+		bs       BitSet
+		enc      []byte
+		numBits  int
+		wordSize uint32
+	}{
+		{Bytes{}, []byte{0x18, 0x01}, 0, 1},
+		{d, []byte{0x08, 0x0c, 0x12, 0x02, 0x01, 0x08}, 12, 0},
+		{Bytes{0x81}, []byte{0x08, 0x08, 0x12, 0x01, 0x81, 0x18, 0x01}, 8, 1},
+		{&w, []byte{0x08, 0x20, 0x12, 0x04, 0x01, 0x00, 0x00, 0x80, 0x18, 0x04}, 32, 4},
+	}
+	for testNum, test := range tests {
+		m, err := ToProto(test.bs)
+		if err != nil {
+			t.Fatalf("Test %d: ToProto: %v", testNum, err)
+		}
+		if m.BitLength != uint64(test.numBits) {
+			t.Errorf("Test %d: got bit length %d expected %d", testNum,
+				m.BitLength, test.numBits)
+		}
+		if test.wordSize == 0 {
+			// The word size of a Dense bitset depends on the
+			// machine.
+			m.WordSize = 0
+		}
+		if m.WordSize != test.wordSize {
+			t.Errorf("Test %d: got word size %d expected %d", testNum,
+				m.WordSize, test.wordSize)
+		}
+		enc, _ := m.MarshalBinary()
+		if !bytes.Equal(enc, test.enc) {
+			t.Errorf("Test %d: got encoding %x expected %x", testNum, enc, test.enc)
+		}
+
+		var m2 ProtoBitSet
+		if err := m2.UnmarshalBinary(enc); err != nil {
+			t.Fatalf("Test %d: UnmarshalBinary: %v", testNum, err)
+		}
+		got, err := FromProto(&m2)
+		if err != nil {
+			t.Fatalf("Test %d: FromProto: %v", testNum, err)
+		}
+		if got.Len() != test.numBits {
+			t.Errorf("Test %d: got length %d expected %d", testNum,
+				got.Len(), test.numBits)
+		}
+		for i := range test.numBits {
+			if got.Get(i) != test.bs.Get(i) {
+				t.Errorf("Test %d: bit %d mismatch", testNum, i)
+			}
+		}
+	}
+
+	if _, err := ToProto(new(AutoGrowBytes)); err != ErrUnsupportedType {
+		t.Errorf("ToProto: got error %v expected %v", err, ErrUnsupportedType)
+	}
+
+	// Sparse bitsets whose bits could not be held by a bytes field are
+	// rejected without allocating them.
+	for _, i := range []int{int(^uint(0)>>1) - 1, -1} {
+		s := NewSparseFromIndices([]int{1, i})
+		if _, err := ToProto(&s); err != ErrIndexOutOfRange {
+			t.Errorf("ToProto index %d: got error %v expected %v", i, err,
+				ErrIndexOutOfRange)
+		}
+	}
+	m, err := ToProto(NewSparseFromIndices([]int{1, 1000}))
+	if err != nil || m.BitLength != 1024 || len(m.Data) != 128 {
+		t.Errorf("ToProto Sparse: got %v, %v", m, err)
+	}
+}
+
+func TestProtoUnmarshal(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		exp  ProtoBitSet
+		err  error
+	}{
+		{"empty", nil, ProtoBitSet{}, nil},
+		{"unknown fields", []byte{
+			0x20, 0x96, 0x01, // field 4 varint
+			0x29, 1, 2, 3, 4, 5, 6, 7, 8, // field 5 fixed64
+			0x35, 1, 2, 3, 4, // field 6 fixed32
+			0x3a, 0x01, 0xff, // field 7 bytes
+			0x08, 0x03, 0x12, 0x01, 0x05,
+		}, ProtoBitSet{BitLength: 3, Data: []byte{0x05}}, nil},
+		{"last field wins", []byte{0x08, 0x01, 0x08, 0x02, 0x12, 0x01, 0x03},
+			ProtoBitSet{BitLength: 2, Data: []byte{0x03}}, nil},
+		{"truncated word size", []byte{0x18, 0x81, 0x80, 0x80, 0x80, 0x10},
+			ProtoBitSet{WordSize: 1}, nil},
+		{"truncated key", []byte{0x80}, ProtoBitSet{}, ErrInvalidProto},
+		{"truncated varint", []byte{0x08, 0x80}, ProtoBitSet{}, ErrInvalidProto},
+		{"truncated bytes", []byte{0x12, 0x02, 0x01}, ProtoBitSet{}, ErrInvalidProto},
+		{"truncated fixed64", []byte{0x29, 1, 2}, ProtoBitSet{}, ErrInvalidProto},
+		{"field zero", []byte{0x00, 0x00}, ProtoBitSet{}, ErrInvalidProto},
+		{"group", []byte{0x23, 0x24}, ProtoBitSet{}, ErrInvalidProto},
+		{"wrong wire type", []byte{0x0a, 0x00}, ProtoBitSet{}, ErrInvalidProto},
+	}
+	for _, test := range tests {
+		m := ProtoBitSet{BitLength: 99}
+		err := m.UnmarshalBinary(test.data)
+		if err != test.err {
+			t.Errorf("%s: got error %v expected %v", test.name, err, test.err)
+			continue
+		}
+		if err != nil {
+			if m.BitLength != 99 {
+				t.Errorf("%s: message modified on error", test.name)
+			}
+			continue
+		}
+		if m.BitLength != test.exp.BitLength || !bytes.Equal(m.Data, test.exp.Data) ||
+			m.WordSize != test.exp.WordSize {
+			t.Errorf("%s: got %+v expected %+v", test.name, m, test.exp)
+		}
+	}
+}
+
+func TestFromProtoInvalid(t *testing.T) {
+	tests := []ProtoBitSet{
+		{BitLength: 9, Data: []byte{0x01}},
+		{BitLength: 8, Data: []byte{0x01, 0x00}},
+		{BitLength: 3, Data: []byte{0x08}},
+		{BitLength: 1 << 63, Data: nil},
+	}
+	for testNum, m := range tests {
+		if _, err := FromProto(&m); err != ErrInvalidProto {
+			t.Errorf("Test %d: got error %v expected %v", testNum, err, ErrInvalidProto)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+import (
+	"io"
+	"math"
+)
+
+// readChunk is the number of bytes read at a time by RandomReader methods
+// which scan a range of the bitset.
+const readChunk = 64 << 10
+
+// RandomReader answers queries about a serialized bitset directly from an
+// io.ReaderAt, such as an *os.File, reading only the bytes needed by each
+// query rather than loading the entire bitset into memory.  Get reads a
+// single byte, while Count and NextSet read sequentially in bounded chunks.
+//
+// The bitset must use the layout of a Bytes bitset.  A RandomReader makes no
+// attempt to cache data, and it is safe for concurrent use if the underlying
+// io.ReaderAt is.  Data which ends before the recorded length results in
+// io.ErrUnexpectedEOF.
+type RandomReader struct {
+	r       io.ReaderAt
+	off     int64 // offset of the first data byte
+	numBits int
+}
+
+// NewRandomReader returns a RandomReader for the streamed bitset written by
+// WriteTo at offset off of r.  Only the bit length header is read.
+func NewRandomReader(r io.ReaderAt, off int64) (*RandomReader, error) {
+	cr := &countingReader{r: io.NewSectionReader(r, off, math.MaxInt64-off)}
+	numBits, err := readStreamHeader(cr, maxInt)
+	if err != nil {
+		return nil, err
+	}
+	return NewRandomReaderBytes(r, off+cr.n, numBits), nil
+}
+
+// NewRandomReaderBytes returns a RandomReader for a bitset of numBits bits
+// stored without a header at offset off of r, such as a file written with
+// the binary encoding of a Bytes or Pointers bitset.
+func NewRandomReaderBytes(r io.ReaderAt, off int64, numBits int) *RandomReader {
+	return &RandomReader{r: r, off: off, numBits: numBits}
+}
+
+// Len returns the number of bits in the bitset.
+func (r *RandomReader) Len() int {
+	return r.numBits
+}
+
+// Get returns whether the bit at index i is set.  If i is outside of the
+// range [0, r.Len()), ErrIndexOutOfRange is returned.
+func (r *RandomReader) Get(i int) (bool, error) {
+	if i < 0 || i >= r.numBits {
+		return false, ErrIndexOutOfRange
+	}
+	var b [1]byte
+	if err := r.read(b[:], i>>byteShift); err != nil {
+		return false, err
+	}
+	return b[0]&(1<<(uint(i)&byteModMask)) != 0, nil
+}
+
+// Count returns the total number of set bits in the bitset.
+func (r *RandomReader) Count() (int, error) {
+	n := 0
+	err := r.scan(0, func(_ int, chunk Bytes) bool {
+		n += chunk.Count()
+		return true
+	})
+	return n, err
+}
+
+// NextSet returns the index of the first set bit at or after index i, or -1
+// if there is no such bit.  Negative indexes are treated as zero.
+func (r *RandomReader) NextSet(i int) (int, error) {
+	if i < 0 {
+		i = 0
+	}
+	if i >= r.numBits {
+		return -1, nil
+	}
+	next := -1
+	err := r.scan(i>>byteShift, func(start int, chunk Bytes) bool {
+		if start == i>>byteShift {
+			chunk[0] &^= 1<<(uint(i)&byteModMask) - 1
+		}
+		if j := chunk.NextSet(0); j != -1 {
+			next = start<<byteShift + j
+			return false
+		}
+		return true
+	})
+	return next, err
+}
+
+// scan reads the bytes of the bitset from the byte index start in chunks,
+// calling fn with the byte index of each chunk until fn returns false.  Bits
+// at or beyond the length of the bitset are cleared, and fn may modify the
+// chunk.
+func (r *RandomReader) scan(start int, fn func(start int, chunk Bytes) bool) error {
+	size := (r.numBits + byteModMask) >> byteShift
+	buf := make(Bytes, min(size-start, readChunk))
+	for start < size {
+		chunk := buf[:min(size-start, readChunk)]
+		if err := r.read(chunk, start); err != nil {
+			return err
+		}
+		if start+len(chunk) == size {
+			chunk.clearFrom(r.numBits - start<<byteShift)
+		}
+		if !fn(start, chunk) {
+			return nil
+		}
+		start += len(chunk)
+	}
+	return nil
+}
+
+// read fills b with the bytes of the bitset beginning at byte index i.
+func (r *RandomReader) read(b []byte, i int) error {
+	n, err := r.r.ReadAt(b, r.off+int64(i))
+	if n == len(b) {
+		return nil
+	}
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	. "github.com/jrick/bitset"
+)
+
+// countingReaderAt records the number of bytes read through it.
+type countingReaderAt struct {
+	r io.ReaderAt
+	n int
+}
+
+func (c *countingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	n, err := c.r.ReadAt(p, off)
+	c.n += n
+	return n, err
+}
+
+func TestRandomReader(t *testing.T) {
+	const numBits = 1<<20 + 3
+	set := []int{0, 7, 8, 100, 70000, 1 << 19, numBits - 1}
+	d := NewDense(numBits)
+	for _, i := range set {
+		d.Set(i)
+	}
+	var buf bytes.Buffer
+	buf.WriteString("prefix")
+	if _, err := d.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	// A set bit beyond the recorded length must be ignored.
+	data := buf.Bytes()
+	data[len(data)-1] |= 0x80
+
+	cr := &countingReaderAt{r: bytes.NewReader(data)}
+	r, err := NewRandomReader(cr, int64(len("prefix")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Len() != numBits {
+		t.Errorf("Len: got %d expected %d", r.Len(), numBits)
+	}
+
+	cr.n = 0
+	for _, i := range []int{0, 1, 100, 101, 1 << 19, numBits - 1} {
+		got, err := r.Get(i)
+		if err != nil {
+			t.Fatalf("Get(%d): %v", i, err)
+		}
+		if got != d.Get(i) {
+			t.Errorf("Get(%d): got %v expected %v", i, got, d.Get(i))
+		}
+	}
+	if cr.n != 6 {
+		t.Errorf("Get: read %d bytes expected 6", cr.n)
+	}
+	for _, i := range []int{-1, numBits, numBits + 1} {
+		if _, err := r.Get(i); err != ErrIndexOutOfRange {
+			t.Errorf("Get(%d): got error %v expected %v", i, err,
+				ErrIndexOutOfRange)
+		}
+	}
+
+	if n, err := r.Count(); err != nil || n != len(set) {
+		t.Errorf("Count: got %d, %v expected %d", n, err, len(set))
+	}
+
+	exp := -1
+	for _, i := range []int{-5, 0, 1, 8, 9, 101, 70000, 70001, numBits - 1, numBits} {
+		exp = -1
+		for _, j := range set {
+			if j >= i {
+				exp = j
+				break
+			}
+		}
+		got, err := r.NextSet(i)
+		if err != nil || got != exp {
+			t.Errorf("NextSet(%d): got %d, %v expected %d", i, got, err, exp)
+		}
+	}
+
+	// Truncated data is reported when a read reaches it.
+	short := NewRandomReaderBytes(bytes.NewReader([]byte{0, 0}), 0, 64)
+	if got, err := short.Get(8); err != nil || got {
+		t.Errorf("Get within data: got %v, %v", got, err)
+	}
+	if _, err := short.Get(63); err != io.ErrUnexpectedEOF {
+		t.Errorf("Get beyond data: got error %v expected %v", err, io.ErrUnexpectedEOF)
+	}
+	if _, err := short.Count(); err != io.ErrUnexpectedEOF {
+		t.Errorf("Count: got error %v expected %v", err, io.ErrUnexpectedEOF)
+	}
+}
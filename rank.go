@@ -0,0 +1,136 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+import (
+	"math/bits"
+	"sort"
+)
+
+// Rank returns the number of set bits in the range [0, i).  This method will
+// panic if i results in a pointer index that exceeds the number of pointers
+// held by the bitset.
+func (p Pointers) Rank(i int) int {
+	return p.CountRange(0, i)
+}
+
+// RankMany returns the rank of every index in indexes, as if by calling Rank
+// on each index, using a single pass over the bitset.  The indexes need not
+// be sorted, and results are returned in the same order as the indexes.  This
+// method will panic if any index results in a pointer index that exceeds the
+// number of pointers held by the bitset.
+func (p Pointers) RankMany(indexes []int) []int {
+	ranks := make([]int, len(indexes))
+	w, acc := 0, 0
+	for _, j := range sortedOrder(indexes) {
+		i := uint(indexes[j])
+		for ; w < int(i>>ptrShift); w++ {
+			acc += bits.OnesCount(uint(p[w]))
+		}
+		ranks[j] = acc
+		if rem := i & ptrModMask; rem != 0 {
+			ranks[j] += bits.OnesCount(uint(p[w] & (1<<rem - 1)))
+		}
+	}
+	return ranks
+}
+
+// Rank returns the number of set bits in the range [0, i).  This method will
+// panic if i results in a byte index that exceeds the number of bytes held by
+// the bitset.
+func (s Bytes) Rank(i int) int {
+	return s.CountRange(0, i)
+}
+
+// RankMany returns the rank of every index in indexes, as if by calling Rank
+// on each index, using a single pass over the bitset.  The indexes need not
+// be sorted, and results are returned in the same order as the indexes.  This
+// method will panic if any index results in a byte index that exceeds the
+// number of bytes held by the bitset.
+func (s Bytes) RankMany(indexes []int) []int {
+	ranks := make([]int, len(indexes))
+	b, acc := 0, 0
+	for _, j := range sortedOrder(indexes) {
+		i := uint(indexes[j])
+		for ; b < int(i>>byteShift); b++ {
+			acc += bits.OnesCount8(s[b])
+		}
+		ranks[j] = acc
+		if rem := i & byteModMask; rem != 0 {
+			ranks[j] += bits.OnesCount8(s[b] & (1<<rem - 1))
+		}
+	}
+	return ranks
+}
+
+// sortedOrder returns the positions of indexes ordered such that the values
+// they refer to are increasing.  The indexes slice itself is not modified.
+func sortedOrder(indexes []int) []int {
+	order := make([]int, len(indexes))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return indexes[order[a]] < indexes[order[b]]
+	})
+	return order
+}
+
+// Select returns the index of the k-th set bit, counting from zero, such
+// that Select(Rank(i)) == i for any set bit i.  Whole pointers are skipped
+// using their population counts and the bit is then located within the
+// final pointer without testing each bit.  If fewer than k+1 bits are set,
+// -1 is returned.
+func (p Pointers) Select(k int) int {
+	if k < 0 {
+		return -1
+	}
+	for w, ptr := range p {
+		n := bits.OnesCount(uint(ptr))
+		if k < n {
+			return w<<ptrShift + selectWord(uint(ptr), k)
+		}
+		k -= n
+	}
+	return -1
+}
+
+// Select returns the index of the k-th set bit, counting from zero, such
+// that Select(Rank(i)) == i for any set bit i.  Whole bytes are skipped
+// using their population counts.  If fewer than k+1 bits are set, -1 is
+// returned.
+func (s Bytes) Select(k int) int {
+	if k < 0 {
+		return -1
+	}
+	for i, b := range s {
+		n := bits.OnesCount8(b)
+		if k < n {
+			return i<<byteShift + selectWord(uint(b), k)
+		}
+		k -= n
+	}
+	return -1
+}
+
+// selectWord returns the bit index of the k-th (counting from zero) set bit
+// of w.  The search space is halved each step by comparing k against the
+// population count of the lower half, so this takes log2 of the word size
+// steps regardless of where the bit is.  The result is undefined if w has
+// fewer than k+1 bits set.
+func selectWord(w uint, k int) int {
+	pos := 0
+	for width := uint(bits.UintSize / 2); width != 0; width >>= 1 {
+		low := w & (1<<width - 1)
+		if n := bits.OnesCount(low); k >= n {
+			k -= n
+			w >>= width
+			pos += int(width)
+		} else {
+			w = low
+		}
+	}
+	return pos
+}
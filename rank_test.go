@@ -0,0 +1,90 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset_test
+
+import (
+	"testing"
+
+	. "github.com/jrick/bitset"
+)
+
+type ranker interface {
+	BitSet
+	Rank(i int) int
+	RankMany(indexes []int) []int
+}
+
+func TestRankMany(t *testing.T) {
+	const numBits = 256
+	set := []int{0, 3, 7, 8, 63, 64, 100, 128, 200, 255}
+	indexes := []int{256, 0, 64, 1, 65, 8, 7, 255, 128, 64, 129, 3, 4}
+	for _, c := range []struct {
+		name string
+		bs   ranker
+	}{
+		{"Pointers", NewPointers(numBits)},
+		{"Bytes", NewBytes(numBits)},
+	} {
+		for _, i := range set {
+			c.bs.Set(i)
+		}
+		ranks := c.bs.RankMany(indexes)
+		for k, i := range indexes {
+			exp := 0
+			for j := 0; j < i; j++ {
+				if c.bs.Get(j) {
+					exp++
+				}
+			}
+			if got := c.bs.Rank(i); got != exp {
+				t.Errorf("bitset %s: Rank(%d) got %d expected %d",
+					c.name, i, got, exp)
+			}
+			if ranks[k] != exp {
+				t.Errorf("bitset %s: RankMany index %d got %d expected %d",
+					c.name, i, ranks[k], exp)
+			}
+		}
+	}
+}
+
+func TestSelect(t *testing.T) {
+	const numBits = 256
+	set := []int{0, 3, 7, 8, 63, 64, 100, 128, 200, 255}
+	for _, c := range []struct {
+		name string
+		bs   interface {
+			ranker
+			Select(k int) int
+		}
+	}{
+		{"Pointers", NewPointers(numBits)},
+		{"Bytes", NewBytes(numBits)},
+	} {
+		if got := c.bs.Select(0); got != -1 {
+			t.Errorf("bitset %s: empty Select(0) got %d expected -1",
+				c.name, got)
+		}
+		for _, i := range set {
+			c.bs.Set(i)
+		}
+		for k, exp := range set {
+			if got := c.bs.Select(k); got != exp {
+				t.Errorf("bitset %s: Select(%d) got %d expected %d",
+					c.name, k, got, exp)
+			}
+			if got := c.bs.Rank(c.bs.Select(k)); got != k {
+				t.Errorf("bitset %s: Rank(Select(%d)) got %d",
+					c.name, k, got)
+			}
+		}
+		for _, k := range []int{-1, len(set), len(set) + 1} {
+			if got := c.bs.Select(k); got != -1 {
+				t.Errorf("bitset %s: Select(%d) got %d expected -1",
+					c.name, k, got)
+			}
+		}
+	}
+}
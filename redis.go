@@ -0,0 +1,247 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+import "math/bits"
+
+// RedisBitmap represents a bitset using the layout of a Redis string used as
+// a bitmap, where offset i is held by byte i/8, with the most significant bit
+// first.  This is the reverse of the bit order within each byte of a Bytes
+// bitset.  The bytes of a RedisBitmap may be exchanged directly with the
+// value of a Redis key (GET, SET, GETRANGE, SETRANGE), and its methods mirror
+// the semantics of the Redis bitmap commands, including their handling of
+// negative and out of range arguments.
+//
+// Offset i of a RedisBitmap and index i of the Bytes bitset returned by its
+// Bytes method always refer to the same bit.
+type RedisBitmap []byte
+
+// RedisUnit selects whether the range arguments of BitCount and BitPos
+// methods are interpreted as byte or bit offsets, as with the BYTE and BIT
+// options of the Redis commands.
+type RedisUnit int
+
+// Range units.
+const (
+	RedisByte RedisUnit = iota
+	RedisBit
+)
+
+// RedisOp is an operation performed by RedisBitOp.
+type RedisOp int
+
+// Operations performed by RedisBitOp, named after the BITOP operations.
+const (
+	RedisAnd RedisOp = iota
+	RedisOr
+	RedisXor
+	RedisNot
+)
+
+// NewRedisBitmap returns a RedisBitmap with the same bits set as s.
+func NewRedisBitmap(s Bytes) RedisBitmap {
+	r := make(RedisBitmap, len(s))
+	for i, b := range s {
+		r[i] = bits.Reverse8(b)
+	}
+	return r
+}
+
+// Bytes returns a new Bytes bitset with the same bits set as r.
+func (r RedisBitmap) Bytes() Bytes {
+	s := make(Bytes, len(r))
+	for i, b := range r {
+		s[i] = bits.Reverse8(b)
+	}
+	return s
+}
+
+// GetBit returns whether the bit at offset i is set, as with GETBIT.
+// Offsets beyond the end of r are unset.  This method will panic if i is
+// negative.
+func (r RedisBitmap) GetBit(i int) bool {
+	if i < 0 {
+		panic("bitset: index out of range")
+	}
+	k := i >> byteShift
+	return k < len(r) && r[k]&(0x80>>(uint(i)&byteModMask)) != 0
+}
+
+// SetBit sets or unsets the bit at offset i depending on the value of b,
+// growing r with zero bytes if necessary, and returns the previous value of
+// the bit, as with SETBIT.  This method will panic if i is negative.
+func (r *RedisBitmap) SetBit(i int, b bool) bool {
+	old := r.GetBit(i)
+	k := i >> byteShift
+	if k >= len(*r) {
+		*r = append(*r, make(RedisBitmap, k+1-len(*r))...)
+	}
+	mask := byte(0x80) >> (uint(i) & byteModMask)
+	if b {
+		(*r)[k] |= mask
+	} else {
+		(*r)[k] &^= mask
+	}
+	return old
+}
+
+// BitCount returns the number of set bits in r, as with BITCOUNT without a
+// range.
+func (r RedisBitmap) BitCount() int {
+	n := 0
+	for _, b := range r {
+		n += bits.OnesCount8(b)
+	}
+	return n
+}
+
+// BitCountRange returns the number of set bits between the offsets start and
+// end inclusive, measured in unit, as with BITCOUNT with a range.  Negative
+// offsets count back from the end of r, where -1 is the final byte or bit.
+func (r RedisBitmap) BitCountRange(start, end int, unit RedisUnit) int {
+	first, last, ok := r.bitRange(start, end, unit)
+	if !ok {
+		return 0
+	}
+	kf, kl := first>>byteShift, last>>byteShift
+	head := byte(0xff) >> (uint(first) & byteModMask)
+	tail := byte(0xff) << (byteModMask - uint(last)&byteModMask)
+	if kf == kl {
+		return bits.OnesCount8(r[kf] & head & tail)
+	}
+	n := bits.OnesCount8(r[kf]&head) + bits.OnesCount8(r[kl]&tail)
+	for _, b := range r[kf+1 : kl] {
+		n += bits.OnesCount8(b)
+	}
+	return n
+}
+
+// BitPos returns the offset of the first bit of r equal to bit, as with
+// BITPOS without a range.  As r is treated as being followed by unset bits,
+// searching for an unset bit in a bitmap with every bit set returns the
+// first offset beyond its end.  Searching for a set bit which does not exist
+// returns -1.
+func (r RedisBitmap) BitPos(bit bool) int {
+	return r.BitPosFrom(bit, 0, RedisByte)
+}
+
+// BitPosFrom returns the offset of the first bit of r at or after the offset
+// start, measured in unit, which is equal to bit, as with BITPOS with a
+// start but no end.  Results are otherwise as for BitPos.
+func (r RedisBitmap) BitPosFrom(bit bool, start int, unit RedisUnit) int {
+	if len(r) == 0 {
+		return redisMissing(bit)
+	}
+	first, last, ok := r.bitRange(start, -1, unit)
+	if !ok {
+		return -1
+	}
+	if i := r.search(bit, first, last); i != -1 || bit {
+		return i
+	}
+	return len(r) << byteShift
+}
+
+// BitPosRange returns the offset of the first bit of r between the offsets
+// start and end inclusive, measured in unit, which is equal to bit, as with
+// BITPOS with a range.  Unlike BitPos and BitPosFrom, -1 is returned when no
+// bit in the range is equal to bit, for both set and unset bits.
+func (r RedisBitmap) BitPosRange(bit bool, start, end int, unit RedisUnit) int {
+	if len(r) == 0 {
+		return redisMissing(bit)
+	}
+	first, last, ok := r.bitRange(start, end, unit)
+	if !ok {
+		return -1
+	}
+	return r.search(bit, first, last)
+}
+
+// RedisBitOp returns the result of applying op to the bitmaps srcs, as with
+// BITOP.  The result has the length of the longest source, and shorter
+// sources are treated as being padded with zero bytes.  This function will
+// panic if srcs is empty, or if op is RedisNot and there is not exactly one
+// source.
+func RedisBitOp(op RedisOp, srcs ...RedisBitmap) RedisBitmap {
+	if len(srcs) == 0 || (op == RedisNot && len(srcs) != 1) {
+		panic("bitset: invalid number of BITOP sources")
+	}
+	n := 0
+	for _, src := range srcs {
+		n = max(n, len(src))
+	}
+	dst := make(Bytes, n)
+	copy(dst, srcs[0])
+	for _, src := range srcs[1:] {
+		switch op {
+		case RedisAnd:
+			// And clears the bytes of dst beyond the source.
+			dst.And(Bytes(src))
+		case RedisOr:
+			dst.Or(Bytes(src))
+		case RedisXor:
+			dst.Xor(Bytes(src))
+		}
+	}
+	if op == RedisNot {
+		for i := range dst {
+			dst[i] = ^dst[i]
+		}
+	}
+	return RedisBitmap(dst)
+}
+
+// bitRange converts the Redis range arguments start and end, measured in
+// unit, to inclusive bit offsets of r.  If the range is empty, ok is false.
+func (r RedisBitmap) bitRange(start, end int, unit RedisUnit) (first, last int, ok bool) {
+	n := len(r)
+	if unit == RedisBit {
+		n <<= byteShift
+	}
+	if start < 0 {
+		start += n
+	}
+	if end < 0 {
+		end += n
+	}
+	start, end = max(start, 0), min(max(end, 0), n-1)
+	if start > end {
+		return 0, 0, false
+	}
+	if unit == RedisByte {
+		return start << byteShift, end<<byteShift + byteModMask, true
+	}
+	return start, end, true
+}
+
+// search returns the first offset between first and last inclusive of a bit
+// equal to bit, or -1 if there is no such bit.
+func (r RedisBitmap) search(bit bool, first, last int) int {
+	kf, kl := first>>byteShift, last>>byteShift
+	for k := kf; k <= kl; k++ {
+		b := r[k]
+		if !bit {
+			b = ^b
+		}
+		if k == kf {
+			b &= 0xff >> (uint(first) & byteModMask)
+		}
+		if k == kl {
+			b &= 0xff << (byteModMask - uint(last)&byteModMask)
+		}
+		if b != 0 {
+			return k<<byteShift + bits.LeadingZeros8(b)
+		}
+	}
+	return -1
+}
+
+// redisMissing returns the result of BITPOS for a key which does not exist.
+func redisMissing(bit bool) int {
+	if bit {
+		return -1
+	}
+	return 0
+}
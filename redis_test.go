@@ -0,0 +1,147 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset_test
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/jrick/bitset"
+)
+
+// Where possible, the expected results of these tests are taken from the
+// examples of the Redis command documentation.
+
+func TestRedisBitCount(t *testing.T) {
+	r := RedisBitmap("foobar")
+	if got := r.BitCount(); got != 26 {
+		t.Errorf("BitCount: got %d expected 26", got)
+	}
+	tests := []struct {
+		start, end int
+		unit       RedisUnit
+		exp        int
+	}{
+		{0, 0, RedisByte, 4},
+		{1, 1, RedisByte, 6},
+		{5, 30, RedisBit, 17},
+		{0, -1, RedisByte, 26},
+		{-2, -1, RedisByte, 7},
+		{-100, 100, RedisByte, 26},
+		{3, 2, RedisByte, 0},
+		{47, 47, RedisBit, 0},
+		{46, 46, RedisBit, 1},
+		{40, 1000, RedisBit, 4},
+	}
+	for _, test := range tests {
+		got := r.BitCountRange(test.start, test.end, test.unit)
+		if got != test.exp {
+			t.Errorf("BitCountRange(%d, %d, %d): got %d expected %d",
+				test.start, test.end, test.unit, got, test.exp)
+		}
+	}
+}
+
+func TestRedisBitPos(t *testing.T) {
+	tests := []struct {
+		value string
+		bit   bool
+		args  []int // start, end (if given), unit (if end given)
+		exp   int
+	}{
+		{"\xff\xf0\x00", false, nil, 12},
+		{"\x00\xff\xf0", true, []int{0}, 8},
+		{"\x00\xff\xf0", true, []int{2}, 16},
+		{"\x00\xff\xf0", true, []int{2, -1, int(RedisByte)}, 16},
+		{"\x00\xff\xf0", true, []int{7, 15, int(RedisBit)}, 8},
+		{"\x00\x00\x00", true, nil, -1},
+		{"\x00\x00\x00", true, []int{7, -3, int(RedisBit)}, -1},
+		{"\xff\xff\xff", false, nil, 24},
+		{"\xff\xff\xff", false, []int{1}, 24},
+		{"\xff\xff\xff", false, []int{0, -1, int(RedisByte)}, -1},
+		{"\xff\xff\xfe", false, []int{0, -1, int(RedisByte)}, 23},
+		{"\xff\xff\xfe", false, []int{0, 22, int(RedisBit)}, -1},
+		{"\xff\x7f", false, []int{9, 9, int(RedisBit)}, -1},
+		{"\xff\x7f", false, []int{8, 9, int(RedisBit)}, 8},
+		{"", true, nil, -1},
+		{"", false, nil, 0},
+		{"", false, []int{5, 10, int(RedisBit)}, 0},
+		{"\x01", true, []int{5}, -1},
+	}
+	for testNum, test := range tests {
+		r := RedisBitmap(test.value)
+		var got int
+		switch len(test.args) {
+		case 0:
+			got = r.BitPos(test.bit)
+		case 1:
+			got = r.BitPosFrom(test.bit, test.args[0], RedisByte)
+		default:
+			got = r.BitPosRange(test.bit, test.args[0], test.args[1],
+				RedisUnit(test.args[2]))
+		}
+		if got != test.exp {
+			t.Errorf("Test %d: got %d expected %d", testNum, got, test.exp)
+		}
+	}
+}
+
+func TestRedisSetBit(t *testing.T) {
+	var r RedisBitmap
+	if old := r.SetBit(7, true); old {
+		t.Errorf("SetBit: got previous value true")
+	}
+	if !bytes.Equal(r, []byte{0x01}) {
+		t.Errorf("got %x expected 01", []byte(r))
+	}
+	if r.GetBit(0) || !r.GetBit(7) || r.GetBit(100) {
+		t.Errorf("GetBit: unexpected values")
+	}
+	if old := r.SetBit(7, false); !old {
+		t.Errorf("SetBit: got previous value false")
+	}
+	r.SetBit(17, true)
+	if !bytes.Equal(r, []byte{0, 0, 0x40}) {
+		t.Errorf("got %x expected 000040", []byte(r))
+	}
+
+	// Offsets are shared with the Bytes layout.
+	s := r.Bytes()
+	if !s.Get(17) || s.Count() != 1 {
+		t.Errorf("Bytes: got %08b", []byte(s))
+	}
+	if !bytes.Equal(NewRedisBitmap(s), r) {
+		t.Errorf("NewRedisBitmap: got %x expected %x", NewRedisBitmap(s), r)
+	}
+}
+
+func TestRedisBitOp(t *testing.T) {
+	a, b := RedisBitmap("foobar"), RedisBitmap("abcdef")
+	tests := []struct {
+		op   RedisOp
+		srcs []RedisBitmap
+		exp  string
+	}{
+		{RedisAnd, []RedisBitmap{a, b}, "`bc`ab"},
+		{RedisOr, []RedisBitmap{a, b}, "goofev"},
+		{RedisXor, []RedisBitmap{a, b}, "\x07\x0d\x0c\x06\x04\x14"},
+		{RedisNot, []RedisBitmap{RedisBitmap("\x0f\xf0")}, "\xf0\x0f"},
+		{RedisAnd, []RedisBitmap{a, RedisBitmap("ab")}, "`b\x00\x00\x00\x00"},
+		{RedisOr, []RedisBitmap{RedisBitmap("a"), a}, "goobar"},
+	}
+	for testNum, test := range tests {
+		got := RedisBitOp(test.op, test.srcs...)
+		if string(got) != test.exp {
+			t.Errorf("Test %d: got %q expected %q", testNum, got, test.exp)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic for NOT with two sources")
+		}
+	}()
+	RedisBitOp(RedisNot, a, b)
+}
@@ -0,0 +1,194 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+import (
+	"encoding/binary"
+	"errors"
+	"iter"
+	"math"
+	"math/bits"
+)
+
+// ErrInvalidRice describes an error where a Golomb-Rice coded bitset could
+// not be decoded because it was truncated or otherwise malformed.
+var ErrInvalidRice = errors.New("bitset: invalid Golomb-Rice encoding")
+
+// maxRiceParameter is the largest supported Rice parameter.
+const maxRiceParameter = 62
+
+// The Golomb-Rice encoding of a bitset records the gaps between its set bits,
+// and is much smaller than the Bytes layout for sets with a low density of
+// set bits.  It is encoded as:
+//
+//	byte     Rice parameter, k
+//	uvarint  number of set bits, n
+//	bits     n Rice codes, packed least significant bit first and padded
+//	         with zero bits to a whole byte
+//
+// The first code holds the index of the lowest set bit, and each following
+// code holds the distance from the previous set bit less one.  A value v is
+// coded as v>>k in unary (that many one bits followed by a zero bit), then
+// the low k bits of v.  The best choice of k depends on the density of the
+// set and is returned by RiceParameter.
+
+// RiceParameter returns the Rice parameter which best encodes a set of count
+// set bits spread randomly among numBits bits.
+func RiceParameter(numBits, count int) int {
+	if count <= 0 || numBits <= count {
+		return 0
+	}
+	// The optimal Golomb divisor for geometrically distributed gaps is
+	// approximately the mean gap scaled by ln 2.  The Rice parameter is
+	// the log of the largest power of two not exceeding it.
+	m := uint64(float64(numBits) / float64(count) * math.Ln2)
+	if m == 0 {
+		return 0
+	}
+	return min(bits.Len64(m)-1, maxRiceParameter)
+}
+
+// AppendRice appends the Golomb-Rice encoding of the set bits yielded by ones
+// using Rice parameter k, and returns the extended slice.  The indexes
+// yielded by ones must be nonnegative and strictly increasing, as they are
+// from the Ones method of every bitset in this package, and ones is iterated
+// twice.  This function will panic if k is negative or greater than 62.
+func AppendRice(dst []byte, ones iter.Seq[int], k int) []byte {
+	if k < 0 || k > maxRiceParameter {
+		panic("bitset: Rice parameter out of range")
+	}
+	n := 0
+	for range ones {
+		n++
+	}
+	dst = append(dst, byte(k))
+	dst = binary.AppendUvarint(dst, uint64(n))
+	w := bitWriter{buf: dst}
+	next := 0
+	for i := range ones {
+		v := uint64(i - next)
+		w.writeUnary(v >> uint(k))
+		w.writeBits(v, uint(k))
+		next = i + 1
+	}
+	return w.buf
+}
+
+// DecodeRice returns the indexes, in increasing order, of the set bits of a
+// bitset encoded by AppendRice.  The result may be passed to any of the
+// New*FromIndices functions.  If the encoding is malformed, ErrInvalidRice
+// is returned.
+func DecodeRice(data []byte) ([]int, error) {
+	if len(data) == 0 || data[0] > maxRiceParameter {
+		return nil, ErrInvalidRice
+	}
+	k := uint(data[0])
+	n, r := binary.Uvarint(data[1:])
+	if r <= 0 {
+		return nil, ErrInvalidRice
+	}
+	br := bitReader{data: data[1+r:]}
+	// Each code requires at least k+1 bits, which bounds the allocation
+	// made for a hostile count.
+	if n > uint64(len(br.data))*8/uint64(k+1) {
+		return nil, ErrInvalidRice
+	}
+	indices := make([]int, n)
+	next := uint64(0)
+	for j := range indices {
+		q, ok := br.readUnary()
+		if !ok || q > uint64(maxInt)>>k {
+			return nil, ErrInvalidRice
+		}
+		low, ok := br.readBits(k)
+		if !ok {
+			return nil, ErrInvalidRice
+		}
+		v := q<<k | low
+		if v > uint64(maxInt)-next {
+			return nil, ErrInvalidRice
+		}
+		indices[j] = int(next + v)
+		next += v + 1
+	}
+	// The encoding must end with the final code's byte, and its padding
+	// must be zero.
+	if (br.pos+7)/8 != uint64(len(br.data)) {
+		return nil, ErrInvalidRice
+	}
+	if pad := br.pos & 7; pad != 0 && br.data[len(br.data)-1]>>pad != 0 {
+		return nil, ErrInvalidRice
+	}
+	return indices, nil
+}
+
+// bitWriter appends bits to a byte slice, least significant bit first.
+type bitWriter struct {
+	buf  []byte
+	free uint // unused bits of the final byte of buf
+}
+
+// writeBits writes the low n bits of v.
+func (w *bitWriter) writeBits(v uint64, n uint) {
+	for n != 0 {
+		if w.free == 0 {
+			w.buf = append(w.buf, 0)
+			w.free = 8
+		}
+		c := min(n, w.free)
+		w.buf[len(w.buf)-1] |= byte(v&(1<<c-1)) << (8 - w.free)
+		v >>= c
+		n -= c
+		w.free -= c
+	}
+}
+
+// writeUnary writes q one bits followed by a zero bit.
+func (w *bitWriter) writeUnary(q uint64) {
+	for ; q >= 32; q -= 32 {
+		w.writeBits(1<<32-1, 32)
+	}
+	w.writeBits(1<<q-1, uint(q)+1)
+}
+
+// bitReader reads bits from a byte slice, least significant bit first.
+type bitReader struct {
+	data []byte
+	pos  uint64 // index of the next bit to read
+}
+
+// readBits reads n bits, returning false if fewer than n bits remain.
+func (r *bitReader) readBits(n uint) (uint64, bool) {
+	if uint64(n) > uint64(len(r.data))*8-r.pos {
+		return 0, false
+	}
+	var v uint64
+	for read := uint(0); read < n; {
+		off := uint(r.pos & 7)
+		c := min(n-read, 8-off)
+		b := uint64(r.data[r.pos>>3]>>off) & (1<<c - 1)
+		v |= b << read
+		read += c
+		r.pos += uint64(c)
+	}
+	return v, true
+}
+
+// readUnary reads one bits up to and including the next zero bit, returning
+// the number of one bits, or false if no zero bit remains.
+func (r *bitReader) readUnary() (uint64, bool) {
+	var q uint64
+	for r.pos>>3 < uint64(len(r.data)) {
+		off := uint(r.pos & 7)
+		ones := uint(bits.TrailingZeros8(^(r.data[r.pos>>3] >> off)))
+		if ones < 8-off {
+			r.pos += uint64(ones) + 1
+			return q + uint64(ones), true
+		}
+		q += uint64(8 - off)
+		r.pos += uint64(8 - off)
+	}
+	return 0, false
+}
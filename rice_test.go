@@ -0,0 +1,125 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset_test
+
+import (
+	"bytes"
+	"math/rand"
+	"slices"
+	"testing"
+
+	. "github.com/jrick/bitset"
+)
+
+func TestRice(t *testing.T) {
+	tests := []struct {
+		set []int
+		k   int
+		exp []byte
+	}{
+		{set: nil, k: 0, exp: []byte{0, 0}},
+		// Codes 0, 0, 2: "0" "0" "110".
+		{set: []int{0, 1, 4}, k: 0, exp: []byte{0, 3, 0x0c}},
+		// Codes 5, 0 with k=2: "10" "10", then "0" "00".
+		{set: []int{5, 6}, k: 2, exp: []byte{2, 2, 0x05}},
+		{set: []int{100}, k: 0, exp: append([]byte{0, 1},
+			0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+			0xff, 0xff, 0x0f)},
+		{set: []int{0, 1 << 30}, k: 30, exp: []byte{30, 2,
+			0, 0, 0, 0, 0xff, 0xff, 0xff, 0x3f}},
+	}
+	for testNum, test := range tests {
+		s := NewSparseFromIndices(test.set)
+		data := AppendRice(nil, s.Ones(), test.k)
+		if !bytes.Equal(data, test.exp) {
+			t.Errorf("Test %d: got encoding %x expected %x", testNum, data, test.exp)
+		}
+		got, err := DecodeRice(data)
+		if err != nil {
+			t.Errorf("Test %d: DecodeRice: %v", testNum, err)
+			continue
+		}
+		if !slices.Equal(got, test.set) {
+			t.Errorf("Test %d: decoded %v expected %v", testNum, got, test.set)
+		}
+	}
+}
+
+func TestRiceDensity(t *testing.T) {
+	const numBits = 1 << 20
+	rng := rand.New(rand.NewSource(1))
+	b := NewBytes(numBits)
+	for b.Count() < numBits/1000 {
+		b.Set(rng.Intn(numBits))
+	}
+
+	k := RiceParameter(numBits, b.Count())
+	data := AppendRice(nil, b.Ones(), k)
+	if len(data)*10 > len(b) {
+		t.Errorf("encoded %d bytes with k=%d, packed form is %d bytes",
+			len(data), k, len(b))
+	}
+	// No other parameter should do much better.
+	for other := 0; other <= 20; other++ {
+		if n := len(AppendRice(nil, b.Ones(), other)); n*20 < len(data)*19 {
+			t.Errorf("k=%d encoded %d bytes but chosen k=%d encoded %d",
+				other, n, k, len(data))
+		}
+	}
+
+	indices, err := DecodeRice(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !NewBytesFromIndices(indices).Equal(b) {
+		t.Errorf("round trip mismatch")
+	}
+}
+
+func TestRiceParameter(t *testing.T) {
+	tests := []struct {
+		numBits, count, exp int
+	}{
+		{numBits: 0, count: 0, exp: 0},
+		{numBits: 100, count: 100, exp: 0},
+		{numBits: 100, count: 50, exp: 0},
+		{numBits: 1000, count: 1, exp: 9},
+		{numBits: 1 << 20, count: 1 << 10, exp: 9},
+	}
+	for _, test := range tests {
+		if got := RiceParameter(test.numBits, test.count); got != test.exp {
+			t.Errorf("RiceParameter(%d, %d): got %d expected %d",
+				test.numBits, test.count, got, test.exp)
+		}
+	}
+}
+
+func TestRiceInvalid(t *testing.T) {
+	tests := [][]byte{
+		nil,
+		{63, 0},                           // parameter too large
+		{0},                               // missing count
+		{0, 1},                            // missing code
+		{0, 1, 0xff},                      // unterminated unary
+		{4, 1, 0x1f},                      // truncated low bits
+		{3, 3, 0x00},                      // count too large for data
+		{0, 1, 0x00, 0},                   // trailing byte
+		{0, 1, 0x02},                      // nonzero padding
+		{0, 0x80, 0x80, 0x80, 0x80, 0x10}, // hostile count
+	}
+	for testNum, data := range tests {
+		if _, err := DecodeRice(data); err != ErrInvalidRice {
+			t.Errorf("Test %d: got error %v expected %v", testNum, err,
+				ErrInvalidRice)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("AppendRice: expected panic for parameter 63")
+		}
+	}()
+	AppendRice(nil, Bytes{1}.Ones(), 63)
+}
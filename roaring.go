@@ -0,0 +1,313 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+import (
+	"encoding/binary"
+	"errors"
+	"iter"
+	"math/bits"
+)
+
+// ErrInvalidRoaring describes an error where a Roaring bitmap could not be
+// decoded because it was truncated or otherwise malformed.
+var ErrInvalidRoaring = errors.New("bitset: invalid Roaring bitmap")
+
+// The Roaring portable serialization format is shared by the C, Java, and Go
+// Roaring bitmap implementations.  A Roaring bitmap holds 32-bit values, and
+// is divided into containers of the values sharing their high 16 bits (the
+// container key).  Each container is stored in one of three forms:
+//
+//   - An array container holds up to 4096 values as sorted little endian
+//     uint16s of their low 16 bits.
+//   - A bitmap container holds more than 4096 values as 1024 little endian
+//     uint64s, which is the layout of a Bytes bitset of 65536 bits.
+//   - A run container holds a little endian uint16 count of runs, followed by
+//     the start and length less one of each run as uint16s.
+//
+// The containers are preceded by a header recording the key and cardinality
+// of each, which container forms are runs, and the offset of each container.
+// See https://github.com/RoaringBitmap/RoaringFormatSpec for details.
+//
+// Containers are written in whichever form is smallest.  As with the other
+// implementations, run containers are only written when smaller than the
+// alternatives, and bitmaps without run containers use the header format
+// understood by readers which predate run containers.
+
+const (
+	roaringCookieNoRun     = 12346
+	roaringCookie          = 12347
+	roaringOffsetThreshold = 4
+	roaringArrayMax        = 4096
+	roaringBitmapSize      = 8192
+)
+
+// AppendRoaring appends the Roaring portable serialization of the set bits
+// yielded by ones to dst and returns the extended slice.  The indexes yielded
+// by ones must be strictly increasing, as they are from the Ones method of
+// every bitset in this package.  If any index does not fit in a uint32,
+// ErrIndexOutOfRange is returned.
+func AppendRoaring(dst []byte, ones iter.Seq[int]) ([]byte, error) {
+	type container struct {
+		key  uint16
+		card int
+		run  bool
+		body []byte
+	}
+	var cs []container
+	var lows []uint16
+	key := -1
+	flush := func() {
+		if len(lows) != 0 {
+			body, run := appendRoaringContainer(nil, lows)
+			cs = append(cs, container{uint16(key), len(lows), run, body})
+			lows = lows[:0]
+		}
+	}
+	for i := range ones {
+		if uint64(i) >= 1<<32 {
+			return nil, ErrIndexOutOfRange
+		}
+		if i>>16 != key {
+			flush()
+			key = i >> 16
+		}
+		lows = append(lows, uint16(i))
+	}
+	flush()
+	hasRun := false
+	for _, c := range cs {
+		hasRun = hasRun || c.run
+	}
+
+	start := len(dst)
+	hasOffsets := true
+	if hasRun {
+		dst = binary.LittleEndian.AppendUint32(dst, roaringCookie|uint32(len(cs)-1)<<16)
+		runs := make([]byte, (len(cs)+7)/8)
+		for i, c := range cs {
+			if c.run {
+				runs[i>>3] |= 1 << (uint(i) & 7)
+			}
+		}
+		dst = append(dst, runs...)
+		hasOffsets = len(cs) >= roaringOffsetThreshold
+	} else {
+		dst = binary.LittleEndian.AppendUint32(dst, roaringCookieNoRun)
+		dst = binary.LittleEndian.AppendUint32(dst, uint32(len(cs)))
+	}
+	for _, c := range cs {
+		dst = binary.LittleEndian.AppendUint16(dst, c.key)
+		dst = binary.LittleEndian.AppendUint16(dst, uint16(c.card-1))
+	}
+	if hasOffsets {
+		off := len(dst) - start + 4*len(cs)
+		for _, c := range cs {
+			dst = binary.LittleEndian.AppendUint32(dst, uint32(off))
+			off += len(c.body)
+		}
+	}
+	for _, c := range cs {
+		dst = append(dst, c.body...)
+	}
+	return dst, nil
+}
+
+// appendRoaringContainer appends the smallest encoding of a container
+// holding the sorted values lows, and returns the extended slice and whether
+// the encoding is a run container.
+func appendRoaringContainer(dst []byte, lows []uint16) ([]byte, bool) {
+	numRuns := 1
+	for j := 1; j < len(lows); j++ {
+		if lows[j] != lows[j-1]+1 {
+			numRuns++
+		}
+	}
+	size := roaringBitmapSize
+	if len(lows) <= roaringArrayMax {
+		size = 2 * len(lows)
+	}
+	switch {
+	case 2+4*numRuns < size:
+		dst = binary.LittleEndian.AppendUint16(dst, uint16(numRuns))
+		for j := 0; j < len(lows); {
+			k := j + 1
+			for k < len(lows) && lows[k] == lows[k-1]+1 {
+				k++
+			}
+			dst = binary.LittleEndian.AppendUint16(dst, lows[j])
+			dst = binary.LittleEndian.AppendUint16(dst, uint16(k-j-1))
+			j = k
+		}
+		return dst, true
+	case len(lows) <= roaringArrayMax:
+		for _, v := range lows {
+			dst = binary.LittleEndian.AppendUint16(dst, v)
+		}
+		return dst, false
+	default:
+		bitmap := make(Bytes, roaringBitmapSize)
+		for _, v := range lows {
+			bitmap.Set(int(v))
+		}
+		return append(dst, bitmap...), false
+	}
+}
+
+// DecodeRoaring validates a Roaring bitmap in the portable serialization
+// format and returns an iterator over its values in increasing order.  The
+// iterator reads from data, which must not be modified while it is in use.
+// To decode into a bitset, set each value yielded, or collect the values and
+// pass them to any of the New*FromIndices functions.  If the encoding is
+// malformed, ErrInvalidRoaring is returned.  On machines with 32-bit ints,
+// values which do not fit in an int result in ErrIndexOutOfRange.
+func DecodeRoaring(data []byte) (iter.Seq[int], error) {
+	if err := walkRoaring(data, nil); err != nil {
+		return nil, err
+	}
+	return func(yield func(int) bool) {
+		walkRoaring(data, yield)
+	}, nil
+}
+
+// walkRoaring validates a Roaring bitmap, calling fn, if not nil, with each
+// of its values in increasing order.  If fn returns false, walkRoaring
+// returns nil immediately without validating the remainder of data.
+func walkRoaring(data []byte, fn func(int) bool) error {
+	le := binary.LittleEndian
+	if len(data) < 4 {
+		return ErrInvalidRoaring
+	}
+	cookie := le.Uint32(data)
+	var size int
+	var runs []byte
+	p := 4
+	switch {
+	case cookie&0xffff == roaringCookie:
+		size = int(cookie>>16) + 1
+		n := (size + 7) / 8
+		if len(data)-p < n {
+			return ErrInvalidRoaring
+		}
+		runs = data[p : p+n]
+		p += n
+	case cookie == roaringCookieNoRun:
+		if len(data) < 8 {
+			return ErrInvalidRoaring
+		}
+		// Keys are distinct uint16s, which bounds the number of
+		// containers.
+		n := le.Uint32(data[4:])
+		if n > 1<<16 {
+			return ErrInvalidRoaring
+		}
+		size, p = int(n), 8
+	default:
+		return ErrInvalidRoaring
+	}
+	if len(data)-p < 4*size {
+		return ErrInvalidRoaring
+	}
+	desc := data[p : p+4*size]
+	p += 4 * size
+	var offsets []byte
+	if runs == nil || size >= roaringOffsetThreshold {
+		if len(data)-p < 4*size {
+			return ErrInvalidRoaring
+		}
+		offsets = data[p : p+4*size]
+		p += 4 * size
+	}
+
+	prevKey := -1
+	for i := 0; i < size; i++ {
+		key := int(le.Uint16(desc[4*i:]))
+		card := int(le.Uint16(desc[4*i+2:])) + 1
+		if key <= prevKey {
+			return ErrInvalidRoaring
+		}
+		prevKey = key
+		if offsets != nil && le.Uint32(offsets[4*i:]) != uint32(p) {
+			return ErrInvalidRoaring
+		}
+		// Every value of the container must be representable.
+		if uint64(key)<<16|0xffff > uint64(maxInt) {
+			return ErrIndexOutOfRange
+		}
+		base := key << 16
+
+		switch {
+		case runs != nil && runs[i>>3]&(1<<(uint(i)&7)) != 0:
+			if len(data)-p < 2 {
+				return ErrInvalidRoaring
+			}
+			numRuns := int(le.Uint16(data[p:]))
+			p += 2
+			if len(data)-p < 4*numRuns {
+				return ErrInvalidRoaring
+			}
+			n, next := 0, 0
+			for j := 0; j < numRuns; j++ {
+				start := int(le.Uint16(data[p:]))
+				length := int(le.Uint16(data[p+2:])) + 1
+				p += 4
+				if start < next || start+length > 1<<16 {
+					return ErrInvalidRoaring
+				}
+				next = start + length
+				n += length
+				for v := start; fn != nil && v < next; v++ {
+					if !fn(base + v) {
+						return nil
+					}
+				}
+			}
+			if n != card {
+				return ErrInvalidRoaring
+			}
+		case card <= roaringArrayMax:
+			if len(data)-p < 2*card {
+				return ErrInvalidRoaring
+			}
+			prev := -1
+			for j := 0; j < card; j++ {
+				v := int(le.Uint16(data[p:]))
+				p += 2
+				if v <= prev {
+					return ErrInvalidRoaring
+				}
+				prev = v
+				if fn != nil && !fn(base+v) {
+					return nil
+				}
+			}
+		default:
+			if len(data)-p < roaringBitmapSize {
+				return ErrInvalidRoaring
+			}
+			bitmap := Bytes(data[p : p+roaringBitmapSize])
+			p += roaringBitmapSize
+			n := 0
+			for _, b := range bitmap {
+				n += bits.OnesCount8(b)
+			}
+			if n != card {
+				return ErrInvalidRoaring
+			}
+			for v := range bitmap.Ones() {
+				if fn == nil {
+					break
+				}
+				if !fn(base + v) {
+					return nil
+				}
+			}
+		}
+	}
+	if p != len(data) {
+		return ErrInvalidRoaring
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"slices"
+	"strconv"
+	"testing"
+
+	. "github.com/jrick/bitset"
+)
+
+func TestRoaringEncoding(t *testing.T) {
+	tests := []struct {
+		name string
+		set  []int
+		exp  []byte
+	}{
+		{"empty", nil, []byte{0x3a, 0x30, 0, 0, 0, 0, 0, 0}},
+		{"array", []int{1, 2, 3}, []byte{
+			0x3a, 0x30, 0, 0, // cookie without run containers
+			1, 0, 0, 0, // one container
+			0, 0, 2, 0, // key 0, cardinality 3
+			16, 0, 0, 0, // offset
+			1, 0, 2, 0, 3, 0,
+		}},
+		{"run", seq(0, 100), []byte{
+			0x3b, 0x30, 0, 0, // cookie, one container
+			0x01,        // container 0 is a run container
+			0, 0, 99, 0, // key 0, cardinality 100
+			1, 0, 0, 0, 99, 0, // one run of length 100 from 0
+		}},
+		{"high key", []int{1<<31 - 1}, []byte{
+			0x3a, 0x30, 0, 0,
+			1, 0, 0, 0,
+			0xff, 0x7f, 0, 0,
+			16, 0, 0, 0,
+			0xff, 0xff,
+		}},
+	}
+	for _, test := range tests {
+		got, err := AppendRoaring(nil, NewSparseFromIndices(test.set).Ones())
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if !bytes.Equal(got, test.exp) {
+			t.Errorf("%s: got encoding %x expected %x", test.name, got, test.exp)
+		}
+		ones, err := DecodeRoaring(test.exp)
+		if err != nil {
+			t.Fatalf("%s: DecodeRoaring: %v", test.name, err)
+		}
+		if decoded := slices.Collect(ones); !slices.Equal(decoded, test.set) {
+			t.Errorf("%s: decoded %v expected %v", test.name, decoded, test.set)
+		}
+	}
+}
+
+func TestRoaringRoundTrip(t *testing.T) {
+	// Containers of every form, including enough containers to require
+	// offsets alongside run containers.
+	var set []int
+	set = append(set, 5, 100, 60000)             // array
+	set = append(set, seq(1<<16, 1<<16+5000)...) // run
+	for i := 2 << 16; i < 3<<16; i += 3 {        // bitmap
+		set = append(set, i)
+	}
+	set = append(set, 4<<16, 5<<16+1, 1<<30+7) // arrays
+	s := NewSparseFromIndices(set)
+
+	data, err := AppendRoaring([]byte("prefix"), s.Ones())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ones, err := DecodeRoaring(data[len("prefix"):])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := slices.Collect(ones); !slices.Equal(got, set) {
+		t.Errorf("round trip mismatch: got %d values expected %d", len(got), len(set))
+	}
+
+	// Iteration may stop early.
+	n := 0
+	for range ones {
+		if n++; n == 10 {
+			break
+		}
+	}
+
+	// Values must fit in a uint32, which only limits 64-bit ints.
+	if strconv.IntSize == 64 {
+		big := int(^uint(0)>>31) + 1
+		if _, err := AppendRoaring(nil, NewSparseFromIndices([]int{big}).Ones()); err != ErrIndexOutOfRange {
+			t.Errorf("got error %v expected %v", err, ErrIndexOutOfRange)
+		}
+	}
+}
+
+func TestRoaringInvalid(t *testing.T) {
+	valid, _ := AppendRoaring(nil, NewSparseFromIndices([]int{1, 2, 3}).Ones())
+	run, _ := AppendRoaring(nil, NewSparseFromIndices(seq(0, 100)).Ones())
+	modify := func(data []byte, fn func([]byte)) []byte {
+		data = slices.Clone(data)
+		fn(data)
+		return data
+	}
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"bad cookie", modify(valid, func(b []byte) { b[0] = 0 })},
+		{"truncated", valid[:len(valid)-1]},
+		{"trailing", append(slices.Clone(valid), 0)},
+		{"bad offset", modify(valid, func(b []byte) { b[12] = 17 })},
+		{"unsorted array", modify(valid, func(b []byte) { b[16] = 9 })},
+		{"cardinality", modify(run, func(b []byte) { b[7] = 98 })},
+		{"run overflow", modify(run, func(b []byte) { b[11], b[12] = 0xff, 0xff })},
+		{"too many containers", binary.LittleEndian.AppendUint32(
+			[]byte{0x3a, 0x30, 0, 0}, 1<<16+1)},
+	}
+	for _, test := range tests {
+		if _, err := DecodeRoaring(test.data); err != ErrInvalidRoaring {
+			t.Errorf("%s: got error %v expected %v", test.name, err, ErrInvalidRoaring)
+		}
+	}
+}
@@ -0,0 +1,314 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+import "math/bits"
+
+// NextSet returns the index of the first set bit at or after index i, or -1
+// if there is no such bit.  Pointers with no set bits are skipped whole.
+func (p Pointers) NextSet(i int) int {
+	if i < 0 {
+		i = 0
+	}
+	w := int(uint(i) >> ptrShift)
+	if w >= len(p) {
+		return -1
+	}
+	if ptr := p[w] >> (uint(i) & ptrModMask); ptr != 0 {
+		return i + bits.TrailingZeros(uint(ptr))
+	}
+	for w++; w < len(p); w++ {
+		if p[w] != 0 {
+			return w<<ptrShift + bits.TrailingZeros(uint(p[w]))
+		}
+	}
+	return -1
+}
+
+// NextSet returns the index of the first set bit at or after index i, or -1
+// if there is no such bit.  Bytes with no set bits are skipped whole.
+func (s Bytes) NextSet(i int) int {
+	if i < 0 {
+		i = 0
+	}
+	b := int(uint(i) >> byteShift)
+	if b >= len(s) {
+		return -1
+	}
+	if v := s[b] >> (uint(i) & byteModMask); v != 0 {
+		return i + bits.TrailingZeros8(v)
+	}
+	for b++; b < len(s); b++ {
+		if s[b] != 0 {
+			return b<<byteShift + bits.TrailingZeros8(s[b])
+		}
+	}
+	return -1
+}
+
+// NextSet returns the index of the first set bit at or after index i, or -1
+// if there is no such bit.  As map iteration is unordered, finding the next
+// pointer beyond the one holding bit i requires visiting every pointer in
+// the map.
+func (s Sparse) NextSet(i int) int {
+	if i < 0 {
+		i = 0
+	}
+	key := int(uint(i) >> ptrShift)
+	if ptr := s[key] >> (uint(i) & ptrModMask); ptr != 0 {
+		return i + bits.TrailingZeros(uint(ptr))
+	}
+	next := -1
+	for k, ptr := range s {
+		if k > key && ptr != 0 && (next == -1 || k < next) {
+			next = k
+		}
+	}
+	if next == -1 {
+		return -1
+	}
+	return next<<ptrShift + bits.TrailingZeros(uint(s[next]))
+}
+
+// NextClear returns the index of the first unset bit at or after index i, or
+// -1 if every bit from i through the end of the bitset is set.  Pointers with
+// every bit set are skipped whole.
+func (p Pointers) NextClear(i int) int {
+	if i < 0 {
+		i = 0
+	}
+	w := int(uint(i) >> ptrShift)
+	if w >= len(p) {
+		return -1
+	}
+	if ptr := ^p[w] >> (uint(i) & ptrModMask); ptr != 0 {
+		return i + bits.TrailingZeros(uint(ptr))
+	}
+	for w++; w < len(p); w++ {
+		if p[w] != ^uintptr(0) {
+			return w<<ptrShift + bits.TrailingZeros(uint(^p[w]))
+		}
+	}
+	return -1
+}
+
+// NextClear returns the index of the first unset bit at or after index i, or
+// -1 if every bit from i through the end of the bitset is set.  Bytes with
+// every bit set are skipped whole.
+func (s Bytes) NextClear(i int) int {
+	if i < 0 {
+		i = 0
+	}
+	b := int(uint(i) >> byteShift)
+	if b >= len(s) {
+		return -1
+	}
+	if v := ^s[b] >> (uint(i) & byteModMask); v != 0 {
+		return i + bits.TrailingZeros8(v)
+	}
+	for b++; b < len(s); b++ {
+		if s[b] != 0xff {
+			return b<<byteShift + bits.TrailingZeros8(^s[b])
+		}
+	}
+	return -1
+}
+
+// NextClear returns the index of the first unset bit at or after index i.
+// As a Sparse bitset is unbounded, an unset bit always exists.
+func (s Sparse) NextClear(i int) int {
+	if i < 0 {
+		i = 0
+	}
+	key := int(uint(i) >> ptrShift)
+	if ptr := ^s[key] >> (uint(i) & ptrModMask); ptr != 0 {
+		return i + bits.TrailingZeros(uint(ptr))
+	}
+	for key++; s[key] == ^uintptr(0); key++ {
+	}
+	return key<<ptrShift + bits.TrailingZeros(uint(^s[key]))
+}
+
+// PrevSet returns the index of the last set bit at or before index i, or -1
+// if there is no such bit.  Pointers with no set bits are skipped whole.
+func (p Pointers) PrevSet(i int) int {
+	if i < 0 || len(p) == 0 {
+		return -1
+	}
+	w := int(uint(i) >> ptrShift)
+	if w >= len(p) {
+		w = len(p) - 1
+		i = len(p)<<ptrShift - 1
+	}
+	if ptr := p[w] << (ptrModMask - uint(i)&ptrModMask); ptr != 0 {
+		return i - bits.LeadingZeros(uint(ptr))
+	}
+	for w--; w >= 0; w-- {
+		if p[w] != 0 {
+			return w<<ptrShift + ptrModMask - bits.LeadingZeros(uint(p[w]))
+		}
+	}
+	return -1
+}
+
+// PrevClear returns the index of the last unset bit at or before index i, or
+// -1 if every bit from the start of the bitset through i is set.  Pointers
+// with every bit set are skipped whole.
+func (p Pointers) PrevClear(i int) int {
+	if i < 0 || len(p) == 0 {
+		return -1
+	}
+	w := int(uint(i) >> ptrShift)
+	if w >= len(p) {
+		w = len(p) - 1
+		i = len(p)<<ptrShift - 1
+	}
+	if ptr := ^p[w] << (ptrModMask - uint(i)&ptrModMask); ptr != 0 {
+		return i - bits.LeadingZeros(uint(ptr))
+	}
+	for w--; w >= 0; w-- {
+		if p[w] != ^uintptr(0) {
+			return w<<ptrShift + ptrModMask - bits.LeadingZeros(uint(^p[w]))
+		}
+	}
+	return -1
+}
+
+// PrevSet returns the index of the last set bit at or before index i, or -1
+// if there is no such bit.  Bytes with no set bits are skipped whole.
+func (s Bytes) PrevSet(i int) int {
+	if i < 0 || len(s) == 0 {
+		return -1
+	}
+	b := int(uint(i) >> byteShift)
+	if b >= len(s) {
+		b = len(s) - 1
+		i = len(s)<<byteShift - 1
+	}
+	if v := s[b] << (byteModMask - uint(i)&byteModMask); v != 0 {
+		return i - bits.LeadingZeros8(v)
+	}
+	for b--; b >= 0; b-- {
+		if s[b] != 0 {
+			return b<<byteShift + byteModMask - bits.LeadingZeros8(s[b])
+		}
+	}
+	return -1
+}
+
+// PrevClear returns the index of the last unset bit at or before index i, or
+// -1 if every bit from the start of the bitset through i is set.  Bytes with
+// every bit set are skipped whole.
+func (s Bytes) PrevClear(i int) int {
+	if i < 0 || len(s) == 0 {
+		return -1
+	}
+	b := int(uint(i) >> byteShift)
+	if b >= len(s) {
+		b = len(s) - 1
+		i = len(s)<<byteShift - 1
+	}
+	if v := ^s[b] << (byteModMask - uint(i)&byteModMask); v != 0 {
+		return i - bits.LeadingZeros8(v)
+	}
+	for b--; b >= 0; b-- {
+		if s[b] != 0xff {
+			return b<<byteShift + byteModMask - bits.LeadingZeros8(^s[b])
+		}
+	}
+	return -1
+}
+
+// PrevSet returns the index of the last set bit at or before index i, or -1
+// if there is no such bit.  As map iteration is unordered, finding the
+// previous pointer before the one holding bit i requires visiting every
+// pointer in the map.
+func (s Sparse) PrevSet(i int) int {
+	if i < 0 {
+		return -1
+	}
+	key := int(uint(i) >> ptrShift)
+	if ptr := s[key] << (ptrModMask - uint(i)&ptrModMask); ptr != 0 {
+		return i - bits.LeadingZeros(uint(ptr))
+	}
+	prev := -1
+	for k, ptr := range s {
+		if k < key && ptr != 0 && k > prev {
+			prev = k
+		}
+	}
+	if prev == -1 {
+		return -1
+	}
+	return prev<<ptrShift + ptrModMask - bits.LeadingZeros(uint(s[prev]))
+}
+
+// PrevClear returns the index of the last unset bit at or before index i, or
+// -1 if every bit from zero through i is set.
+func (s Sparse) PrevClear(i int) int {
+	if i < 0 {
+		return -1
+	}
+	key := int(uint(i) >> ptrShift)
+	if ptr := ^s[key] << (ptrModMask - uint(i)&ptrModMask); ptr != 0 {
+		return i - bits.LeadingZeros(uint(ptr))
+	}
+	for key--; key >= 0; key-- {
+		if s[key] != ^uintptr(0) {
+			return key<<ptrShift + ptrModMask - bits.LeadingZeros(uint(^s[key]))
+		}
+	}
+	return -1
+}
+
+// First returns the index of the lowest set bit, or -1 if no bits are set.
+func (p Pointers) First() int {
+	return p.NextSet(0)
+}
+
+// Last returns the index of the highest set bit, or -1 if no bits are set.
+func (p Pointers) Last() int {
+	return p.PrevSet(len(p)<<ptrShift - 1)
+}
+
+// First returns the index of the lowest set bit, or -1 if no bits are set.
+func (s Bytes) First() int {
+	return s.NextSet(0)
+}
+
+// Last returns the index of the highest set bit, or -1 if no bits are set.
+func (s Bytes) Last() int {
+	return s.PrevSet(len(s)<<byteShift - 1)
+}
+
+// First returns the index of the lowest set bit, or -1 if no bits are set.
+// This requires visiting every pointer in the map.
+func (s Sparse) First() int {
+	first := -1
+	for k, ptr := range s {
+		if ptr != 0 && (first == -1 || k < first) {
+			first = k
+		}
+	}
+	if first == -1 {
+		return -1
+	}
+	return first<<ptrShift + bits.TrailingZeros(uint(s[first]))
+}
+
+// Last returns the index of the highest set bit, or -1 if no bits are set.
+// This requires visiting every pointer in the map.
+func (s Sparse) Last() int {
+	last := -1
+	for k, ptr := range s {
+		if ptr != 0 && k > last {
+			last = k
+		}
+	}
+	if last == -1 {
+		return -1
+	}
+	return last<<ptrShift + ptrModMask - bits.LeadingZeros(uint(s[last]))
+}
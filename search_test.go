@@ -0,0 +1,191 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset_test
+
+import (
+	"testing"
+
+	. "github.com/jrick/bitset"
+)
+
+type searcher interface {
+	BitSet
+	NextSet(i int) int
+}
+
+func searchers(numBits int) []struct {
+	name string
+	bs   searcher
+} {
+	return []struct {
+		name string
+		bs   searcher
+	}{
+		{"Pointers", NewPointers(numBits)},
+		{"Bytes", NewBytes(numBits)},
+		{"Sparse", make(Sparse)},
+	}
+}
+
+func TestNextSet(t *testing.T) {
+	const numBits = 300
+	sets := [][]int{
+		nil,
+		{0},
+		{299},
+		{0, 1, 7, 8, 63, 64, 65, 200, 299},
+		{150},
+	}
+	for setNum, set := range sets {
+		for _, c := range searchers(numBits) {
+			for _, i := range set {
+				c.bs.Set(i)
+			}
+			for i := -1; i <= numBits+1; i++ {
+				exp := -1
+				for _, j := range set {
+					if j >= i {
+						exp = j
+						break
+					}
+				}
+				if got := c.bs.NextSet(i); got != exp {
+					t.Errorf("Set %d bitset %s: NextSet(%d) got %d expected %d",
+						setNum, c.name, i, got, exp)
+				}
+			}
+		}
+	}
+}
+
+func TestNextClear(t *testing.T) {
+	const numBits = 256
+	sets := [][]int{
+		nil,
+		seq(0, 256),
+		seq(0, 255),
+		seq(1, 256),
+		append(seq(0, 100), seq(101, 256)...),
+		append(seq(0, 64), seq(128, 256)...),
+	}
+	for setNum, set := range sets {
+		for _, c := range searchers(numBits) {
+			bs := c.bs.(interface {
+				searcher
+				NextClear(i int) int
+			})
+			for _, i := range set {
+				bs.Set(i)
+			}
+			for i := -1; i <= numBits+1; i++ {
+				exp := -1
+				for j := i; j < numBits; j++ {
+					if j >= 0 && !bs.Get(j) {
+						exp = j
+						break
+					}
+				}
+				if exp == -1 && c.name == "Sparse" {
+					// Sparse bitsets are unbounded.
+					exp = numBits
+					if i > exp {
+						exp = i
+					}
+				}
+				if got := bs.NextClear(i); got != exp {
+					t.Errorf("Set %d bitset %s: NextClear(%d) got %d expected %d",
+						setNum, c.name, i, got, exp)
+				}
+			}
+		}
+	}
+}
+
+func TestPrevSetClear(t *testing.T) {
+	const numBits = 256
+	sets := [][]int{
+		nil,
+		{0},
+		{255},
+		{0, 1, 7, 8, 63, 64, 65, 200, 255},
+		seq(0, 256),
+		append(seq(0, 64), seq(128, 256)...),
+		seq(1, 256),
+	}
+	type prevSearcher interface {
+		BitSet
+		PrevSet(i int) int
+		PrevClear(i int) int
+	}
+	for setNum, set := range sets {
+		for _, c := range searchers(numBits) {
+			bs := c.bs.(prevSearcher)
+			for _, i := range set {
+				bs.Set(i)
+			}
+			for i := -1; i <= numBits+1; i++ {
+				expSet, expClear := -1, -1
+				for j := i; j >= 0; j-- {
+					if j >= numBits {
+						// Only Sparse bitsets hold bits
+						// beyond numBits.
+						if expClear == -1 && c.name == "Sparse" {
+							expClear = j
+						}
+						continue
+					}
+					if expSet == -1 && bs.Get(j) {
+						expSet = j
+					}
+					if expClear == -1 && !bs.Get(j) {
+						expClear = j
+					}
+				}
+				if got := bs.PrevSet(i); got != expSet {
+					t.Errorf("Set %d bitset %s: PrevSet(%d) got %d expected %d",
+						setNum, c.name, i, got, expSet)
+				}
+				if got := bs.PrevClear(i); got != expClear {
+					t.Errorf("Set %d bitset %s: PrevClear(%d) got %d expected %d",
+						setNum, c.name, i, got, expClear)
+				}
+			}
+		}
+	}
+}
+
+func TestFirstLast(t *testing.T) {
+	tests := []struct {
+		numBits     int
+		set         []int
+		first, last int
+	}{
+		{numBits: 0, set: nil, first: -1, last: -1},
+		{numBits: 64, set: nil, first: -1, last: -1},
+		{numBits: 64, set: []int{5}, first: 5, last: 5},
+		{numBits: 200, set: []int{70, 3, 199, 64}, first: 3, last: 199},
+		{numBits: 1024, set: []int{1023, 512}, first: 512, last: 1023},
+	}
+	for testNum, test := range tests {
+		for _, c := range searchers(test.numBits) {
+			bs := c.bs.(interface {
+				BitSet
+				First() int
+				Last() int
+			})
+			for _, i := range test.set {
+				bs.Set(i)
+			}
+			if got := bs.First(); got != test.first {
+				t.Errorf("Test %d bitset %s: First got %d expected %d",
+					testNum, c.name, got, test.first)
+			}
+			if got := bs.Last(); got != test.last {
+				t.Errorf("Test %d bitset %s: Last got %d expected %d",
+					testNum, c.name, got, test.last)
+			}
+		}
+	}
+}
@@ -0,0 +1,391 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+import "encoding/binary"
+
+// And sets p to the intersection of p and other, operating a pointer at a
+// time.  Pointers of p beyond the length of other are treated as being
+// intersected with zero and are cleared.
+func (p Pointers) And(other Pointers) {
+	n := min(len(p), len(other))
+	for i, ptr := range other[:n] {
+		p[i] &= ptr
+	}
+	clear(p[n:])
+}
+
+// Or sets p to the union of p and other, operating a pointer at a time.  As
+// p is not grown, any bits of other which lie beyond the length of p are not
+// merged; Grow p to at least the length of other beforehand if those bits
+// must be kept.
+func (p Pointers) Or(other Pointers) {
+	n := min(len(p), len(other))
+	for i, ptr := range other[:n] {
+		p[i] |= ptr
+	}
+}
+
+// Xor sets p to the symmetric difference of p and other, operating a pointer
+// at a time.  As p is not grown, any bits of other which lie beyond the
+// length of p are not considered.
+func (p Pointers) Xor(other Pointers) {
+	n := min(len(p), len(other))
+	for i, ptr := range other[:n] {
+		p[i] ^= ptr
+	}
+}
+
+// AndNot removes every bit set in other from p, operating a pointer at a
+// time.  Pointers of p beyond the length of other are left unchanged.
+func (p Pointers) AndNot(other Pointers) {
+	n := min(len(p), len(other))
+	for i, ptr := range other[:n] {
+		p[i] &^= ptr
+	}
+}
+
+// OrGrow sets p to the union of p and other, first growing p if necessary so
+// that no bits of other are dropped.
+func (p *Pointers) OrGrow(other Pointers) {
+	p.Grow(len(other) << ptrShift)
+	p.Or(other)
+}
+
+// XorGrow sets p to the symmetric difference of p and other, first growing p
+// if necessary so that no bits of other are dropped.
+func (p *Pointers) XorGrow(other Pointers) {
+	p.Grow(len(other) << ptrShift)
+	p.Xor(other)
+}
+
+// AndTruncate sets p to the intersection of p and other, and truncates p to
+// the length of other if it is the shorter of the two.  Unlike And, pointers
+// beyond the length of other are dropped rather than cleared.
+func (p *Pointers) AndTruncate(other Pointers) {
+	*p = (*p)[:min(len(*p), len(other))]
+	p.And(other)
+}
+
+// Apply sets each pointer of p to the result of calling fn with that pointer
+// and the pointer of other at the same index.  It allows operations not
+// otherwise provided by this package, such as NAND, to be performed a
+// pointer at a time.  Pointers of other beyond the length of p are ignored,
+// and fn is called with a zero src for pointers of p beyond the length of
+// other.
+func (p Pointers) Apply(other Pointers, fn func(dst, src uintptr) uintptr) {
+	n := min(len(p), len(other))
+	for i, ptr := range other[:n] {
+		p[i] = fn(p[i], ptr)
+	}
+	for i := n; i < len(p); i++ {
+		p[i] = fn(p[i], 0)
+	}
+}
+
+// The set operations of Bytes process eight bytes at a time by loading each
+// chunk as a little endian uint64.  The encoding/binary loads and stores
+// compile to single unaligned memory accesses on architectures which permit
+// them.  Trailing bytes which do not fill a whole chunk are processed
+// individually.
+
+// And sets s to the intersection of s and other.  Bytes of s beyond the
+// length of other are treated as being intersected with zero and are
+// cleared.
+func (s Bytes) And(other Bytes) {
+	n := min(len(s), len(other))
+	i := 0
+	for ; i+8 <= n; i += 8 {
+		v := binary.LittleEndian.Uint64(s[i:]) & binary.LittleEndian.Uint64(other[i:])
+		binary.LittleEndian.PutUint64(s[i:], v)
+	}
+	for ; i < n; i++ {
+		s[i] &= other[i]
+	}
+	clear(s[n:])
+}
+
+// Or sets s to the union of s and other.  As s is not grown, any bits of
+// other which lie beyond the length of s are not merged; Grow s to at least
+// the length of other beforehand if those bits must be kept.
+func (s Bytes) Or(other Bytes) {
+	n := min(len(s), len(other))
+	i := 0
+	for ; i+8 <= n; i += 8 {
+		v := binary.LittleEndian.Uint64(s[i:]) | binary.LittleEndian.Uint64(other[i:])
+		binary.LittleEndian.PutUint64(s[i:], v)
+	}
+	for ; i < n; i++ {
+		s[i] |= other[i]
+	}
+}
+
+// Xor sets s to the symmetric difference of s and other.  As s is not grown,
+// any bits of other which lie beyond the length of s are not considered.
+func (s Bytes) Xor(other Bytes) {
+	n := min(len(s), len(other))
+	i := 0
+	for ; i+8 <= n; i += 8 {
+		v := binary.LittleEndian.Uint64(s[i:]) ^ binary.LittleEndian.Uint64(other[i:])
+		binary.LittleEndian.PutUint64(s[i:], v)
+	}
+	for ; i < n; i++ {
+		s[i] ^= other[i]
+	}
+}
+
+// AndNot removes every bit set in other from s.  Bytes of s beyond the
+// length of other are left unchanged.
+func (s Bytes) AndNot(other Bytes) {
+	n := min(len(s), len(other))
+	i := 0
+	for ; i+8 <= n; i += 8 {
+		v := binary.LittleEndian.Uint64(s[i:]) &^ binary.LittleEndian.Uint64(other[i:])
+		binary.LittleEndian.PutUint64(s[i:], v)
+	}
+	for ; i < n; i++ {
+		s[i] &^= other[i]
+	}
+}
+
+// OrGrow sets s to the union of s and other, first growing s if necessary so
+// that no bits of other are dropped.
+func (s *Bytes) OrGrow(other Bytes) {
+	s.Grow(len(other) << byteShift)
+	s.Or(other)
+}
+
+// XorGrow sets s to the symmetric difference of s and other, first growing s
+// if necessary so that no bits of other are dropped.
+func (s *Bytes) XorGrow(other Bytes) {
+	s.Grow(len(other) << byteShift)
+	s.Xor(other)
+}
+
+// AndTruncate sets s to the intersection of s and other, and truncates s to
+// the length of other if it is the shorter of the two.  Unlike And, bytes
+// beyond the length of other are dropped rather than cleared.
+func (s *Bytes) AndTruncate(other Bytes) {
+	*s = (*s)[:min(len(*s), len(other))]
+	s.And(other)
+}
+
+// Apply sets each byte of s to the result of calling fn with that byte and
+// the byte of other at the same index.  It allows operations not otherwise
+// provided by this package, such as NAND, to be performed a byte at a time.
+// Bytes of other beyond the length of s are ignored, and fn is called with a
+// zero src for bytes of s beyond the length of other.
+func (s Bytes) Apply(other Bytes, fn func(dst, src byte) byte) {
+	n := min(len(s), len(other))
+	for i, b := range other[:n] {
+		s[i] = fn(s[i], b)
+	}
+	for i := n; i < len(s); i++ {
+		s[i] = fn(s[i], 0)
+	}
+}
+
+// Union sets s to the union of s and other, operating a map pointer at a
+// time.
+func (s Sparse) Union(other Sparse) {
+	for k, ptr := range other {
+		if ptr != 0 {
+			s[k] |= ptr
+		}
+	}
+}
+
+// Intersect sets s to the intersection of s and other, operating a map
+// pointer at a time.  Pointers which no longer contain any set bits are
+// removed from s.
+func (s Sparse) Intersect(other Sparse) {
+	for k, ptr := range s {
+		ptr &= other[k]
+		if ptr == 0 {
+			delete(s, k)
+		} else {
+			s[k] = ptr
+		}
+	}
+}
+
+// Difference removes every bit set in other from s, operating a map pointer
+// at a time.  Pointers which no longer contain any set bits are removed from
+// s.  Only the smaller of the two maps is iterated.
+func (s Sparse) Difference(other Sparse) {
+	if len(s) <= len(other) {
+		for k, ptr := range s {
+			s.andNotKey(k, ptr, other[k])
+		}
+		return
+	}
+	for k, o := range other {
+		if ptr, ok := s[k]; ok {
+			s.andNotKey(k, ptr, o)
+		}
+	}
+}
+
+// andNotKey stores ptr&^o at key k of s, removing the key if no bits remain.
+func (s Sparse) andNotKey(k int, ptr, o uintptr) {
+	ptr &^= o
+	if ptr == 0 {
+		delete(s, k)
+	} else {
+		s[k] = ptr
+	}
+}
+
+// UnionAll stores the union of all sets into p and returns the result.  Each
+// pointer of the result is computed by combining the corresponding pointer
+// of every set at once, so the inputs are passed over only a single time
+// regardless of how many there are.  The result has the length of the
+// longest set.  Like append, the memory of p is reused if it has sufficient
+// capacity, otherwise a new bitset is allocated, and a nil p may be used to
+// always allocate.  p may also be one of the sets.
+func (p Pointers) UnionAll(sets ...Pointers) Pointers {
+	n := 0
+	for _, set := range sets {
+		n = max(n, len(set))
+	}
+	p = p.resize(n)
+	for i := range p {
+		var ptr uintptr
+		for _, set := range sets {
+			if i < len(set) {
+				ptr |= set[i]
+			}
+		}
+		p[i] = ptr
+	}
+	return p
+}
+
+// IntersectAll stores the intersection of all sets into p and returns the
+// result.  Each pointer of the result is computed by combining the
+// corresponding pointer of every set at once.  The result has the length of
+// the shortest set, or zero if there are no sets.  The memory of p is reused
+// following the same rules as UnionAll.
+func (p Pointers) IntersectAll(sets ...Pointers) Pointers {
+	n := 0
+	for i, set := range sets {
+		if i == 0 || len(set) < n {
+			n = len(set)
+		}
+	}
+	p = p.resize(n)
+	for i := range p {
+		ptr := ^uintptr(0)
+		for _, set := range sets {
+			ptr &= set[i]
+		}
+		p[i] = ptr
+	}
+	return p
+}
+
+// resize returns p resliced to n pointers if it has the capacity, or a newly
+// allocated bitset of n pointers otherwise.  The contents of the result are
+// not cleared.
+func (p Pointers) resize(n int) Pointers {
+	if n <= cap(p) {
+		return p[:n]
+	}
+	return make(Pointers, n)
+}
+
+// UnionAll stores the union of all sets into s and returns the result.  Each
+// byte of the result is computed by combining the corresponding byte of every
+// set at once, so the inputs are passed over only a single time regardless of
+// how many there are.  The result has the length of the longest set.  Like
+// append, the memory of s is reused if it has sufficient capacity, otherwise
+// a new bitset is allocated, and a nil s may be used to always allocate.  s
+// may also be one of the sets.
+func (s Bytes) UnionAll(sets ...Bytes) Bytes {
+	n := 0
+	for _, set := range sets {
+		n = max(n, len(set))
+	}
+	s = s.resize(n)
+	for i := range s {
+		var b byte
+		for _, set := range sets {
+			if i < len(set) {
+				b |= set[i]
+			}
+		}
+		s[i] = b
+	}
+	return s
+}
+
+// IntersectAll stores the intersection of all sets into s and returns the
+// result.  Each byte of the result is computed by combining the
+// corresponding byte of every set at once.  The result has the length of the
+// shortest set, or zero if there are no sets.  The memory of s is reused
+// following the same rules as UnionAll.
+func (s Bytes) IntersectAll(sets ...Bytes) Bytes {
+	n := 0
+	for i, set := range sets {
+		if i == 0 || len(set) < n {
+			n = len(set)
+		}
+	}
+	s = s.resize(n)
+	for i := range s {
+		b := byte(0xff)
+		for _, set := range sets {
+			b &= set[i]
+		}
+		s[i] = b
+	}
+	return s
+}
+
+// resize returns s resliced to n bytes if it has the capacity, or a newly
+// allocated bitset of n bytes otherwise.  The contents of the result are not
+// cleared.
+func (s Bytes) resize(n int) Bytes {
+	if n <= cap(s) {
+		return s[:n]
+	}
+	return make(Bytes, n)
+}
+
+// Complement inverts every bit of p in the range [0, numBits).  Bits at or
+// beyond numBits are cleared rather than inverted, so the result holds
+// exactly those bits below numBits which were previously unset.  This method
+// will panic if numBits results in a pointer index that exceeds the number of
+// pointers held by the bitset.
+func (p Pointers) Complement(numBits int) {
+	full := uint(numBits) >> ptrShift
+	for i := range p[:full] {
+		p[i] = ^p[i]
+	}
+	tail := p[full:]
+	if rem := uint(numBits) & ptrModMask; rem != 0 {
+		tail[0] = ^tail[0] & (1<<rem - 1)
+		tail = tail[1:]
+	}
+	clear(tail)
+}
+
+// Complement inverts every bit of s in the range [0, numBits).  Bits at or
+// beyond numBits are cleared rather than inverted, so the result holds
+// exactly those bits below numBits which were previously unset.  This method
+// will panic if numBits results in a byte index that exceeds the number of
+// bytes held by the bitset.
+func (s Bytes) Complement(numBits int) {
+	full := uint(numBits) >> byteShift
+	for i := range s[:full] {
+		s[i] = ^s[i]
+	}
+	tail := s[full:]
+	if rem := uint(numBits) & byteModMask; rem != 0 {
+		tail[0] = ^tail[0] & (1<<rem - 1)
+		tail = tail[1:]
+	}
+	clear(tail)
+}
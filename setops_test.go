@@ -0,0 +1,305 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset_test
+
+import (
+	"reflect"
+	"testing"
+
+	. "github.com/jrick/bitset"
+)
+
+var setOpTests = []struct {
+	a, b   []int
+	aBits  int
+	bBits  int
+	and    []int
+	or     []int
+	xor    []int
+	andNot []int
+}{
+	{
+		aBits: 128, bBits: 128,
+		a: nil, b: nil,
+		and: nil, or: nil, xor: nil, andNot: nil,
+	},
+	{
+		aBits: 128, bBits: 128,
+		a:      []int{0, 1, 64, 100},
+		b:      []int{1, 2, 100, 127},
+		and:    []int{1, 100},
+		or:     []int{0, 1, 2, 64, 100, 127},
+		xor:    []int{0, 2, 64, 127},
+		andNot: []int{0, 64},
+	},
+	{
+		// Lengths which are not a multiple of eight bytes.
+		aBits: 200, bBits: 200,
+		a:      []int{0, 63, 64, 130, 190, 199},
+		b:      []int{0, 64, 131, 199},
+		and:    []int{0, 64, 199},
+		or:     []int{0, 63, 64, 130, 131, 190, 199},
+		xor:    []int{63, 130, 131, 190},
+		andNot: []int{63, 130, 190},
+	},
+	{
+		// b shorter than a.
+		aBits: 256, bBits: 64,
+		a:      []int{3, 63, 64, 200},
+		b:      []int{3, 4},
+		and:    []int{3},
+		or:     []int{3, 4, 63, 64, 200},
+		xor:    []int{4, 63, 64, 200},
+		andNot: []int{63, 64, 200},
+	},
+}
+
+// testSetOp runs all setOpTests through the in-place operations pf and bf
+// of Pointers and Bytes, comparing the resulting set bits to those selected
+// by exp.  A nil bf skips Bytes bitsets.
+func testSetOp(t *testing.T, op string, exp func(testNum int) []int,
+	pf func(a, b Pointers), bf func(a, b Bytes)) {
+
+	for testNum, test := range setOpTests {
+		pa := NewPointersFromIndices(test.a)
+		pa.Grow(test.aBits)
+		pb := NewPointersFromIndices(test.b)
+		pb.Grow(test.bBits)
+		pf(pa, pb)
+		if got := pa.ToSlice(); !equalIndices(got, exp(testNum)) {
+			t.Errorf("%s test %d bitset Pointers: got %v expected %v",
+				op, testNum, got, exp(testNum))
+		}
+
+		if bf == nil {
+			continue
+		}
+		ba := NewBytesFromIndices(test.a)
+		ba.Grow(test.aBits)
+		bb := NewBytesFromIndices(test.b)
+		bb.Grow(test.bBits)
+		bf(ba, bb)
+		if got := ba.ToSlice(); !equalIndices(got, exp(testNum)) {
+			t.Errorf("%s test %d bitset Bytes: got %v expected %v",
+				op, testNum, got, exp(testNum))
+		}
+	}
+}
+
+func TestAnd(t *testing.T) {
+	testSetOp(t, "And", func(i int) []int { return setOpTests[i].and },
+		Pointers.And, Bytes.And)
+}
+
+func TestOr(t *testing.T) {
+	testSetOp(t, "Or", func(i int) []int { return setOpTests[i].or },
+		Pointers.Or, Bytes.Or)
+
+	// Bits of the operand beyond the length of the receiver are dropped.
+	a := NewPointersFromIndices([]int{1})
+	a.Or(NewPointersFromIndices([]int{2, 1000}))
+	if got, exp := a.ToSlice(), []int{1, 2}; !equalIndices(got, exp) {
+		t.Errorf("Short receiver: got %v expected %v", got, exp)
+	}
+}
+
+func TestXor(t *testing.T) {
+	testSetOp(t, "Xor", func(i int) []int { return setOpTests[i].xor },
+		Pointers.Xor, Bytes.Xor)
+}
+
+// equalIndices returns whether two index slices are equal, treating nil and
+// empty slices as equal.
+func equalIndices(a, b []int) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func TestAndNot(t *testing.T) {
+	testSetOp(t, "AndNot", func(i int) []int { return setOpTests[i].andNot },
+		Pointers.AndNot, Bytes.AndNot)
+}
+
+func TestApply(t *testing.T) {
+	testSetOp(t, "Apply", func(i int) []int { return setOpTests[i].xor },
+		func(a, b Pointers) {
+			a.Apply(b, func(dst, src uintptr) uintptr { return dst ^ src })
+		},
+		func(a, b Bytes) {
+			a.Apply(b, func(dst, src byte) byte { return dst ^ src })
+		})
+
+	// NAND sets every bit of the receiver beyond the operand.
+	p := NewPointersFromIndices([]int{0, 1})
+	p.Grow(128)
+	p.Apply(NewPointersFromIndices([]int{1}), func(dst, src uintptr) uintptr {
+		return ^(dst & src)
+	})
+	if got, exp := p.Count(), p.Cap()-1; got != exp || p.Get(1) {
+		t.Errorf("Pointers NAND: got count %d expected %d", got, exp)
+	}
+	b := NewBytesFromIndices([]int{0, 1})
+	b.Grow(128)
+	b.Apply(NewBytesFromIndices([]int{1}), func(dst, src byte) byte {
+		return ^(dst & src)
+	})
+	if got := b.Count(); got != 127 || b.Get(1) {
+		t.Errorf("Bytes NAND: got count %d expected 127", got)
+	}
+}
+
+func TestSparseSetOps(t *testing.T) {
+	ops := []struct {
+		name string
+		fn   func(a, b Sparse)
+		exp  func(testNum int) []int
+	}{
+		{"Union", Sparse.Union, func(i int) []int { return setOpTests[i].or }},
+		{"Intersect", Sparse.Intersect, func(i int) []int { return setOpTests[i].and }},
+		{"Difference", Sparse.Difference, func(i int) []int { return setOpTests[i].andNot }},
+	}
+	for _, op := range ops {
+		for testNum, test := range setOpTests {
+			a := NewSparseFromIndices(test.a)
+			b := NewSparseFromIndices(test.b)
+			op.fn(a, b)
+			if got := a.ToSlice(); !equalIndices(got, op.exp(testNum)) {
+				t.Errorf("%s test %d: got %v expected %v", op.name,
+					testNum, got, op.exp(testNum))
+			}
+			for k, ptr := range a {
+				if ptr == 0 {
+					t.Errorf("%s test %d: zero pointer left at key %d",
+						op.name, testNum, k)
+				}
+			}
+
+			// Also check with the operands swapped in size so
+			// both iteration strategies are exercised.
+			a = NewSparseFromIndices(test.a)
+			b = NewSparseFromIndices(append(seq(1000, 2000), test.b...))
+			op.fn(a, b)
+			if op.name == "Difference" {
+				if got := a.ToSlice(); !equalIndices(got, op.exp(testNum)) {
+					t.Errorf("%s test %d (large operand): got %v expected %v",
+						op.name, testNum, got, op.exp(testNum))
+				}
+			}
+		}
+	}
+}
+
+func TestUnionIntersectAll(t *testing.T) {
+	sets := [][]int{
+		{0, 1, 2, 64, 100, 300},
+		{1, 2, 3, 64, 100},
+		{2, 64, 100, 101, 200},
+	}
+	union := []int{0, 1, 2, 3, 64, 100, 101, 200, 300}
+	intersect := []int{2, 64, 100}
+
+	var ps []Pointers
+	var bs []Bytes
+	for _, set := range sets {
+		ps = append(ps, NewPointersFromIndices(set))
+		bs = append(bs, NewBytesFromIndices(set))
+	}
+
+	if got := Pointers(nil).UnionAll(ps...).ToSlice(); !equalIndices(got, union) {
+		t.Errorf("Pointers UnionAll: got %v expected %v", got, union)
+	}
+	if got := Pointers(nil).IntersectAll(ps...).ToSlice(); !equalIndices(got, intersect) {
+		t.Errorf("Pointers IntersectAll: got %v expected %v", got, intersect)
+	}
+	if got := Bytes(nil).UnionAll(bs...).ToSlice(); !equalIndices(got, union) {
+		t.Errorf("Bytes UnionAll: got %v expected %v", got, union)
+	}
+	if got := Bytes(nil).IntersectAll(bs...).ToSlice(); !equalIndices(got, intersect) {
+		t.Errorf("Bytes IntersectAll: got %v expected %v", got, intersect)
+	}
+
+	// The receiver may be one of the operands.
+	dst := ps[0]
+	if got := dst.IntersectAll(ps...).ToSlice(); !equalIndices(got, intersect) {
+		t.Errorf("Pointers aliased IntersectAll: got %v expected %v", got, intersect)
+	}
+	if got := Pointers(nil).UnionAll(); len(got) != 0 {
+		t.Errorf("Pointers UnionAll with no sets: got len %d", len(got))
+	}
+}
+
+func TestComplement(t *testing.T) {
+	tests := []struct {
+		capBits int
+		numBits int
+		set     []int
+		exp     []int
+	}{
+		{capBits: 0, numBits: 0, set: nil, exp: nil},
+		{capBits: 8, numBits: 3, set: []int{1, 5}, exp: []int{0, 2}},
+		{capBits: 64, numBits: 64, set: seq(1, 64), exp: []int{0}},
+		{capBits: 200, numBits: 70, set: append(seq(0, 68), 150), exp: []int{68, 69}},
+		{capBits: 128, numBits: 128, set: nil, exp: seq(0, 128)},
+	}
+	for testNum, test := range tests {
+		p := NewPointers(test.capBits)
+		b := NewBytes(test.capBits)
+		for _, i := range test.set {
+			p.Set(i)
+			b.Set(i)
+		}
+		p.Complement(test.numBits)
+		b.Complement(test.numBits)
+		if got := p.ToSlice(); !equalIndices(got, test.exp) {
+			t.Errorf("Test %d bitset Pointers: got %v expected %v",
+				testNum, got, test.exp)
+		}
+		if got := b.ToSlice(); !equalIndices(got, test.exp) {
+			t.Errorf("Test %d bitset Bytes: got %v expected %v",
+				testNum, got, test.exp)
+		}
+	}
+}
+
+func TestLengthMismatch(t *testing.T) {
+	short := []int{1, 5}
+	long := []int{5, 300}
+
+	p := NewPointersFromIndices(short)
+	p.OrGrow(NewPointersFromIndices(long))
+	if got, exp := p.ToSlice(), []int{1, 5, 300}; !equalIndices(got, exp) {
+		t.Errorf("Pointers OrGrow: got %v expected %v", got, exp)
+	}
+	p = NewPointersFromIndices(short)
+	p.XorGrow(NewPointersFromIndices(long))
+	if got, exp := p.ToSlice(), []int{1, 300}; !equalIndices(got, exp) {
+		t.Errorf("Pointers XorGrow: got %v expected %v", got, exp)
+	}
+	p = NewPointersFromIndices(long)
+	p.AndTruncate(NewPointersFromIndices(short))
+	if got, exp := p.ToSlice(), []int{5}; !equalIndices(got, exp) || len(p) != 1 {
+		t.Errorf("Pointers AndTruncate: got %v (len %d) expected %v",
+			got, len(p), exp)
+	}
+
+	b := NewBytesFromIndices(short)
+	b.OrGrow(NewBytesFromIndices(long))
+	if got, exp := b.ToSlice(), []int{1, 5, 300}; !equalIndices(got, exp) {
+		t.Errorf("Bytes OrGrow: got %v expected %v", got, exp)
+	}
+	b = NewBytesFromIndices(short)
+	b.XorGrow(NewBytesFromIndices(long))
+	if got, exp := b.ToSlice(), []int{1, 300}; !equalIndices(got, exp) {
+		t.Errorf("Bytes XorGrow: got %v expected %v", got, exp)
+	}
+	b = NewBytesFromIndices(long)
+	b.AndTruncate(NewBytesFromIndices(short))
+	if got, exp := b.ToSlice(), []int{5}; !equalIndices(got, exp) || len(b) != 1 {
+		t.Errorf("Bytes AndTruncate: got %v (len %d) expected %v",
+			got, len(b), exp)
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"sort"
+)
+
+// ErrInvalidShard describes an error where a shard is malformed, or where a
+// set of shards does not describe exactly one complete bitset.
+var ErrInvalidShard = errors.New("bitset: invalid shard")
+
+// A shard holds a contiguous range of the bits of a larger bitset, and is
+// encoded as:
+//
+//	uvarint   index of the shard's first bit in the complete bitset
+//	uvarint   number of bits in the complete bitset
+//	streamed  the shard's bits, as written by WriteTo
+//
+// Every shard is independently decodable, and a complete set of shards can be
+// reassembled into the streamed encoding of the original bitset.
+
+// Shard is a decoded shard of a larger bitset.
+type Shard struct {
+	// Offset is the index of the shard's first bit in the complete bitset.
+	Offset int
+
+	// TotalBits is the number of bits in the complete bitset.
+	TotalBits int
+
+	// Bits holds the bits of the shard.  Bit i of Bits is bit Offset+i of
+	// the complete bitset.
+	Bits *Dense
+}
+
+// WriteTo writes the shard encoding of s to w.  It implements the
+// io.WriterTo interface.
+func (s *Shard) WriteTo(w io.Writer) (int64, error) {
+	hdr := binary.AppendUvarint(nil, uint64(s.Offset))
+	hdr = binary.AppendUvarint(hdr, uint64(s.TotalBits))
+	n, err := w.Write(hdr)
+	if err != nil {
+		return int64(n), err
+	}
+	m, err := s.Bits.WriteTo(w)
+	return int64(n) + m, err
+}
+
+// ReadFrom replaces the contents of s with a shard read from r.  It
+// implements the io.ReaderFrom interface.  If the shard does not lie within
+// the complete bitset it describes, ErrInvalidShard is returned.  On error, s
+// is not modified.
+func (s *Shard) ReadFrom(r io.Reader) (int64, error) {
+	cr := &countingReader{r: r}
+	offset, total, err := readShardHeader(cr)
+	if err != nil {
+		return cr.n, err
+	}
+	var d Dense
+	if _, err := d.ReadFrom(cr); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return cr.n, err
+	}
+	if d.n > total-offset {
+		return cr.n, ErrInvalidShard
+	}
+	*s = Shard{Offset: offset, TotalBits: total, Bits: &d}
+	return cr.n, nil
+}
+
+// Split reads a streamed bitset from r and writes it as shards of shardBits
+// bits each, except for the final shard which may be shorter.  For each
+// shard, create is called with the offset of the shard's first bit to obtain
+// the writer it is written to.  A bitset with no bits is written as a single
+// empty shard.  The bitset is read in bounded chunks, so memory use is
+// independent of both the bitset and shard sizes.  This function will panic
+// if shardBits is not a positive multiple of 8.
+func Split(r io.Reader, shardBits int, create func(offset int) (io.Writer, error)) error {
+	if shardBits <= 0 || shardBits&byteModMask != 0 {
+		panic("bitset: shard size must be a positive multiple of 8")
+	}
+	src, err := newStreamSource(r)
+	if err != nil {
+		return err
+	}
+	buf := make(Bytes, min(src.size, streamChunk))
+	offset := 0
+	for {
+		w, err := create(offset)
+		if err != nil {
+			return err
+		}
+		numBits := min(shardBits, src.numBits-offset)
+		hdr := binary.AppendUvarint(nil, uint64(offset))
+		hdr = binary.AppendUvarint(hdr, uint64(src.numBits))
+		hdr = binary.AppendUvarint(hdr, uint64(numBits))
+		if _, err := w.Write(hdr); err != nil {
+			return err
+		}
+		for size := (numBits + byteModMask) >> byteShift; size != 0; {
+			chunk := buf[:min(size, len(buf))]
+			if err := src.fill(chunk); err != nil {
+				return err
+			}
+			if _, err := w.Write(chunk); err != nil {
+				return err
+			}
+			size -= len(chunk)
+		}
+		offset += numBits
+		if offset == src.numBits {
+			return nil
+		}
+	}
+}
+
+// Reassemble reads a complete set of shards, in any order, and writes the
+// streamed encoding of the original bitset to w.  The headers of every shard
+// are read before any data, after which each shard is read to its end in
+// order of offset.  If the shards do not exactly cover one bitset, or any
+// shard other than the last does not end on a byte boundary,
+// ErrInvalidShard is returned.  It returns the number of bytes written to w.
+func Reassemble(w io.Writer, shards ...io.Reader) (int64, error) {
+	type part struct {
+		offset int
+		src    *streamSource
+	}
+	parts := make([]part, len(shards))
+	total := -1
+	for i, r := range shards {
+		cr := &countingReader{r: r}
+		offset, t, err := readShardHeader(cr)
+		if err != nil {
+			return 0, err
+		}
+		src, err := newStreamSource(cr)
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return 0, err
+		}
+		if total != -1 && t != total {
+			return 0, ErrInvalidShard
+		}
+		total = t
+		parts[i] = part{offset, src}
+	}
+	if len(parts) == 0 {
+		return 0, ErrInvalidShard
+	}
+	sort.Slice(parts, func(i, j int) bool {
+		return parts[i].offset < parts[j].offset
+	})
+	next := 0
+	for i, p := range parts {
+		if p.offset != next || p.src.numBits > total-next {
+			return 0, ErrInvalidShard
+		}
+		next += p.src.numBits
+		if i != len(parts)-1 && (p.src.numBits == 0 || next&byteModMask != 0) {
+			return 0, ErrInvalidShard
+		}
+	}
+	if next != total {
+		return 0, ErrInvalidShard
+	}
+
+	cw := &countingWriter{w: w}
+	if _, err := cw.Write(binary.AppendUvarint(nil, uint64(total))); err != nil {
+		return cw.n, err
+	}
+	buf := make(Bytes, streamChunk)
+	for _, p := range parts {
+		for p.src.remaining() != 0 {
+			chunk := buf[:min(p.src.remaining(), len(buf))]
+			if err := p.src.fill(chunk); err != nil {
+				return cw.n, err
+			}
+			if _, err := cw.Write(chunk); err != nil {
+				return cw.n, err
+			}
+		}
+	}
+	return cw.n, nil
+}
+
+// readShardHeader reads the offset and total bit length of a shard from cr.
+func readShardHeader(cr *countingReader) (offset, total int, err error) {
+	var v [2]uint64
+	for i := range v {
+		start := cr.n
+		v[i], err = binary.ReadUvarint(cr)
+		if err != nil {
+			switch {
+			case cr.n-start == binary.MaxVarintLen64:
+				err = ErrInvalidShard
+			case i != 0 && err == io.EOF:
+				err = io.ErrUnexpectedEOF
+			}
+			return 0, 0, err
+		}
+	}
+	if v[1] > uint64(maxInt-byteModMask) || v[0] > v[1] {
+		return 0, 0, ErrInvalidShard
+	}
+	return int(v[0]), int(v[1]), nil
+}
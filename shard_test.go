@@ -0,0 +1,136 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	. "github.com/jrick/bitset"
+)
+
+func TestSplitReassemble(t *testing.T) {
+	tests := []struct {
+		numBits   int
+		shardBits int
+		set       []int
+		numShards int
+	}{
+		{numBits: 0, shardBits: 8, numShards: 1},
+		{numBits: 5, shardBits: 8, set: []int{4}, numShards: 1},
+		{numBits: 64, shardBits: 16, set: []int{0, 15, 16, 63}, numShards: 4},
+		{numBits: 100, shardBits: 24, set: []int{23, 24, 99}, numShards: 5},
+		{numBits: 300000, shardBits: 80000, set: []int{1, 79999, 80000, 299999}, numShards: 4},
+	}
+	for testNum, test := range tests {
+		d := NewDense(test.numBits)
+		for _, i := range test.set {
+			d.Set(i)
+		}
+		var enc bytes.Buffer
+		d.WriteTo(&enc)
+
+		var offsets []int
+		var shards []*bytes.Buffer
+		err := Split(bytes.NewReader(enc.Bytes()), test.shardBits, func(offset int) (io.Writer, error) {
+			offsets = append(offsets, offset)
+			shards = append(shards, new(bytes.Buffer))
+			return shards[len(shards)-1], nil
+		})
+		if err != nil {
+			t.Fatalf("Test %d: Split: %v", testNum, err)
+		}
+		if len(shards) != test.numShards {
+			t.Fatalf("Test %d: got %d shards expected %d", testNum,
+				len(shards), test.numShards)
+		}
+
+		// Each shard decodes independently.
+		for k, buf := range shards {
+			var s Shard
+			if _, err := s.ReadFrom(bytes.NewReader(buf.Bytes())); err != nil {
+				t.Fatalf("Test %d shard %d: %v", testNum, k, err)
+			}
+			if s.Offset != offsets[k] || s.Offset != k*test.shardBits ||
+				s.TotalBits != test.numBits {
+				t.Errorf("Test %d shard %d: got offset %d total %d", testNum,
+					k, s.Offset, s.TotalBits)
+			}
+			for i := 0; i < s.Bits.Len(); i++ {
+				if s.Bits.Get(i) != d.Get(s.Offset+i) {
+					t.Errorf("Test %d shard %d: bit %d mismatch", testNum, k, i)
+				}
+			}
+		}
+
+		// Reassemble in reverse order.
+		readers := make([]io.Reader, len(shards))
+		for k, buf := range shards {
+			readers[len(shards)-1-k] = bytes.NewReader(buf.Bytes())
+		}
+		var out bytes.Buffer
+		if _, err := Reassemble(&out, readers...); err != nil {
+			t.Fatalf("Test %d: Reassemble: %v", testNum, err)
+		}
+		if !bytes.Equal(out.Bytes(), enc.Bytes()) {
+			t.Errorf("Test %d: reassembled encoding differs", testNum)
+		}
+	}
+}
+
+func TestReassembleInvalid(t *testing.T) {
+	d := NewDense(40)
+	d.Set(39)
+	var enc bytes.Buffer
+	d.WriteTo(&enc)
+	var shards [][]byte
+	Split(&enc, 16, func(int) (io.Writer, error) {
+		shards = append(shards, nil)
+		return writerFunc(func(p []byte) (int, error) {
+			shards[len(shards)-1] = append(shards[len(shards)-1], p...)
+			return len(p), nil
+		}), nil
+	})
+
+	reassemble := func(parts ...[]byte) error {
+		readers := make([]io.Reader, len(parts))
+		for i, p := range parts {
+			readers[i] = bytes.NewReader(p)
+		}
+		_, err := Reassemble(io.Discard, readers...)
+		return err
+	}
+	mismatched := &Shard{Offset: 16, TotalBits: 48, Bits: NewDense(16)}
+	var mismatchedEnc bytes.Buffer
+	mismatched.WriteTo(&mismatchedEnc)
+
+	tests := []struct {
+		name  string
+		parts [][]byte
+		err   error
+	}{
+		{"none", nil, ErrInvalidShard},
+		{"missing", [][]byte{shards[0], shards[2]}, ErrInvalidShard},
+		{"duplicate", [][]byte{shards[0], shards[1], shards[1], shards[2]}, ErrInvalidShard},
+		{"mismatched total", [][]byte{shards[0], mismatchedEnc.Bytes(), shards[2]}, ErrInvalidShard},
+		{"truncated", [][]byte{shards[0], shards[1], shards[2][:3]}, io.ErrUnexpectedEOF},
+	}
+	for _, test := range tests {
+		if err := reassemble(test.parts...); err != test.err {
+			t.Errorf("%s: got error %v expected %v", test.name, err, test.err)
+		}
+	}
+	if err := reassemble(shards...); err != nil {
+		t.Errorf("complete: unexpected error %v", err)
+	}
+}
+
+// writerFunc is an io.Writer implemented by a function.
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
@@ -0,0 +1,233 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+// ShiftLeft moves every bit of p n positions towards higher indexes, so that
+// the bit at index i moves to index i+n.  The n lowest bits become unset and
+// bits shifted beyond the end of the bitset are discarded.  This method will
+// panic if n is negative.
+func (p Pointers) ShiftLeft(n int) {
+	p.ShiftLeftTo(p, n)
+}
+
+// ShiftLeftTo stores p shifted left by n positions into dst, as described by
+// ShiftLeft.  Bits shifted beyond the end of dst are discarded, and bits of
+// dst which do not receive a shifted bit are unset.  dst may be p itself,
+// but must not otherwise overlap p.  This method will panic if n is negative.
+func (p Pointers) ShiftLeftTo(dst Pointers, n int) {
+	if n < 0 {
+		panic("bitset: negative shift count")
+	}
+	ws := n >> ptrShift
+	bs := uint(n) & ptrModMask
+	for w := len(dst) - 1; w >= 0; w-- {
+		src := w - ws
+		var ptr uintptr
+		if src >= 0 && src < len(p) {
+			ptr = p[src] << bs
+		}
+		if bs != 0 && src >= 1 && src-1 < len(p) {
+			ptr |= p[src-1] >> (ptrBits - bs)
+		}
+		dst[w] = ptr
+	}
+}
+
+// ShiftRight moves every bit of p n positions towards lower indexes, so that
+// the bit at index i moves to index i-n.  The n highest bits become unset and
+// bits shifted below index zero are discarded.  This method will panic if n
+// is negative.
+func (p Pointers) ShiftRight(n int) {
+	p.ShiftRightTo(p, n)
+}
+
+// ShiftRightTo stores p shifted right by n positions into dst, as described
+// by ShiftRight.  Bits of dst which do not receive a shifted bit are unset.
+// dst may be p itself, but must not otherwise overlap p.  This method will
+// panic if n is negative.
+func (p Pointers) ShiftRightTo(dst Pointers, n int) {
+	if n < 0 {
+		panic("bitset: negative shift count")
+	}
+	ws := n >> ptrShift
+	bs := uint(n) & ptrModMask
+	for w := range dst {
+		src := w + ws
+		var ptr uintptr
+		if src >= 0 && src < len(p) {
+			ptr = p[src] >> bs
+		}
+		if bs != 0 && src+1 < len(p) {
+			ptr |= p[src+1] << (ptrBits - bs)
+		}
+		dst[w] = ptr
+	}
+}
+
+// ShiftLeft moves every bit of s n positions towards higher indexes, so that
+// the bit at index i moves to index i+n.  The n lowest bits become unset and
+// bits shifted beyond the end of the bitset are discarded.  This method will
+// panic if n is negative.
+func (s Bytes) ShiftLeft(n int) {
+	s.ShiftLeftTo(s, n)
+}
+
+// ShiftLeftTo stores s shifted left by n positions into dst, as described by
+// ShiftLeft.  Bits shifted beyond the end of dst are discarded, and bits of
+// dst which do not receive a shifted bit are unset.  dst may be s itself,
+// but must not otherwise overlap s.  This method will panic if n is negative.
+func (s Bytes) ShiftLeftTo(dst Bytes, n int) {
+	if n < 0 {
+		panic("bitset: negative shift count")
+	}
+	bs := n >> byteShift
+	sh := uint(n) & byteModMask
+	for i := len(dst) - 1; i >= 0; i-- {
+		src := i - bs
+		var b byte
+		if src >= 0 && src < len(s) {
+			b = s[src] << sh
+		}
+		if sh != 0 && src >= 1 && src-1 < len(s) {
+			b |= s[src-1] >> (8 - sh)
+		}
+		dst[i] = b
+	}
+}
+
+// ShiftRight moves every bit of s n positions towards lower indexes, so that
+// the bit at index i moves to index i-n.  The n highest bits become unset and
+// bits shifted below index zero are discarded.  This method will panic if n
+// is negative.
+func (s Bytes) ShiftRight(n int) {
+	s.ShiftRightTo(s, n)
+}
+
+// ShiftRightTo stores s shifted right by n positions into dst, as described
+// by ShiftRight.  Bits of dst which do not receive a shifted bit are unset.
+// dst may be s itself, but must not otherwise overlap s.  This method will
+// panic if n is negative.
+func (s Bytes) ShiftRightTo(dst Bytes, n int) {
+	if n < 0 {
+		panic("bitset: negative shift count")
+	}
+	bs := n >> byteShift
+	sh := uint(n) & byteModMask
+	for i := range dst {
+		src := i + bs
+		var b byte
+		if src >= 0 && src < len(s) {
+			b = s[src] >> sh
+		}
+		if sh != 0 && src+1 < len(s) {
+			b |= s[src+1] << (8 - sh)
+		}
+		dst[i] = b
+	}
+}
+
+// RotateLeft rotates the bits in the range [0, numBits) of p n positions
+// towards higher indexes, so that the bit at index i moves to index
+// (i+n) mod numBits.  Bits at or beyond numBits are not modified.  A negative
+// n rotates right.  This method will panic if numBits results in a pointer
+// index that exceeds the number of pointers held by the bitset.
+func (p Pointers) RotateLeft(n, numBits int) {
+	if numBits <= 0 {
+		return
+	}
+	n %= numBits
+	if n < 0 {
+		n += numBits
+	}
+	if n == 0 {
+		return
+	}
+	nw := (numBits + ptrModMask) >> ptrShift
+	src := make(Pointers, nw)
+	copy(src, p[:nw])
+	src.clearFrom(numBits)
+	rot := make(Pointers, nw)
+	src.ShiftLeftTo(rot, n)
+	src.ShiftRight(numBits - n)
+	rot.Or(src)
+	rot.clearFrom(numBits)
+	if rem := uint(numBits) & ptrModMask; rem != 0 {
+		rot[nw-1] |= p[nw-1] &^ (1<<rem - 1)
+	}
+	copy(p, rot)
+}
+
+// RotateRight rotates the bits in the range [0, numBits) of p n positions
+// towards lower indexes, so that the bit at index i moves to index
+// (i-n) mod numBits.  Bits at or beyond numBits are not modified.  A negative
+// n rotates left.  This method will panic if numBits results in a pointer
+// index that exceeds the number of pointers held by the bitset.
+func (p Pointers) RotateRight(n, numBits int) {
+	if numBits > 0 {
+		p.RotateLeft(numBits-n%numBits, numBits)
+	}
+}
+
+// clearFrom unsets every bit of p at or beyond index i.
+func (p Pointers) clearFrom(i int) {
+	w := int(uint(i) >> ptrShift)
+	if w >= len(p) {
+		return
+	}
+	p[w] &= 1<<(uint(i)&ptrModMask) - 1
+	clear(p[w+1:])
+}
+
+// RotateLeft rotates the bits in the range [0, numBits) of s n positions
+// towards higher indexes, so that the bit at index i moves to index
+// (i+n) mod numBits.  Bits at or beyond numBits are not modified.  A negative
+// n rotates right.  This method will panic if numBits results in a byte index
+// that exceeds the number of bytes held by the bitset.
+func (s Bytes) RotateLeft(n, numBits int) {
+	if numBits <= 0 {
+		return
+	}
+	n %= numBits
+	if n < 0 {
+		n += numBits
+	}
+	if n == 0 {
+		return
+	}
+	nb := (numBits + byteModMask) >> byteShift
+	src := make(Bytes, nb)
+	copy(src, s[:nb])
+	src.clearFrom(numBits)
+	rot := make(Bytes, nb)
+	src.ShiftLeftTo(rot, n)
+	src.ShiftRight(numBits - n)
+	rot.Or(src)
+	rot.clearFrom(numBits)
+	if rem := uint(numBits) & byteModMask; rem != 0 {
+		rot[nb-1] |= s[nb-1] &^ (1<<rem - 1)
+	}
+	copy(s, rot)
+}
+
+// RotateRight rotates the bits in the range [0, numBits) of s n positions
+// towards lower indexes, so that the bit at index i moves to index
+// (i-n) mod numBits.  Bits at or beyond numBits are not modified.  A negative
+// n rotates left.  This method will panic if numBits results in a byte index
+// that exceeds the number of bytes held by the bitset.
+func (s Bytes) RotateRight(n, numBits int) {
+	if numBits > 0 {
+		s.RotateLeft(numBits-n%numBits, numBits)
+	}
+}
+
+// clearFrom unsets every bit of s at or beyond index i.
+func (s Bytes) clearFrom(i int) {
+	b := int(uint(i) >> byteShift)
+	if b >= len(s) {
+		return
+	}
+	s[b] &= 1<<(uint(i)&byteModMask) - 1
+	clear(s[b+1:])
+}
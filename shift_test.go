@@ -0,0 +1,138 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset_test
+
+import (
+	"testing"
+
+	. "github.com/jrick/bitset"
+)
+
+// shifted returns the indexes of set shifted by n and limited to the range
+// [0, numBits).
+func shifted(set []int, n, numBits int) []int {
+	var out []int
+	for _, i := range set {
+		if j := i + n; j >= 0 && j < numBits {
+			out = append(out, j)
+		}
+	}
+	return out
+}
+
+func TestShift(t *testing.T) {
+	const numBits = 256
+	set := []int{0, 1, 7, 8, 63, 64, 100, 127, 128, 200, 255}
+	for _, n := range []int{0, 1, 3, 7, 8, 9, 31, 32, 63, 64, 65, 130, 255, 256, 1000} {
+		p := NewPointers(numBits)
+		p.SetMany(set)
+		p.ShiftLeft(n)
+		if got, exp := p.ToSlice(), shifted(set, n, numBits); !equalIndices(got, exp) {
+			t.Errorf("Pointers ShiftLeft(%d): got %v expected %v", n, got, exp)
+		}
+		p = NewPointers(numBits)
+		p.SetMany(set)
+		p.ShiftRight(n)
+		if got, exp := p.ToSlice(), shifted(set, -n, numBits); !equalIndices(got, exp) {
+			t.Errorf("Pointers ShiftRight(%d): got %v expected %v", n, got, exp)
+		}
+
+		b := NewBytes(numBits)
+		b.SetMany(set)
+		b.ShiftLeft(n)
+		if got, exp := b.ToSlice(), shifted(set, n, numBits); !equalIndices(got, exp) {
+			t.Errorf("Bytes ShiftLeft(%d): got %v expected %v", n, got, exp)
+		}
+		b = NewBytes(numBits)
+		b.SetMany(set)
+		b.ShiftRight(n)
+		if got, exp := b.ToSlice(), shifted(set, -n, numBits); !equalIndices(got, exp) {
+			t.Errorf("Bytes ShiftRight(%d): got %v expected %v", n, got, exp)
+		}
+	}
+}
+
+func TestShiftTo(t *testing.T) {
+	set := []int{0, 5, 60, 64}
+	src := NewPointersFromIndices(set)
+	dst := NewPointers(512)
+	dst.Set(511)
+	src.ShiftLeftTo(dst, 100)
+	if got, exp := dst.ToSlice(), shifted(set, 100, 512); !equalIndices(got, exp) {
+		t.Errorf("Pointers ShiftLeftTo: got %v expected %v", got, exp)
+	}
+	if got, exp := src.ToSlice(), set; !equalIndices(got, exp) {
+		t.Errorf("Pointers ShiftLeftTo modified source: got %v", got)
+	}
+	bsrc := NewBytesFromIndices(set)
+	bdst := NewBytes(64)
+	bsrc.ShiftRightTo(bdst, 5)
+	if got, exp := bdst.ToSlice(), shifted(set, -5, 64); !equalIndices(got, exp) {
+		t.Errorf("Bytes ShiftRightTo: got %v expected %v", got, exp)
+	}
+}
+
+func TestRotate(t *testing.T) {
+	const capBits = 256
+	set := []int{0, 1, 7, 8, 63, 64, 99, 100}
+	beyond := 250
+	for _, numBits := range []int{1, 8, 13, 64, 101, 200, 256} {
+		for _, n := range []int{0, 1, 5, 8, 63, 64, 65, 101, 300, -3} {
+			var in []int
+			for _, i := range set {
+				if i < numBits {
+					in = append(in, i)
+				}
+			}
+			expect := func(sign int) []int {
+				exp := make([]bool, capBits)
+				for _, i := range in {
+					j := ((i+sign*n)%numBits + numBits) % numBits
+					exp[j] = true
+				}
+				if beyond >= numBits {
+					exp[beyond] = true
+				}
+				var out []int
+				for i, v := range exp {
+					if v {
+						out = append(out, i)
+					}
+				}
+				return out
+			}
+
+			p, b := NewPointers(capBits), NewBytes(capBits)
+			p.SetMany(in)
+			b.SetMany(in)
+			if beyond >= numBits {
+				p.Set(beyond)
+				b.Set(beyond)
+			}
+			p.RotateLeft(n, numBits)
+			b.RotateLeft(n, numBits)
+			if got, exp := p.ToSlice(), expect(1); !equalIndices(got, exp) {
+				t.Errorf("Pointers RotateLeft(%d, %d): got %v expected %v",
+					n, numBits, got, exp)
+			}
+			if got, exp := b.ToSlice(), expect(1); !equalIndices(got, exp) {
+				t.Errorf("Bytes RotateLeft(%d, %d): got %v expected %v",
+					n, numBits, got, exp)
+			}
+			p.RotateRight(n, numBits)
+			b.RotateRight(n, numBits)
+			p.RotateRight(n, numBits)
+			b.RotateRight(n, numBits)
+			if got, exp := p.ToSlice(), expect(-1); !equalIndices(got, exp) {
+				t.Errorf("Pointers RotateRight(%d, %d): got %v expected %v",
+					n, numBits, got, exp)
+			}
+			if got, exp := b.ToSlice(), expect(-1); !equalIndices(got, exp) {
+				t.Errorf("Bytes RotateRight(%d, %d): got %v expected %v",
+					n, numBits, got, exp)
+			}
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+// The similarity coefficients below are derived from the fused set counts so
+// that no intermediate bitsets are allocated.  Each coefficient ranges from 0
+// for disjoint sets to 1 for identical sets.  Two empty sets are identical,
+// and each coefficient is defined to be 1 for them.  An empty set and a
+// nonempty set are disjoint, and each coefficient is 0 for them.
+
+// Jaccard returns the Jaccard index of p and other: the number of bits set in
+// both divided by the number of bits set in either.
+func (p Pointers) Jaccard(other Pointers) float64 {
+	return ratio(p.AndCount(other), p.OrCount(other))
+}
+
+// Overlap returns the overlap coefficient of p and other: the number of bits
+// set in both divided by the number of bits set in the smaller of the two.
+func (p Pointers) Overlap(other Pointers) float64 {
+	return overlap(p.AndCount(other), p.Count(), other.Count())
+}
+
+// Dice returns the Sørensen–Dice coefficient of p and other: twice the number
+// of bits set in both divided by the sum of the bits set in each.
+func (p Pointers) Dice(other Pointers) float64 {
+	return ratio(2*p.AndCount(other), p.Count()+other.Count())
+}
+
+// Jaccard returns the Jaccard index of s and other: the number of bits set in
+// both divided by the number of bits set in either.
+func (s Bytes) Jaccard(other Bytes) float64 {
+	return ratio(s.AndCount(other), s.OrCount(other))
+}
+
+// Overlap returns the overlap coefficient of s and other: the number of bits
+// set in both divided by the number of bits set in the smaller of the two.
+func (s Bytes) Overlap(other Bytes) float64 {
+	return overlap(s.AndCount(other), s.Count(), other.Count())
+}
+
+// Dice returns the Sørensen–Dice coefficient of s and other: twice the number
+// of bits set in both divided by the sum of the bits set in each.
+func (s Bytes) Dice(other Bytes) float64 {
+	return ratio(2*s.AndCount(other), s.Count()+other.Count())
+}
+
+// Jaccard returns the Jaccard index of s and other: the number of bits set in
+// both divided by the number of bits set in either.
+func (s Sparse) Jaccard(other Sparse) float64 {
+	return ratio(s.AndCount(other), s.OrCount(other))
+}
+
+// Overlap returns the overlap coefficient of s and other: the number of bits
+// set in both divided by the number of bits set in the smaller of the two.
+func (s Sparse) Overlap(other Sparse) float64 {
+	return overlap(s.AndCount(other), s.Count(), other.Count())
+}
+
+// Dice returns the Sørensen–Dice coefficient of s and other: twice the number
+// of bits set in both divided by the sum of the bits set in each.
+func (s Sparse) Dice(other Sparse) float64 {
+	return ratio(2*s.AndCount(other), s.Count()+other.Count())
+}
+
+// ratio returns num/denom, or 1 if denom is zero.
+func ratio(num, denom int) float64 {
+	if denom == 0 {
+		return 1
+	}
+	return float64(num) / float64(denom)
+}
+
+// overlap returns the overlap coefficient of two sets with n and m bits set
+// and both bits set in common.  Unlike ratio, the smaller count is zero when
+// only one of the sets is empty, and the coefficient is then 0.
+func overlap(both, n, m int) float64 {
+	if min(n, m) == 0 && max(n, m) != 0 {
+		return 0
+	}
+	return ratio(both, min(n, m))
+}
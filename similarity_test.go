@@ -0,0 +1,46 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset_test
+
+import (
+	"testing"
+
+	. "github.com/jrick/bitset"
+)
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b                   []int
+		jaccard, overlap, dice float64
+	}{
+		{a: nil, b: nil, jaccard: 1, overlap: 1, dice: 1},
+		{a: nil, b: []int{1}, jaccard: 0, overlap: 0, dice: 0},
+		{a: []int{70}, b: nil, jaccard: 0, overlap: 0, dice: 0},
+		{a: []int{1}, b: []int{2}, jaccard: 0, overlap: 0, dice: 0},
+		{a: []int{1, 100}, b: []int{1, 100}, jaccard: 1, overlap: 1, dice: 1},
+		{a: []int{1, 2, 3, 4}, b: []int{3, 4, 500, 600}, jaccard: 2.0 / 6, overlap: 0.5, dice: 0.5},
+		{a: []int{1, 2}, b: []int{1, 2, 64, 65}, jaccard: 0.5, overlap: 1, dice: 4.0 / 6},
+	}
+	for testNum, test := range tests {
+		pa, pb := NewPointersFromIndices(test.a), NewPointersFromIndices(test.b)
+		ba, bb := NewBytesFromIndices(test.a), NewBytesFromIndices(test.b)
+		sa, sb := NewSparseFromIndices(test.a), NewSparseFromIndices(test.b)
+		results := []struct {
+			name                   string
+			jaccard, overlap, dice float64
+		}{
+			{"Pointers", pa.Jaccard(pb), pa.Overlap(pb), pa.Dice(pb)},
+			{"Bytes", ba.Jaccard(bb), ba.Overlap(bb), ba.Dice(bb)},
+			{"Sparse", sa.Jaccard(sb), sa.Overlap(sb), sa.Dice(sb)},
+		}
+		for _, r := range results {
+			if r.jaccard != test.jaccard || r.overlap != test.overlap || r.dice != test.dice {
+				t.Errorf("Test %d bitset %s: got %v/%v/%v expected %v/%v/%v",
+					testNum, r.name, r.jaccard, r.overlap, r.dice,
+					test.jaccard, test.overlap, test.dice)
+			}
+		}
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// ErrInvalidLength describes an error where the bit length header of a
+// streamed bitset is malformed or too large to be represented.
+var ErrInvalidLength = errors.New("bitset: invalid bit length")
+
+// A streamed bitset is written by WriteTo as:
+//
+//	uvarint  number of bits, n
+//	bytes    (n+7)/8 bytes using the layout of a Bytes bitset
+//
+// Bits of the final byte at or beyond n are written as zero and ignored when
+// read.  The ReadFrom methods return io.EOF if r is already at EOF, and
+// io.ErrUnexpectedEOF if the stream ends partway through a bitset.
+// Pointers and Bytes bitsets record their full capacity as the bit
+// length, while a Dense bitset records its exact logical length.
+
+// WriteTo writes the streamed encoding of s to w.  It implements the
+// io.WriterTo interface.
+func (s Bytes) WriteTo(w io.Writer) (int64, error) {
+	return writeStream(w, len(s)<<byteShift, s)
+}
+
+// ReadFrom replaces the contents of s with a streamed bitset read from r,
+// resizing s to hold the recorded number of bits.  It implements the
+// io.ReaderFrom interface.  On error, s is not modified.
+func (s *Bytes) ReadFrom(r io.Reader) (int64, error) {
+	_, data, n, err := readStream(r, maxInt)
+	if err != nil {
+		return n, err
+	}
+	*s = data
+	return n, nil
+}
+
+// WriteTo writes the streamed encoding of p to w.  It implements the
+// io.WriterTo interface.
+func (p Pointers) WriteTo(w io.Writer) (int64, error) {
+	return writeStream(w, len(p)<<ptrShift, p.appendBytes(nil))
+}
+
+// ReadFrom replaces the contents of p with a streamed bitset read from r,
+// resizing p to hold the recorded number of bits rounded up to whole
+// pointers.  It implements the io.ReaderFrom interface.  On error, p is not
+// modified.
+func (p *Pointers) ReadFrom(r io.Reader) (int64, error) {
+	_, data, n, err := readStream(r, maxInt)
+	if err != nil {
+		return n, err
+	}
+	p.setBytes(data)
+	return n, nil
+}
+
+// WriteTo writes the streamed encoding of d to w, recording the logical
+// length of d.  It implements the io.WriterTo interface.
+func (d *Dense) WriteTo(w io.Writer) (int64, error) {
+	data := d.p.appendBytes(nil)
+	return writeStream(w, d.n, data[:(d.n+byteModMask)>>byteShift])
+}
+
+// ReadFrom replaces the contents of d with a streamed bitset read from r,
+// setting the logical length of d to the recorded number of bits.  It
+// implements the io.ReaderFrom interface.  On error, d is not modified.
+func (d *Dense) ReadFrom(r io.Reader) (int64, error) {
+	numBits, data, n, err := readStream(r, maxInt)
+	if err != nil {
+		return n, err
+	}
+	d.p.setBytes(data)
+	d.n = numBits
+	return n, nil
+}
+
+// writeStream writes the bit length header numBits followed by data, which
+// must hold exactly (numBits+7)/8 bytes.
+func writeStream(w io.Writer, numBits int, data []byte) (int64, error) {
+	var hdr [binary.MaxVarintLen64]byte
+	n, err := w.Write(hdr[:binary.PutUvarint(hdr[:], uint64(numBits))])
+	total := int64(n)
+	if err != nil {
+		return total, err
+	}
+	n, err = w.Write(data)
+	return total + int64(n), err
+}
+
+// readStream reads a bit length header and the bytes which follow it from r,
+// returning the bit length, the bytes with any bits beyond the length
+// cleared, and the total number of bytes read.  Headers recording more than
+// maxBits bits result in ErrTooLarge.
+func readStream(r io.Reader, maxBits int) (int, Bytes, int64, error) {
+	cr := &countingReader{r: r}
+	numBits, err := readStreamHeader(cr, maxBits)
+	if err != nil {
+		return 0, nil, cr.n, err
+	}
+	data, err := readChunked(cr, (numBits+byteModMask)>>byteShift)
+	if err != nil {
+		return 0, nil, cr.n, err
+	}
+	Bytes(data).clearFrom(numBits)
+	return numBits, data, cr.n, nil
+}
+
+// readChunked reads exactly size bytes from r.  The bytes are read in bounded
+// chunks so that a header recording a huge length cannot cause a huge
+// allocation before the data backing it has actually been read.  If r ends
+// before size bytes are read, io.ErrUnexpectedEOF is returned.
+func readChunked(r io.Reader, size int) ([]byte, error) {
+	data := make([]byte, 0, min(size, streamChunk))
+	for len(data) < size {
+		n := min(size-len(data), streamChunk)
+		data = append(data, make([]byte, n)...)
+		if _, err := io.ReadFull(r, data[len(data)-n:]); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// readStreamHeader reads the bit length header of a streamed bitset from cr.
+// Headers recording more than maxBits bits result in ErrTooLarge.
+func readStreamHeader(cr *countingReader, maxBits int) (int, error) {
+	start := cr.n
+	numBits, err := binary.ReadUvarint(cr)
+	if err != nil {
+		// ReadUvarint only reports an overflow after successfully
+		// reading the longest possible varint.
+		if cr.n-start == binary.MaxVarintLen64 {
+			err = ErrInvalidLength
+		}
+		return 0, err
+	}
+	if numBits > uint64(maxInt-byteModMask) {
+		return 0, ErrInvalidLength
+	}
+	if numBits > uint64(maxBits) {
+		return 0, ErrTooLarge
+	}
+	return int(numBits), nil
+}
+
+// streamChunk is the largest number of bytes allocated by readChunked ahead
+// of reading them.
+const streamChunk = 64 << 10
+
+// countingReader is an io.Reader and io.ByteReader which records the number
+// of bytes read from the underlying reader.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func (c *countingReader) ReadByte() (byte, error) {
+	var b [1]byte
+	_, err := io.ReadFull(c, b[:])
+	return b[0], err
+}
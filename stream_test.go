@@ -0,0 +1,100 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	. "github.com/jrick/bitset"
+)
+
+func TestStreamDense(t *testing.T) {
+	for _, numBits := range []int{0, 1, 7, 8, 9, 64, 65, 200} {
+		d := NewDense(numBits)
+		for i := 0; i < numBits; i += 3 {
+			d.Set(i)
+		}
+		var buf bytes.Buffer
+		n, err := d.WriteTo(&buf)
+		if err != nil {
+			t.Fatalf("%d bits: WriteTo: %v", numBits, err)
+		}
+		if n != int64(buf.Len()) {
+			t.Errorf("%d bits: WriteTo reported %d bytes, wrote %d", numBits, n, buf.Len())
+		}
+		hdrLen := 1
+		if numBits >= 128 {
+			hdrLen = 2
+		}
+		if exp := hdrLen + (numBits+7)/8; buf.Len() != exp {
+			t.Errorf("%d bits: wrote %d bytes expected %d", numBits, buf.Len(), exp)
+		}
+		buf.WriteString("trailing")
+
+		d2 := NewDense(5)
+		n2, err := d2.ReadFrom(&buf)
+		if err != nil {
+			t.Fatalf("%d bits: ReadFrom: %v", numBits, err)
+		}
+		if n2 != n {
+			t.Errorf("%d bits: ReadFrom read %d bytes expected %d", numBits, n2, n)
+		}
+		if d2.Len() != numBits || !d2.Equal(d) {
+			t.Errorf("%d bits: decoded %d bits not equal to original", numBits, d2.Len())
+		}
+		if buf.String() != "trailing" {
+			t.Errorf("%d bits: ReadFrom consumed past the bitset", numBits)
+		}
+	}
+}
+
+func TestStreamPointersBytes(t *testing.T) {
+	set := []int{0, 9, 63, 64, 127}
+	var buf bytes.Buffer
+	if _, err := NewPointersFromIndices(set).WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var s Bytes
+	if _, err := s.ReadFrom(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.ToSlice(); !equalIndices(got, set) {
+		t.Errorf("Bytes: got %v expected %v", got, set)
+	}
+	if _, err := s.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var p Pointers
+	if _, err := p.ReadFrom(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if got := p.ToSlice(); !equalIndices(got, set) {
+		t.Errorf("Pointers: got %v expected %v", got, set)
+	}
+}
+
+func TestStreamErrors(t *testing.T) {
+	tests := []struct {
+		data []byte
+		err  error
+	}{
+		{nil, io.EOF},
+		{[]byte{0x80}, io.ErrUnexpectedEOF},
+		{[]byte{16, 0xff}, io.ErrUnexpectedEOF},
+		{[]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f}, ErrInvalidLength},
+		{[]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}, ErrInvalidLength},
+	}
+	for testNum, test := range tests {
+		s := NewBytesFromIndices([]int{3})
+		if _, err := s.ReadFrom(bytes.NewReader(test.data)); err != test.err {
+			t.Errorf("Test %d: got error %v expected %v", testNum, err, test.err)
+		}
+		if got := s.ToSlice(); !equalIndices(got, []int{3}) {
+			t.Errorf("Test %d: bitset modified by failed read", testNum)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// StreamDecoder incrementally decodes a streamed bitset, as written by
+// WriteTo, from chunks of any size as they become available.  This allows a
+// bitset to be rebuilt as it arrives from the network, without first
+// buffering the entire encoding or blocking on an io.Reader.  Memory for the
+// bitset is allocated as its data is received, so a hostile length header
+// alone cannot cause a large allocation.
+//
+// Errors are sticky: once Feed or Write returns an error, every later call
+// returns the same error.
+type StreamDecoder struct {
+	maxBits  int
+	hdrLen   int // bytes of the length header received so far
+	numBits  uint64
+	haveHdr  bool
+	size     int // bytes of data following the header
+	data     Bytes
+	received int64
+	err      error
+}
+
+// NewStreamDecoder returns a StreamDecoder for a bitset of no more than
+// maxBits bits.  Length headers recording more bits result in ErrTooLarge.
+func NewStreamDecoder(maxBits int) *StreamDecoder {
+	return &StreamDecoder{maxBits: maxBits}
+}
+
+// Feed decodes the next chunk of the encoding, returning the number of bytes
+// of p that were consumed.  Once the bitset is complete, no further bytes are
+// consumed, and any remaining bytes of p belong to whatever follows the
+// bitset in the stream.
+func (d *StreamDecoder) Feed(p []byte) (int, error) {
+	if d.err != nil {
+		return 0, d.err
+	}
+	n := 0
+	for !d.haveHdr && n < len(p) {
+		b := p[n]
+		n++
+		if d.hdrLen == binary.MaxVarintLen64-1 && b > 1 {
+			d.err = ErrInvalidLength
+			break
+		}
+		d.numBits |= uint64(b&0x7f) << (7 * uint(d.hdrLen))
+		d.hdrLen++
+		if b < 0x80 {
+			d.haveHdr = true
+			switch {
+			case d.numBits > uint64(maxInt-byteModMask):
+				d.err = ErrInvalidLength
+			case d.numBits > uint64(d.maxBits):
+				d.err = ErrTooLarge
+			default:
+				d.size = (int(d.numBits) + byteModMask) >> byteShift
+			}
+		}
+	}
+	if d.err == nil && d.haveHdr {
+		m := min(len(p)-n, d.size-len(d.data))
+		d.data = append(d.data, p[n:n+m]...)
+		n += m
+		if len(d.data) == d.size {
+			d.data.clearFrom(int(d.numBits))
+		}
+	}
+	d.received += int64(n)
+	return n, d.err
+}
+
+// Write decodes p as the next chunk of the encoding.  It implements the
+// io.Writer interface.  Unlike Feed, it is an error for p to extend beyond
+// the end of the bitset, and io.ErrShortWrite is returned in that case.
+func (d *StreamDecoder) Write(p []byte) (int, error) {
+	n, err := d.Feed(p)
+	if err == nil && n != len(p) {
+		err = io.ErrShortWrite
+	}
+	return n, err
+}
+
+// Progress returns the number of bytes of the encoding decoded so far and
+// the total size of the encoding.  The total is -1 until the length header
+// has been decoded.
+func (d *StreamDecoder) Progress() (received, total int64) {
+	if !d.haveHdr {
+		return d.received, -1
+	}
+	return d.received, int64(d.hdrLen + d.size)
+}
+
+// Done returns whether the entire bitset has been decoded.
+func (d *StreamDecoder) Done() bool {
+	return d.err == nil && d.haveHdr && len(d.data) == d.size
+}
+
+// Bytes returns the decoded bitset.  If decoding is not yet complete,
+// io.ErrUnexpectedEOF is returned, or the error returned by Feed if decoding
+// failed.
+func (d *StreamDecoder) Bytes() (Bytes, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	if !d.Done() {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return d.data, nil
+}
+
+// Dense returns the decoded bitset with the logical length recorded by its
+// header.  Errors are returned as by Bytes.
+func (d *StreamDecoder) Dense() (*Dense, error) {
+	s, err := d.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	dense := &Dense{n: int(d.numBits)}
+	dense.p.setBytes(s)
+	return dense, nil
+}
+
+// Reset prepares the decoder to decode a new bitset, keeping its size limit.
+// Bitsets previously returned by Bytes or Dense are not modified.
+func (d *StreamDecoder) Reset() {
+	*d = StreamDecoder{maxBits: d.maxBits}
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	. "github.com/jrick/bitset"
+)
+
+func TestStreamDecoder(t *testing.T) {
+	d := NewDense(1000)
+	for _, i := range []int{0, 7, 500, 999} {
+		d.Set(i)
+	}
+	var buf bytes.Buffer
+	d.WriteTo(&buf)
+	enc := buf.Bytes()
+
+	for _, chunkSize := range []int{1, 2, 7, 100, len(enc)} {
+		dec := NewStreamDecoder(1000)
+		for off := 0; off < len(enc); off += chunkSize {
+			if dec.Done() {
+				t.Fatalf("chunk size %d: done after %d bytes", chunkSize, off)
+			}
+			if _, err := dec.Bytes(); err != io.ErrUnexpectedEOF {
+				t.Errorf("chunk size %d: incomplete Bytes got error %v",
+					chunkSize, err)
+			}
+			chunk := enc[off:min(off+chunkSize, len(enc))]
+			if n, err := dec.Write(chunk); err != nil || n != len(chunk) {
+				t.Fatalf("chunk size %d: Write got %d, %v", chunkSize, n, err)
+			}
+			received, total := dec.Progress()
+			if received != int64(off+len(chunk)) {
+				t.Errorf("chunk size %d: received %d expected %d",
+					chunkSize, received, off+len(chunk))
+			}
+			if received >= 2 && total != int64(len(enc)) {
+				t.Errorf("chunk size %d: total %d expected %d", chunkSize,
+					total, len(enc))
+			}
+			if received < 2 && total != -1 {
+				t.Errorf("chunk size %d: total %d before header", chunkSize, total)
+			}
+		}
+		if !dec.Done() {
+			t.Fatalf("chunk size %d: not done", chunkSize)
+		}
+		got, err := dec.Dense()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got.Equal(d) {
+			t.Errorf("chunk size %d: decoded %v expected %v", chunkSize, got, d)
+		}
+	}
+}
+
+func TestStreamDecoderFrames(t *testing.T) {
+	// Two bitsets back to back, fed as a single chunk.
+	var buf bytes.Buffer
+	Bytes{0x01, 0xff}.WriteTo(&buf)
+	Bytes{0x80}.WriteTo(&buf)
+	stream := buf.Bytes()
+
+	dec := NewStreamDecoder(64)
+	var frames []Bytes
+	for len(stream) != 0 {
+		n, err := dec.Feed(stream)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stream = stream[n:]
+		if dec.Done() {
+			s, _ := dec.Bytes()
+			frames = append(frames, s)
+			dec.Reset()
+		}
+	}
+	if len(frames) != 2 || !frames[0].Equal(Bytes{0x01, 0xff}) ||
+		!frames[1].Equal(Bytes{0x80}) {
+		t.Errorf("got frames %x", frames)
+	}
+
+	// Write rejects data beyond the bitset.
+	buf.Reset()
+	Bytes{0x01}.WriteTo(&buf)
+	buf.WriteByte(0)
+	dec = NewStreamDecoder(64)
+	if n, err := dec.Write(buf.Bytes()); n != 2 || err != io.ErrShortWrite {
+		t.Errorf("Write: got %d, %v expected 2, %v", n, err, io.ErrShortWrite)
+	}
+}
+
+func TestStreamDecoderErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{"too large", binary.AppendUvarint(nil, 65), ErrTooLarge},
+		{"hostile length", binary.AppendUvarint(nil, 1<<30), ErrTooLarge},
+		{"overflow", bytes.Repeat([]byte{0xff}, 11), ErrInvalidLength},
+	}
+	for _, test := range tests {
+		dec := NewStreamDecoder(64)
+		if _, err := dec.Feed(test.data); err != test.err {
+			t.Errorf("%s: got error %v expected %v", test.name, err, test.err)
+		}
+		// Errors are sticky.
+		if _, err := dec.Feed([]byte{0}); err != test.err {
+			t.Errorf("%s: got later error %v expected %v", test.name, err, test.err)
+		}
+		if _, err := dec.Bytes(); err != test.err {
+			t.Errorf("%s: Bytes got error %v expected %v", test.name, err, test.err)
+		}
+	}
+}
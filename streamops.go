@@ -0,0 +1,196 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// The streaming set operations combine streamed bitsets, as written by
+// WriteTo, directly from io.Readers and write the streamed encoding of the
+// result to an io.Writer.  Operands are read in bounded chunks, so memory use
+// is independent of the size of the bitsets.  Each operand is read to its
+// end, leaving its reader positioned after the bitset, and errors reading an
+// operand are reported as by ReadFrom.
+
+// StreamOr writes the union of the streamed bitsets read from a and b to w.
+// The result records the larger of the two bit lengths.  It returns the
+// number of bytes written to w.
+func StreamOr(w io.Writer, a, b io.Reader) (int64, error) {
+	return streamCombine(w, a, b, true, Bytes.Or)
+}
+
+// StreamAnd writes the intersection of the streamed bitsets read from a and
+// b to w.  The result records the smaller of the two bit lengths.  It returns
+// the number of bytes written to w.
+func StreamAnd(w io.Writer, a, b io.Reader) (int64, error) {
+	return streamCombine(w, a, b, false, Bytes.And)
+}
+
+// StreamCount returns the number of set bits of the streamed bitset read
+// from r.
+func StreamCount(r io.Reader) (int, error) {
+	src, err := newStreamSource(r)
+	if err != nil {
+		return 0, err
+	}
+	buf := make(Bytes, min(src.size, streamChunk))
+	n := 0
+	for src.remaining() != 0 {
+		chunk := buf[:min(src.remaining(), len(buf))]
+		if err := src.fill(chunk); err != nil {
+			return 0, err
+		}
+		n += chunk.Count()
+	}
+	return n, nil
+}
+
+// Merge writes the union of the streamed bitsets read from every input to w
+// in a single pass, which is considerably faster than merging the inputs
+// pairwise.  The inputs are read in turn one word-aligned chunk at a time, so
+// memory use is independent of both the number and size of the inputs.  The
+// result records the largest bit length of the inputs, and is an empty
+// bitset if there are no inputs.  It returns the number of bytes written to
+// w.
+func Merge(w io.Writer, inputs ...io.Reader) (int64, error) {
+	srcs := make([]*streamSource, len(inputs))
+	numBits := 0
+	for i, r := range inputs {
+		src, err := newStreamSource(r)
+		if err != nil {
+			return 0, err
+		}
+		srcs[i] = src
+		numBits = max(numBits, src.numBits)
+	}
+	size := (numBits + byteModMask) >> byteShift
+
+	cw := &countingWriter{w: w}
+	if _, err := cw.Write(binary.AppendUvarint(nil, uint64(numBits))); err != nil {
+		return cw.n, err
+	}
+	out := make(Bytes, min(size, streamChunk))
+	scratch := make(Bytes, len(out))
+	for off := 0; off < size; {
+		n := min(size-off, streamChunk)
+		chunk := out[:n]
+		clear(chunk)
+		for _, src := range srcs {
+			if src.remaining() == 0 {
+				continue
+			}
+			if err := src.fill(scratch[:n]); err != nil {
+				return cw.n, err
+			}
+			chunk.Or(scratch[:n])
+		}
+		if _, err := cw.Write(chunk); err != nil {
+			return cw.n, err
+		}
+		off += n
+	}
+	return cw.n, nil
+}
+
+// streamCombine writes the result of combining the streamed bitsets read
+// from a and b with op, which modifies its first operand.  The result has
+// the larger bit length of the operands if union is true, and the smaller
+// otherwise.
+func streamCombine(w io.Writer, a, b io.Reader, union bool, op func(dst, src Bytes)) (int64, error) {
+	srcA, err := newStreamSource(a)
+	if err != nil {
+		return 0, err
+	}
+	srcB, err := newStreamSource(b)
+	if err != nil {
+		return 0, err
+	}
+	numBits := min(srcA.numBits, srcB.numBits)
+	if union {
+		numBits = max(srcA.numBits, srcB.numBits)
+	}
+	size := (numBits + byteModMask) >> byteShift
+
+	cw := &countingWriter{w: w}
+	if _, err := cw.Write(binary.AppendUvarint(nil, uint64(numBits))); err != nil {
+		return cw.n, err
+	}
+	bufA := make(Bytes, min(size, streamChunk))
+	bufB := make(Bytes, len(bufA))
+	for off := 0; off < size; {
+		n := min(size-off, streamChunk)
+		chunkA, chunkB := bufA[:n], bufB[:n]
+		if err := srcA.fill(chunkA); err != nil {
+			return cw.n, err
+		}
+		if err := srcB.fill(chunkB); err != nil {
+			return cw.n, err
+		}
+		op(chunkA, chunkB)
+		if _, err := cw.Write(chunkA); err != nil {
+			return cw.n, err
+		}
+		off += n
+	}
+	if err := srcA.discard(); err != nil {
+		return cw.n, err
+	}
+	return cw.n, srcB.discard()
+}
+
+// streamSource reads the data of a streamed bitset in chunks.
+type streamSource struct {
+	cr      *countingReader
+	numBits int
+	size    int // bytes of data following the header
+	off     int // bytes of data read
+}
+
+// newStreamSource reads the header of a streamed bitset from r and returns a
+// streamSource positioned at the start of its data.
+func newStreamSource(r io.Reader) (*streamSource, error) {
+	cr := &countingReader{r: r}
+	numBits, err := readStreamHeader(cr, maxInt)
+	if err != nil {
+		return nil, err
+	}
+	size := (numBits + byteModMask) >> byteShift
+	return &streamSource{cr: cr, numBits: numBits, size: size}, nil
+}
+
+// remaining returns the number of bytes of data which have not been read.
+func (s *streamSource) remaining() int {
+	return s.size - s.off
+}
+
+// fill reads the next len(buf) bytes of the bitset into buf.  Bytes beyond
+// the end of the data, and bits beyond the bit length, are cleared.
+func (s *streamSource) fill(buf Bytes) error {
+	n := min(len(buf), s.remaining())
+	if _, err := io.ReadFull(s.cr, buf[:n]); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	clear(buf[n:])
+	if n != 0 && s.off+n == s.size {
+		buf.clearFrom(s.numBits - s.off<<byteShift)
+	}
+	s.off += n
+	return nil
+}
+
+// discard reads and discards any remaining data of the bitset.
+func (s *streamSource) discard() error {
+	n, err := io.CopyN(io.Discard, s.cr, int64(s.remaining()))
+	s.off += int(n)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,182 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset_test
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"slices"
+	"testing"
+
+	. "github.com/jrick/bitset"
+)
+
+func TestStreamOps(t *testing.T) {
+	tests := []struct {
+		aBits, bBits int
+		a, b         []int
+	}{
+		{aBits: 0, bBits: 0},
+		{aBits: 10, bBits: 0, a: []int{1, 9}},
+		{aBits: 10, bBits: 20, a: []int{1, 9}, b: []int{9, 19}},
+		{aBits: 200000, bBits: 70000, a: []int{0, 65536, 199999}, b: []int{0, 65537, 69999}},
+		{aBits: 1 << 17, bBits: 1 << 17, a: []int{3, 1 << 16}, b: []int{1 << 16, 1<<17 - 1}},
+	}
+	for testNum, test := range tests {
+		sa, sb := NewSparseFromIndices(test.a), NewSparseFromIndices(test.b)
+		da, db := NewDense(test.aBits), NewDense(test.bBits)
+		for _, i := range test.a {
+			da.Set(i)
+		}
+		for _, i := range test.b {
+			db.Set(i)
+		}
+		// Each operand is followed by a trailer which must be left
+		// unread.
+		encode := func(d *Dense) *bytes.Buffer {
+			var buf bytes.Buffer
+			d.WriteTo(&buf)
+			buf.WriteString("trailer")
+			return &buf
+		}
+		checkTrailer := func(op string, r *bytes.Buffer) {
+			if r.String() != "trailer" {
+				t.Errorf("Test %d %s: operand left %q unread", testNum, op, r.String())
+			}
+		}
+
+		for _, op := range []struct {
+			name string
+			fn   func(w io.Writer, a, b io.Reader) (int64, error)
+			bits int
+			exp  func(i int) bool
+		}{
+			{"Or", StreamOr, max(test.aBits, test.bBits), func(i int) bool {
+				return sa.Get(i) || sb.Get(i)
+			}},
+			{"And", StreamAnd, min(test.aBits, test.bBits), func(i int) bool {
+				return sa.Get(i) && sb.Get(i)
+			}},
+		} {
+			ra, rb := encode(da), encode(db)
+			var out bytes.Buffer
+			n, err := op.fn(&out, ra, rb)
+			if err != nil {
+				t.Fatalf("Test %d %s: %v", testNum, op.name, err)
+			}
+			if n != int64(out.Len()) {
+				t.Errorf("Test %d %s: returned %d wrote %d", testNum, op.name,
+					n, out.Len())
+			}
+			checkTrailer(op.name, ra)
+			checkTrailer(op.name, rb)
+
+			var got Dense
+			if _, err := got.ReadFrom(&out); err != nil {
+				t.Fatalf("Test %d %s: %v", testNum, op.name, err)
+			}
+			if got.Len() != op.bits {
+				t.Errorf("Test %d %s: got length %d expected %d", testNum,
+					op.name, got.Len(), op.bits)
+			}
+			for i := 0; i < op.bits; i++ {
+				if got.Get(i) != op.exp(i) {
+					t.Errorf("Test %d %s: bit %d got %v", testNum, op.name,
+						i, got.Get(i))
+				}
+			}
+		}
+
+		ra := encode(da)
+		if n, err := StreamCount(ra); err != nil || n != len(test.a) {
+			t.Errorf("Test %d Count: got %d, %v expected %d", testNum, n,
+				err, len(test.a))
+		}
+	}
+}
+
+func TestStreamOpsErrors(t *testing.T) {
+	var full bytes.Buffer
+	NewBytes(64).WriteTo(&full)
+	truncated := full.Bytes()[:4]
+
+	var out bytes.Buffer
+	if _, err := StreamOr(&out, bytes.NewReader(full.Bytes()), bytes.NewReader(truncated)); err != io.ErrUnexpectedEOF {
+		t.Errorf("Or: got error %v expected %v", err, io.ErrUnexpectedEOF)
+	}
+	// The intersection is empty, but the truncated operand must still
+	// be detected.
+	var empty bytes.Buffer
+	NewBytes(0).WriteTo(&empty)
+	if _, err := StreamAnd(&out, &empty, bytes.NewReader(truncated)); err != io.ErrUnexpectedEOF {
+		t.Errorf("And: got error %v expected %v", err, io.ErrUnexpectedEOF)
+	}
+	if _, err := StreamCount(bytes.NewReader(truncated)); err != io.ErrUnexpectedEOF {
+		t.Errorf("Count: got error %v expected %v", err, io.ErrUnexpectedEOF)
+	}
+	if _, err := StreamCount(bytes.NewReader(nil)); err != io.EOF {
+		t.Errorf("Count: got error %v expected %v", err, io.EOF)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	const numInputs = 200
+	rng := rand.New(rand.NewSource(1))
+	exp := make(Sparse)
+	maxBits := 0
+	inputs := make([]io.Reader, numInputs)
+	buffers := make([]*bytes.Buffer, numInputs)
+	for k := range inputs {
+		numBits := rng.Intn(1 << 18)
+		maxBits = max(maxBits, numBits)
+		d := NewDense(numBits)
+		for j := 0; j < 20 && numBits != 0; j++ {
+			i := rng.Intn(numBits)
+			d.Set(i)
+			exp.Set(i)
+		}
+		buffers[k] = new(bytes.Buffer)
+		d.WriteTo(buffers[k])
+		buffers[k].WriteString("trailer")
+		inputs[k] = buffers[k]
+	}
+
+	var out bytes.Buffer
+	n, err := Merge(&out, inputs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(out.Len()) {
+		t.Errorf("returned %d wrote %d", n, out.Len())
+	}
+	for k, buf := range buffers {
+		if buf.String() != "trailer" {
+			t.Errorf("input %d: left %q unread", k, buf.String())
+		}
+	}
+	var got Dense
+	if _, err := got.ReadFrom(&out); err != nil {
+		t.Fatal(err)
+	}
+	if got.Len() != maxBits {
+		t.Errorf("got length %d expected %d", got.Len(), maxBits)
+	}
+	if !NewSparseFromIndices(slices.Collect(got.Ones())).Equal(exp) {
+		t.Errorf("merged bitset differs from union")
+	}
+
+	out.Reset()
+	if _, err := Merge(&out); err != nil || !bytes.Equal(out.Bytes(), []byte{0}) {
+		t.Errorf("no inputs: got %x, %v", out.Bytes(), err)
+	}
+
+	var full bytes.Buffer
+	NewBytes(64).WriteTo(&full)
+	_, err = Merge(&out, bytes.NewReader(full.Bytes()), bytes.NewReader(full.Bytes()[:4]))
+	if err != io.ErrUnexpectedEOF {
+		t.Errorf("truncated input: got error %v expected %v", err, io.ErrUnexpectedEOF)
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+// rangeCounter is the method set of bitsets which a View may be created
+// over.
+type rangeCounter interface {
+	BitSet
+	CountRange(start, end int) int
+}
+
+// View is a window over a range of bits of a Pointers or Bytes bitset.  The
+// bit at index i of the view is the bit at index start+i of the underlying
+// bitset, and no bits are copied: modifications through the view are visible
+// in the underlying bitset and vice versa.
+//
+// Unlike the bitsets it is created from, a View checks every index against
+// its own length and panics for any index outside the window, even if the
+// index would be valid for the underlying bitset.  This makes it possible to
+// hand out non-overlapping views of a single bitset without any holder being
+// able to modify bits outside of its own range.
+type View struct {
+	bs    rangeCounter
+	start int
+	n     int
+}
+
+// View returns a View over the bits of p in the range [start, end).  This
+// method will panic if start is negative or greater than end, or if end
+// results in a pointer index that exceeds the number of pointers held by the
+// bitset.
+func (p Pointers) View(start, end int) View {
+	_ = p[:(end+ptrModMask)>>ptrShift]
+	return newView(p, start, end)
+}
+
+// View returns a View over the bits of s in the range [start, end).  This
+// method will panic if start is negative or greater than end, or if end
+// results in a byte index that exceeds the number of bytes held by the
+// bitset.
+func (s Bytes) View(start, end int) View {
+	_ = s[:(end+byteModMask)>>byteShift]
+	return newView(s, start, end)
+}
+
+// newView returns a View over the bits of bs in the range [start, end).
+func newView(bs rangeCounter, start, end int) View {
+	if start < 0 || start > end {
+		panic("bitset: invalid view range")
+	}
+	return View{bs: bs, start: start, n: end - start}
+}
+
+// Len returns the number of bits in the view.
+func (v View) Len() int {
+	return v.n
+}
+
+// index returns the index in the underlying bitset of the view's bit i,
+// panicking if i is outside the view.
+func (v View) index(i int) int {
+	if uint(i) >= uint(v.n) {
+		panic("bitset: view index out of range")
+	}
+	return v.start + i
+}
+
+// Get returns whether the bit at index i of the view is set or not.  This
+// method will panic if i is not in the range [0, v.Len()).
+func (v View) Get(i int) bool {
+	return v.bs.Get(v.index(i))
+}
+
+// Set sets the bit at index i of the view.  This method will panic if i is
+// not in the range [0, v.Len()).
+func (v View) Set(i int) {
+	v.bs.Set(v.index(i))
+}
+
+// Unset unsets the bit at index i of the view.  This method will panic if i
+// is not in the range [0, v.Len()).
+func (v View) Unset(i int) {
+	v.bs.Unset(v.index(i))
+}
+
+// SetBool sets or unsets the bit at index i of the view depending on the
+// value of b.  This method will panic if i is not in the range [0, v.Len()).
+func (v View) SetBool(i int, b bool) {
+	v.bs.SetBool(v.index(i), b)
+}
+
+// Count returns the number of set bits in the view.
+func (v View) Count() int {
+	return v.bs.CountRange(v.start, v.start+v.n)
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset_test
+
+import (
+	"testing"
+
+	. "github.com/jrick/bitset"
+)
+
+func TestView(t *testing.T) {
+	p := NewPointers(256)
+	b := NewBytes(256)
+	for _, c := range []struct {
+		name   string
+		parent interface {
+			BitSet
+			Count() int
+		}
+		view View
+	}{
+		{"Pointers", p, p.View(60, 140)},
+		{"Bytes", b, b.View(60, 140)},
+	} {
+		v := c.view
+		if v.Len() != 80 {
+			t.Errorf("bitset %s: view len %d expected 80", c.name, v.Len())
+		}
+		v.Set(0)
+		v.Set(79)
+		v.SetBool(10, true)
+		if !c.parent.Get(60) || !c.parent.Get(139) || !c.parent.Get(70) {
+			t.Errorf("bitset %s: view writes not visible in parent", c.name)
+		}
+		c.parent.Set(100)
+		c.parent.Set(59)
+		c.parent.Set(140)
+		if !v.Get(40) {
+			t.Errorf("bitset %s: parent writes not visible in view", c.name)
+		}
+		if got := v.Count(); got != 4 {
+			t.Errorf("bitset %s: view count %d expected 4", c.name, got)
+		}
+		v.Unset(40)
+		if c.parent.Get(100) || c.parent.Count() != 5 {
+			t.Errorf("bitset %s: view unset not visible in parent", c.name)
+		}
+
+		for _, i := range []int{-1, 80, 81} {
+			func() {
+				defer func() {
+					if recover() == nil {
+						t.Errorf("bitset %s: index %d outside view did not panic",
+							c.name, i)
+					}
+				}()
+				v.Set(i)
+			}()
+		}
+	}
+}
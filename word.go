@@ -0,0 +1,241 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+import (
+	"encoding/binary"
+	"iter"
+)
+
+// GetWord64 returns the 64 bits of p beginning at bit index i, with bit i in
+// the least significant bit of the result.  The index need not be aligned to
+// a pointer boundary.  This method will panic if any bit of the window lies
+// outside of the bits held by the bitset.
+func (p Pointers) GetWord64(i int) uint64 {
+	checkWindow(i, 64, len(p)<<ptrShift)
+	return p.uintAt(i, 64)
+}
+
+// SetWord64 replaces the 64 bits of p beginning at bit index i with the bits
+// of w, with bit i taking the least significant bit of w.  The index need not
+// be aligned to a pointer boundary.  This method will panic if any bit of the
+// window lies outside of the bits held by the bitset.
+func (p Pointers) SetWord64(i int, w uint64) {
+	checkWindow(i, 64, len(p)<<ptrShift)
+	p.setUintAt(i, 64, w)
+}
+
+// GetWord64 returns the 64 bits of s beginning at bit index i, with bit i in
+// the least significant bit of the result.  The index need not be aligned to
+// a byte boundary.  This method will panic if any bit of the window lies
+// outside of the bits held by the bitset.
+func (s Bytes) GetWord64(i int) uint64 {
+	checkWindow(i, 64, len(s)<<byteShift)
+	return s.uintAt(i, 64)
+}
+
+// SetWord64 replaces the 64 bits of s beginning at bit index i with the bits
+// of w, with bit i taking the least significant bit of w.  The index need not
+// be aligned to a byte boundary.  This method will panic if any bit of the
+// window lies outside of the bits held by the bitset.
+func (s Bytes) SetWord64(i int, w uint64) {
+	checkWindow(i, 64, len(s)<<byteShift)
+	s.setUintAt(i, 64, w)
+}
+
+// ExtractUint returns the width-bit unsigned integer stored in p beginning at
+// bit index offset, with bit offset as its least significant bit.  A width of
+// zero always returns zero.  This method will panic if width is not in the
+// range [0, 64] or if any bit of the field lies outside of the bits held by
+// the bitset.
+func (p Pointers) ExtractUint(offset, width int) uint64 {
+	checkField(offset, width, len(p)<<ptrShift)
+	if width == 0 {
+		return 0
+	}
+	return p.uintAt(offset, uint(width))
+}
+
+// InsertUint stores the low width bits of v in p beginning at bit index
+// offset, with bit offset receiving the least significant bit of v.  Bits of
+// v above width are ignored.  This method will panic if width is not in the
+// range [0, 64] or if any bit of the field lies outside of the bits held by
+// the bitset.
+func (p Pointers) InsertUint(offset, width int, v uint64) {
+	checkField(offset, width, len(p)<<ptrShift)
+	if width == 0 {
+		return
+	}
+	p.setUintAt(offset, uint(width), v)
+}
+
+// ExtractUint returns the width-bit unsigned integer stored in s beginning at
+// bit index offset, with bit offset as its least significant bit.  A width of
+// zero always returns zero.  This method will panic if width is not in the
+// range [0, 64] or if any bit of the field lies outside of the bits held by
+// the bitset.
+func (s Bytes) ExtractUint(offset, width int) uint64 {
+	checkField(offset, width, len(s)<<byteShift)
+	if width == 0 {
+		return 0
+	}
+	return s.uintAt(offset, uint(width))
+}
+
+// InsertUint stores the low width bits of v in s beginning at bit index
+// offset, with bit offset receiving the least significant bit of v.  Bits of
+// v above width are ignored.  This method will panic if width is not in the
+// range [0, 64] or if any bit of the field lies outside of the bits held by
+// the bitset.
+func (s Bytes) InsertUint(offset, width int, v uint64) {
+	checkField(offset, width, len(s)<<byteShift)
+	if width == 0 {
+		return
+	}
+	s.setUintAt(offset, uint(width), v)
+}
+
+// The storage of Pointers and Bytes bitsets may be accessed as a sequence of
+// 64-bit chunks, independent of the machine pointer size.  Chunk k holds bits
+// 64k through 64k+63 with bit 64k in the least significant bit.  When the
+// number of bits held by a bitset is not a multiple of 64, the final chunk is
+// partial: bits beyond the end of the bitset read as zero and are ignored
+// when written.
+
+// NumUint64Chunks returns the number of 64-bit chunks needed to cover every
+// bit held by p.
+func (p Pointers) NumUint64Chunks() int {
+	return (len(p)<<ptrShift + 63) >> 6
+}
+
+// Uint64Chunk returns the 64-bit chunk k of p.  This method will panic if k
+// is not in the range [0, p.NumUint64Chunks()).
+func (p Pointers) Uint64Chunk(k int) uint64 {
+	off, width := chunkWindow(k, len(p)<<ptrShift)
+	return p.uintAt(off, width)
+}
+
+// SetUint64Chunk replaces the 64-bit chunk k of p with v.  This method will
+// panic if k is not in the range [0, p.NumUint64Chunks()).
+func (p Pointers) SetUint64Chunk(k int, v uint64) {
+	off, width := chunkWindow(k, len(p)<<ptrShift)
+	p.setUintAt(off, width, v)
+}
+
+// Uint64s returns an iterator over the index and value of every 64-bit chunk
+// of p in increasing order.
+func (p Pointers) Uint64s() iter.Seq2[int, uint64] {
+	return func(yield func(int, uint64) bool) {
+		for k := range p.NumUint64Chunks() {
+			if !yield(k, p.Uint64Chunk(k)) {
+				return
+			}
+		}
+	}
+}
+
+// NumUint64Chunks returns the number of 64-bit chunks needed to cover every
+// bit held by s.
+func (s Bytes) NumUint64Chunks() int {
+	return (len(s) + 7) >> 3
+}
+
+// Uint64Chunk returns the 64-bit chunk k of s.  This method will panic if k
+// is not in the range [0, s.NumUint64Chunks()).
+func (s Bytes) Uint64Chunk(k int) uint64 {
+	off, width := chunkWindow(k, len(s)<<byteShift)
+	if width == 64 {
+		return binary.LittleEndian.Uint64(s[off>>byteShift:])
+	}
+	return s.uintAt(off, width)
+}
+
+// SetUint64Chunk replaces the 64-bit chunk k of s with v.  This method will
+// panic if k is not in the range [0, s.NumUint64Chunks()).
+func (s Bytes) SetUint64Chunk(k int, v uint64) {
+	off, width := chunkWindow(k, len(s)<<byteShift)
+	if width == 64 {
+		binary.LittleEndian.PutUint64(s[off>>byteShift:], v)
+		return
+	}
+	s.setUintAt(off, width, v)
+}
+
+// Uint64s returns an iterator over the index and value of every 64-bit chunk
+// of s in increasing order.
+func (s Bytes) Uint64s() iter.Seq2[int, uint64] {
+	return func(yield func(int, uint64) bool) {
+		for k := range s.NumUint64Chunks() {
+			if !yield(k, s.Uint64Chunk(k)) {
+				return
+			}
+		}
+	}
+}
+
+// chunkWindow returns the bit offset and width of the 64-bit chunk k of a
+// bitset holding numBits bits, panicking if there is no such chunk.
+func chunkWindow(k, numBits int) (int, uint) {
+	if k < 0 || k >= (numBits+63)>>6 {
+		panic("bitset: chunk index out of range")
+	}
+	off := k << 6
+	return off, uint(min(64, numBits-off))
+}
+
+// checkField panics if width is not a valid integer field width or if the
+// field does not lie within the first numBits bits of a bitset.
+func checkField(off, width, numBits int) {
+	if width < 0 || width > 64 {
+		panic("bitset: invalid bit field width")
+	}
+	checkWindow(off, width, numBits)
+}
+
+// checkWindow panics if the width bits beginning at index off do not all lie
+// within the first numBits bits of a bitset.
+func checkWindow(off, width, numBits int) {
+	if off < 0 || off > numBits-width {
+		panic("bitset: bit window out of range")
+	}
+}
+
+// uintAt returns the width bits of p beginning at index off, stitched
+// together from as many pointers as the window spans.  width must be in the
+// range [1, 64].
+func (p Pointers) uintAt(off int, width uint) uint64 {
+	var v uint64
+	for k := uint(0); k < width; k += ptrBits {
+		v |= uint64(p.bitsAt(off+int(k), min(ptrBits, width-k))) << k
+	}
+	return v
+}
+
+// setUintAt replaces the width bits of p beginning at index off with the low
+// width bits of v.  width must be in the range [1, 64].
+func (p Pointers) setUintAt(off int, width uint, v uint64) {
+	for k := uint(0); k < width; k += ptrBits {
+		p.setBitsAt(off+int(k), min(ptrBits, width-k), uintptr(v>>k))
+	}
+}
+
+// uintAt returns the width bits of s beginning at index off, stitched
+// together from as many bytes as the window spans.  width must be in the
+// range [1, 64].
+func (s Bytes) uintAt(off int, width uint) uint64 {
+	var v uint64
+	for k := uint(0); k < width; k += 8 {
+		v |= uint64(s.bitsAt(off+int(k), min(8, width-k))) << k
+	}
+	return v
+}
+
+// setUintAt replaces the width bits of s beginning at index off with the low
+// width bits of v.  width must be in the range [1, 64].
+func (s Bytes) setUintAt(off int, width uint, v uint64) {
+	for k := uint(0); k < width; k += 8 {
+		s.setBitsAt(off+int(k), min(8, width-k), byte(v>>k))
+	}
+}
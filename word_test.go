@@ -0,0 +1,200 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset_test
+
+import (
+	"iter"
+	"testing"
+
+	. "github.com/jrick/bitset"
+)
+
+type word64er interface {
+	BitSet
+	GetWord64(i int) uint64
+	SetWord64(i int, w uint64)
+}
+
+func TestWord64(t *testing.T) {
+	const numBits = 256
+	words := []uint64{0, 1, 1 << 63, 0xdeadbeefcafef00d, ^uint64(0)}
+	for _, off := range []int{0, 1, 7, 8, 31, 32, 63, 64, 100, numBits - 64} {
+		for _, w := range words {
+			for _, c := range []struct {
+				name string
+				bs   word64er
+			}{
+				{"Pointers", NewPointers(numBits)},
+				{"Bytes", NewBytes(numBits)},
+			} {
+				// Surround the window with set bits to check they
+				// are preserved.
+				for i := 0; i < numBits; i++ {
+					c.bs.Set(i)
+				}
+				c.bs.SetWord64(off, w)
+				if got := c.bs.GetWord64(off); got != w {
+					t.Errorf("bitset %s: offset %d got %#x expected %#x",
+						c.name, off, got, w)
+				}
+				for i := 0; i < numBits; i++ {
+					exp := true
+					if i >= off && i < off+64 {
+						exp = w&(1<<uint(i-off)) != 0
+					}
+					if got := c.bs.Get(i); got != exp {
+						t.Errorf("bitset %s: offset %d word %#x: bit %d got %v expected %v",
+							c.name, off, w, i, got, exp)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestWord64OutOfRange(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		bs   word64er
+	}{
+		{"Pointers", NewPointers(128)},
+		{"Bytes", NewBytes(128)},
+	} {
+		for _, off := range []int{-1, 65, 128} {
+			func() {
+				defer func() {
+					if recover() == nil {
+						t.Errorf("bitset %s: GetWord64(%d) did not panic",
+							c.name, off)
+					}
+				}()
+				c.bs.GetWord64(off)
+			}()
+		}
+	}
+}
+
+type uintFielder interface {
+	BitSet
+	ExtractUint(offset, width int) uint64
+	InsertUint(offset, width int, v uint64)
+}
+
+func TestUintFields(t *testing.T) {
+	const numBits = 1024
+	for _, c := range []struct {
+		name string
+		bs   uintFielder
+	}{
+		{"Pointers", NewPointers(numBits)},
+		{"Bytes", NewBytes(numBits)},
+	} {
+		// Pack consecutive fields of increasing width and read them back.
+		type field struct {
+			off, width int
+			v          uint64
+		}
+		var fields []field
+		off := 0
+		for width := 0; width <= 30; width++ {
+			v := uint64(0x5555555555555555) ^ uint64(width)*0x0123456789abcdef
+			fields = append(fields, field{off, width, v})
+			c.bs.InsertUint(off, width, v)
+			off += width
+		}
+		fields = append(fields, field{off, 64, ^uint64(0) - 1})
+		c.bs.InsertUint(off, 64, ^uint64(0)-1)
+		for _, f := range fields {
+			exp := f.v
+			if f.width < 64 {
+				exp &= 1<<uint(f.width) - 1
+			}
+			if got := c.bs.ExtractUint(f.off, f.width); got != exp {
+				t.Errorf("bitset %s: field at %d width %d got %#x expected %#x",
+					c.name, f.off, f.width, got, exp)
+			}
+		}
+		if c.bs.Get(off + 64) {
+			t.Errorf("bitset %s: bit beyond last field set", c.name)
+		}
+
+		for _, bad := range [][2]int{{0, -1}, {0, 65}, {numBits - 3, 4}, {-1, 1}} {
+			func() {
+				defer func() {
+					if recover() == nil {
+						t.Errorf("bitset %s: ExtractUint(%d, %d) did not panic",
+							c.name, bad[0], bad[1])
+					}
+				}()
+				c.bs.ExtractUint(bad[0], bad[1])
+			}()
+		}
+	}
+}
+
+type chunker interface {
+	BitSet
+	NumUint64Chunks() int
+	Uint64Chunk(k int) uint64
+	SetUint64Chunk(k int, v uint64)
+	Uint64s() iter.Seq2[int, uint64]
+	Cap() int
+}
+
+func TestUint64Chunks(t *testing.T) {
+	for _, numBits := range []int{0, 8, 64, 72, 128, 200} {
+		set := []int{}
+		for i := 0; i < numBits; i += 5 {
+			set = append(set, i)
+		}
+		bitsets := []struct {
+			name string
+			bs   chunker
+		}{
+			{"Pointers", NewPointers(numBits)},
+			{"Bytes", NewBytes(numBits)},
+		}
+		for _, nbs := range bitsets {
+			for _, i := range set {
+				nbs.bs.Set(i)
+			}
+			total := nbs.bs.Cap()
+			if got, exp := nbs.bs.NumUint64Chunks(), (total+63)/64; got != exp {
+				t.Errorf("%d bits bitset %s: got %d chunks expected %d",
+					numBits, nbs.name, got, exp)
+			}
+			chunks := 0
+			for k, v := range nbs.bs.Uint64s() {
+				if k != chunks {
+					t.Errorf("%d bits bitset %s: chunk index %d expected %d",
+						numBits, nbs.name, k, chunks)
+				}
+				chunks++
+				for j := 0; j < 64; j++ {
+					i := k*64 + j
+					exp := i < total && nbs.bs.Get(i)
+					if got := v&(1<<uint(j)) != 0; got != exp {
+						t.Errorf("%d bits bitset %s: chunk %d bit %d got %v expected %v",
+							numBits, nbs.name, k, j, got, exp)
+					}
+				}
+				// Invert the chunk; bits beyond the end are ignored.
+				nbs.bs.SetUint64Chunk(k, ^v)
+				exp := ^v
+				if rem := total - k*64; rem < 64 {
+					exp &= 1<<uint(rem) - 1
+				}
+				if got := nbs.bs.Uint64Chunk(k); got != exp {
+					t.Errorf("%d bits bitset %s: chunk %d after set got %#x expected %#x",
+						numBits, nbs.name, k, got, exp)
+				}
+			}
+			if chunks != nbs.bs.NumUint64Chunks() {
+				t.Errorf("%d bits bitset %s: iterated %d chunks", numBits,
+					nbs.name, chunks)
+			}
+		}
+	}
+}
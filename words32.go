@@ -0,0 +1,151 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+import (
+	"encoding/binary"
+	"iter"
+	"math/bits"
+)
+
+// Words32 represents a bitset backed by a uint32 slice.  It is intended for
+// 32-bit and WebAssembly targets where 64-bit operations are emulated, and
+// unlike Pointers its word size is chosen explicitly rather than following
+// the machine pointer size.  Bit i is always bit i&31 of word i>>5, on every
+// platform.  On 32-bit machines it performs identically to Pointers.
+//
+// Like Pointers, Words32 bitsets do not automatically grow for indexed values
+// outside of the allocated range.  The Grow method is provided if it is
+// necessary to grow a Words32 bitset beyond its initial allocation.
+type Words32 []uint32
+
+// NewWords32 returns a new bitset that is capable of holding numBits number
+// of binary values.  All words in the bitset are zeroed and each bit is
+// therefore considered unset.
+func NewWords32(numBits int) Words32 {
+	return make(Words32, (numBits+31)>>5)
+}
+
+// NewWords32FromBytes returns a new bitset with the same bits set as s.  The
+// bitset is sized to hold every byte of s, rounded up to whole words.
+func NewWords32FromBytes(s Bytes) Words32 {
+	w := NewWords32(len(s) << byteShift)
+	for i, b := range s {
+		w[i>>2] |= uint32(b) << (uint(i&3) << byteShift)
+	}
+	return w
+}
+
+// Get returns whether the bit at index i is set or not.  This method will
+// panic if the index results in a word index that exceeds the number of words
+// held by the bitset.
+func (w Words32) Get(i int) bool {
+	return w[uint(i)>>5]&(1<<(uint(i)&31)) != 0
+}
+
+// Set sets the bit at index i.  This method will panic if the index results
+// in a word index that exceeds the number of words held by the bitset.
+func (w Words32) Set(i int) {
+	w[uint(i)>>5] |= 1 << (uint(i) & 31)
+}
+
+// Unset unsets the bit at index i.  This method will panic if the index
+// results in a word index that exceeds the number of words held by the
+// bitset.
+func (w Words32) Unset(i int) {
+	w[uint(i)>>5] &^= 1 << (uint(i) & 31)
+}
+
+// SetBool sets or unsets the bit at index i depending on the value of b.
+// This method will panic if the index results in a word index that exceeds
+// the number of words held by the bitset.
+func (w Words32) SetBool(i int, b bool) {
+	if b {
+		w.Set(i)
+		return
+	}
+	w.Unset(i)
+}
+
+// Grow ensures that the bitset w is large enough to hold numBits number of
+// bits.  If the backing array has enough spare capacity, the slice is
+// extended in place and the reclaimed words are zeroed.  Otherwise, the
+// slice is reallocated.
+func (w *Words32) Grow(numBits int) {
+	words := *w
+	targetLen := (numBits + 31) >> 5
+	if targetLen <= len(words) {
+		return
+	}
+	if targetLen <= cap(words) {
+		*w = words[:targetLen]
+		clear((*w)[len(words):])
+		return
+	}
+	*w = append(words, make(Words32, targetLen-len(words))...)
+}
+
+// Clone returns a copy of w which does not share memory with w.
+func (w Words32) Clone() Words32 {
+	c := make(Words32, len(w))
+	copy(c, w)
+	return c
+}
+
+// Count returns the total number of set bits in the bitset.
+func (w Words32) Count() int {
+	n := 0
+	for _, word := range w {
+		n += bits.OnesCount32(word)
+	}
+	return n
+}
+
+// NextSet returns the index of the first set bit at or after index i, or -1
+// if there is no such bit.  Negative indexes are treated as zero.
+func (w Words32) NextSet(i int) int {
+	if i < 0 {
+		i = 0
+	}
+	k := int(uint(i) >> 5)
+	if k >= len(w) {
+		return -1
+	}
+	if word := w[k] >> (uint(i) & 31); word != 0 {
+		return i + bits.TrailingZeros32(word)
+	}
+	for k++; k < len(w); k++ {
+		if w[k] != 0 {
+			return k<<5 + bits.TrailingZeros32(w[k])
+		}
+	}
+	return -1
+}
+
+// Ones returns an iterator over the indexes of all set bits in increasing
+// order.  Bits modified during iteration may or may not be observed.
+func (w Words32) Ones() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for k, word := range w {
+			for word != 0 {
+				if !yield(k<<5 + bits.TrailingZeros32(word)) {
+					return
+				}
+				word &= word - 1
+			}
+		}
+	}
+}
+
+// Bytes returns a new Bytes bitset with the same bits set as w.  The result
+// holds exactly four bytes for every word of w, in little endian order,
+// and is identical on every platform.
+func (w Words32) Bytes() Bytes {
+	s := make(Bytes, len(w)*4)
+	for k, word := range w {
+		binary.LittleEndian.PutUint32(s[k*4:], word)
+	}
+	return s
+}
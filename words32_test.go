@@ -0,0 +1,67 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset_test
+
+import (
+	"testing"
+
+	. "github.com/jrick/bitset"
+)
+
+func TestWords32(t *testing.T) {
+	set := []int{0, 1, 31, 32, 63, 64, 100, 191}
+	w := NewWords32(192)
+	if len(w) != 6 {
+		t.Fatalf("got %d words expected 6", len(w))
+	}
+	for _, i := range set {
+		w.SetBool(i, true)
+	}
+	if w.Count() != len(set) {
+		t.Errorf("got count %d expected %d", w.Count(), len(set))
+	}
+	if w[0] != 1<<0|1<<1|1<<31 || w[1] != 1<<0|1<<31 {
+		t.Errorf("unexpected layout of words 0 and 1: %#x", w[:2])
+	}
+	var got []int
+	for i := range w.Ones() {
+		got = append(got, i)
+	}
+	if !equalIndices(got, set) {
+		t.Errorf("Ones: got %v expected %v", got, set)
+	}
+	for i, next := 0, 0; i < 192; i++ {
+		for next < len(set) && set[next] < i {
+			next++
+		}
+		exp := -1
+		if next < len(set) {
+			exp = set[next]
+		}
+		if got := w.NextSet(i); got != exp {
+			t.Errorf("NextSet(%d): got %d expected %d", i, got, exp)
+		}
+	}
+
+	b := w.Bytes()
+	if got := b.ToSlice(); !equalIndices(got, set) {
+		t.Errorf("Bytes: got %v expected %v", got, set)
+	}
+	w2 := NewWords32FromBytes(b[:len(b)-1])
+	if got, exp := w2.Count(), len(set)-1; got != exp {
+		t.Errorf("NewWords32FromBytes: got count %d expected %d", got, exp)
+	}
+
+	c := w.Clone()
+	w.Unset(0)
+	if !c.Get(0) || w.Get(0) {
+		t.Errorf("Clone shares memory with original")
+	}
+	c.Grow(1000)
+	c.Set(999)
+	if len(c) != 32 || c.Count() != len(set)+1 {
+		t.Errorf("Grow: got %d words and count %d", len(c), c.Count())
+	}
+}
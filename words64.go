@@ -0,0 +1,152 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+import (
+	"encoding/binary"
+	"iter"
+	"math/bits"
+)
+
+// Words64 represents a bitset backed by a uint64 slice.  Unlike Pointers,
+// whose word size follows the machine pointer size, the layout of a Words64
+// bitset is the same on every platform: bit i is always bit i&63 of word
+// i>>6.  A Words64 built on a 64-bit machine may therefore be moved to a
+// 32-bit or WebAssembly target, word for word, without any change in
+// indexing.  On 64-bit machines it performs identically to Pointers.
+//
+// Like Pointers, Words64 bitsets do not automatically grow for indexed values
+// outside of the allocated range.  The Grow method is provided if it is
+// necessary to grow a Words64 bitset beyond its initial allocation.
+type Words64 []uint64
+
+// NewWords64 returns a new bitset that is capable of holding numBits number
+// of binary values.  All words in the bitset are zeroed and each bit is
+// therefore considered unset.
+func NewWords64(numBits int) Words64 {
+	return make(Words64, (numBits+63)>>6)
+}
+
+// NewWords64FromBytes returns a new bitset with the same bits set as s.  The
+// bitset is sized to hold every byte of s, rounded up to whole words.
+func NewWords64FromBytes(s Bytes) Words64 {
+	w := NewWords64(len(s) << byteShift)
+	for k, v := range s.Uint64s() {
+		w[k] = v
+	}
+	return w
+}
+
+// Get returns whether the bit at index i is set or not.  This method will
+// panic if the index results in a word index that exceeds the number of words
+// held by the bitset.
+func (w Words64) Get(i int) bool {
+	return w[uint(i)>>6]&(1<<(uint(i)&63)) != 0
+}
+
+// Set sets the bit at index i.  This method will panic if the index results
+// in a word index that exceeds the number of words held by the bitset.
+func (w Words64) Set(i int) {
+	w[uint(i)>>6] |= 1 << (uint(i) & 63)
+}
+
+// Unset unsets the bit at index i.  This method will panic if the index
+// results in a word index that exceeds the number of words held by the
+// bitset.
+func (w Words64) Unset(i int) {
+	w[uint(i)>>6] &^= 1 << (uint(i) & 63)
+}
+
+// SetBool sets or unsets the bit at index i depending on the value of b.
+// This method will panic if the index results in a word index that exceeds
+// the number of words held by the bitset.
+func (w Words64) SetBool(i int, b bool) {
+	if b {
+		w.Set(i)
+		return
+	}
+	w.Unset(i)
+}
+
+// Grow ensures that the bitset w is large enough to hold numBits number of
+// bits.  If the backing array has enough spare capacity, the slice is
+// extended in place and the reclaimed words are zeroed.  Otherwise, the
+// slice is reallocated.
+func (w *Words64) Grow(numBits int) {
+	words := *w
+	targetLen := (numBits + 63) >> 6
+	if targetLen <= len(words) {
+		return
+	}
+	if targetLen <= cap(words) {
+		*w = words[:targetLen]
+		clear((*w)[len(words):])
+		return
+	}
+	*w = append(words, make(Words64, targetLen-len(words))...)
+}
+
+// Clone returns a copy of w which does not share memory with w.
+func (w Words64) Clone() Words64 {
+	c := make(Words64, len(w))
+	copy(c, w)
+	return c
+}
+
+// Count returns the total number of set bits in the bitset.
+func (w Words64) Count() int {
+	n := 0
+	for _, word := range w {
+		n += bits.OnesCount64(word)
+	}
+	return n
+}
+
+// NextSet returns the index of the first set bit at or after index i, or -1
+// if there is no such bit.  Negative indexes are treated as zero.
+func (w Words64) NextSet(i int) int {
+	if i < 0 {
+		i = 0
+	}
+	k := int(uint(i) >> 6)
+	if k >= len(w) {
+		return -1
+	}
+	if word := w[k] >> (uint(i) & 63); word != 0 {
+		return i + bits.TrailingZeros64(word)
+	}
+	for k++; k < len(w); k++ {
+		if w[k] != 0 {
+			return k<<6 + bits.TrailingZeros64(w[k])
+		}
+	}
+	return -1
+}
+
+// Ones returns an iterator over the indexes of all set bits in increasing
+// order.  Bits modified during iteration may or may not be observed.
+func (w Words64) Ones() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for k, word := range w {
+			for word != 0 {
+				if !yield(k<<6 + bits.TrailingZeros64(word)) {
+					return
+				}
+				word &= word - 1
+			}
+		}
+	}
+}
+
+// Bytes returns a new Bytes bitset with the same bits set as w.  The result
+// holds exactly eight bytes for every word of w, in little endian order,
+// and is identical on every platform.
+func (w Words64) Bytes() Bytes {
+	s := make(Bytes, len(w)*8)
+	for k, word := range w {
+		binary.LittleEndian.PutUint64(s[k*8:], word)
+	}
+	return s
+}
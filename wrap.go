@@ -0,0 +1,48 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+// A bitset may be overlaid on memory owned by the caller, such as a buffer
+// read from disk, without copying.  The bitset returned by PointersOf,
+// WordsOf, or BytesOf shares its backing array with the wrapped slice, and
+// the following rules apply for as long as both are in use:
+//
+//   - Setting or unsetting a bit writes through to the wrapped slice, and
+//     writes to the slice are observed by the bitset.
+//   - The bitset holds exactly len(buf) pointers, words, or bytes.  Methods
+//     which do not change the length never reallocate.
+//   - Grow, GrowAmortized, OrGrow, XorGrow, Append, and the other methods
+//     with pointer receivers that lengthen the bitset write into any spare
+//     capacity of the slice, and the bitset stops sharing memory with the
+//     slice once a reallocation is required.  Use a full slice expression
+//     (buf[:n:n]) to ensure the bitset never writes beyond buf[:n].
+//   - Truncate zeroes the bits it drops, which modifies the wrapped slice.
+//     Compact may reallocate, after which the bitset no longer shares
+//     memory with the slice.
+//
+// No copy is made, and so the caller must not modify the slice concurrently
+// with any use of the bitset.
+
+// PointersOf returns a Pointers bitset which uses buf as its storage.  See
+// the aliasing rules described above.
+func PointersOf(buf []uintptr) Pointers {
+	return Pointers(buf)
+}
+
+// WordsOf returns a Words64 bitset which uses buf as its storage.  The bit at
+// index i is bit i&63 of buf[i>>6] on every platform, so this is the form to
+// use for externally produced uint64 words.  See the aliasing rules
+// described above.
+func WordsOf(buf []uint64) Words64 {
+	return Words64(buf)
+}
+
+// BytesOf returns a Bytes bitset which uses buf as its storage.  The bit at
+// index i is bit i&7 of buf[i>>3], so any buffer previously written from a
+// Bytes bitset may be wrapped directly.  See the aliasing rules described
+// above.
+func BytesOf(buf []byte) Bytes {
+	return Bytes(buf)
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset_test
+
+import (
+	"testing"
+
+	. "github.com/jrick/bitset"
+)
+
+func TestBytesOf(t *testing.T) {
+	buf := []byte{0x01, 0x80, 0x00}
+	s := BytesOf(buf)
+	if got, exp := s.ToSlice(), []int{0, 15}; !equalIndices(got, exp) {
+		t.Errorf("got %v expected %v", got, exp)
+	}
+	s.Set(17)
+	if buf[2] != 0x02 {
+		t.Errorf("Set did not write through: got %#x expected 0x02", buf[2])
+	}
+	buf[0] = 0
+	if s.Get(0) {
+		t.Errorf("write to buffer not observed by bitset")
+	}
+
+	// A full slice expression prevents growth from writing into the rest
+	// of the buffer.
+	s = BytesOf(buf[:1:1])
+	s.Grow(16)
+	s.Set(9)
+	if buf[1] != 0x80 {
+		t.Errorf("Grow wrote beyond the wrapped slice: got %#x", buf[1])
+	}
+}
+
+func TestPointersOf(t *testing.T) {
+	buf := make([]uintptr, 2)
+	p := PointersOf(buf)
+	p.Set(1)
+	p.Set(p.Cap() - 1)
+	if buf[0] != 2 || buf[1] == 0 {
+		t.Errorf("Set did not write through: got %#x", buf)
+	}
+	buf[0] = 0
+	if p.Get(1) {
+		t.Errorf("write to buffer not observed by bitset")
+	}
+}
+
+func TestWordsOf(t *testing.T) {
+	buf := make([]uint64, 2)
+	w := WordsOf(buf)
+	w.Set(1)
+	w.Set(127)
+	if buf[0] != 2 || buf[1] != 1<<63 {
+		t.Errorf("Set did not write through: got %#x", buf)
+	}
+	buf[0] = 0
+	if w.Get(1) {
+		t.Errorf("write to buffer not observed by bitset")
+	}
+
+	// Growing within the spare capacity writes into the wrapped array.
+	buf = make([]uint64, 1, 2)
+	w = WordsOf(buf)
+	w.Grow(128)
+	w.Set(64)
+	if buf[:2][1] != 1 {
+		t.Errorf("Grow did not use spare capacity")
+	}
+}
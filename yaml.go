@@ -0,0 +1,106 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset
+
+import "encoding/hex"
+
+// Bitsets are represented in YAML in one of two forms, suited to declaring
+// static bitsets in configuration files:
+//
+//   - A string of the lowercase hexadecimal encoding of the bitset's bytes,
+//     using the layout of a Bytes bitset, as produced by MarshalText.  This
+//     is the form produced by Pointers and Bytes.
+//   - A sequence of the indexes of all set bits.  This is the form produced
+//     by Sparse.
+//
+// The UnmarshalYAML methods accept either form, and the indexes of a
+// sequence may appear in any order.  Indexes decoded into Pointers and Bytes
+// bitsets are limited as described for JSON.  Hexadecimal strings which
+// consist only of decimal digits must be quoted so they are not read as
+// integers.
+//
+// The MarshalYAML and UnmarshalYAML methods match the interfaces of the
+// gopkg.in/yaml.v2 package, which are also supported by gopkg.in/yaml.v3, so
+// bitsets may be embedded in YAML documents without this package depending
+// on either.
+
+// MarshalYAML encodes s as a hexadecimal string.  It never returns an error.
+func (s Bytes) MarshalYAML() (interface{}, error) {
+	return hex.EncodeToString(s), nil
+}
+
+// UnmarshalYAML replaces the contents of s with the bits encoded by a
+// hexadecimal string or a sequence of indexes.  On error, s is not modified.
+func (s *Bytes) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalYAML(unmarshal, maxInt, s)
+}
+
+// MarshalYAML encodes p as a hexadecimal string of its binary encoding.  It
+// never returns an error.
+func (p Pointers) MarshalYAML() (interface{}, error) {
+	text, _ := p.MarshalText()
+	return string(text), nil
+}
+
+// UnmarshalYAML replaces the contents of p with the bits encoded by a
+// hexadecimal string or a sequence of indexes.  On error, p is not modified.
+func (p *Pointers) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalYAML(unmarshal, maxInt, p)
+}
+
+// MarshalYAML encodes s as a sequence of the indexes of its set bits in
+// increasing order.  If any bit is set at a negative index, which would be
+// rejected when unmarshaling, ErrIndexOutOfRange is returned.
+func (s Sparse) MarshalYAML() (interface{}, error) {
+	indices := make([]int, 0, s.Count())
+	for i := range s.Ones() {
+		if i < 0 {
+			return nil, ErrIndexOutOfRange
+		}
+		indices = append(indices, i)
+	}
+	return indices, nil
+}
+
+// UnmarshalYAML replaces the contents of s with the bits encoded by a
+// hexadecimal string or a sequence of indexes, allocating the map if s is
+// nil.  On error, s is not modified.
+func (s *Sparse) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalYAML(unmarshal, maxInt, s)
+}
+
+// unmarshalYAML decodes a YAML value, using the unmarshal function provided
+// by a YAML package, as either a hexadecimal string or a sequence of indexes,
+// replacing the contents of bs.  Negative indexes result in
+// ErrIndexOutOfRange, and encodings of more than maxBits bits result in
+// ErrTooLarge.
+func unmarshalYAML(unmarshal func(interface{}) error, maxBits int, bs BitSet) error {
+	fromBytes, fromIndexes, ok := replacers(bs)
+	if !ok {
+		return ErrUnsupportedType
+	}
+	var text string
+	if err := unmarshal(&text); err == nil {
+		// Each hexadecimal digit encodes four bits.
+		if len(text) > maxBits>>2 {
+			return ErrTooLarge
+		}
+		b, err := hex.DecodeString(text)
+		if err != nil {
+			return err
+		}
+		fromBytes(b)
+		return nil
+	}
+	var indices []int
+	if err := unmarshal(&indices); err != nil {
+		return err
+	}
+	if err := checkIndexes(indices, indexLimit(bs, maxBits)); err != nil {
+		return err
+	}
+	fromIndexes(indices)
+	return nil
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2014-2015 Josh Rickmar.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bitset_test
+
+import (
+	"errors"
+	"iter"
+	"reflect"
+	"slices"
+	"testing"
+
+	. "github.com/jrick/bitset"
+)
+
+// yamlValue returns an unmarshal function, as passed to UnmarshalYAML
+// methods by YAML packages, which decodes v into values of the same type.
+func yamlValue(v interface{}) func(interface{}) error {
+	return func(out interface{}) error {
+		rv := reflect.ValueOf(out).Elem()
+		if rv.Type() != reflect.TypeOf(v) {
+			return errors.New("cannot unmarshal " + reflect.TypeOf(v).String() +
+				" into " + rv.Type().String())
+		}
+		rv.Set(reflect.ValueOf(v))
+		return nil
+	}
+}
+
+func TestYAML(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		set   []int
+		err   error
+	}{
+		{"", nil, nil},
+		{"8100", []int{0, 7}, nil},
+		{"0A", []int{1, 3}, nil},
+		{[]int{}, nil, nil},
+		{[]int{9, 2, 9}, []int{2, 9}, nil},
+		{[]int{-1}, nil, ErrIndexOutOfRange},
+	}
+	for testNum, test := range tests {
+		bitsets := []BitSet{new(Bytes), new(Pointers), new(Sparse)}
+		for _, bs := range bitsets {
+			u := bs.(interface {
+				UnmarshalYAML(func(interface{}) error) error
+			})
+			err := u.UnmarshalYAML(yamlValue(test.value))
+			if err != test.err {
+				t.Errorf("Test %d %T: got error %v expected %v", testNum,
+					bs, err, test.err)
+				continue
+			}
+			ones := bs.(interface{ Ones() iter.Seq[int] }).Ones()
+			if got := slices.Collect(ones); !slices.Equal(got, test.set) {
+				t.Errorf("Test %d %T: got %v expected %v", testNum, bs,
+					got, test.set)
+			}
+		}
+	}
+
+	var s Bytes
+	if err := s.UnmarshalYAML(yamlValue("xy")); err == nil {
+		t.Errorf("invalid hexadecimal: expected error")
+	}
+	if err := s.UnmarshalYAML(yamlValue(1.5)); err == nil {
+		t.Errorf("float: expected error")
+	}
+	huge := []int{int(^uint(0)>>1) - 1}
+	if err := s.UnmarshalYAML(yamlValue(huge)); err != ErrTooLarge {
+		t.Errorf("huge index: got error %v expected %v", err, ErrTooLarge)
+	}
+	var sp Sparse
+	if err := sp.UnmarshalYAML(yamlValue(huge)); err != nil || !sp.Get(huge[0]) {
+		t.Errorf("huge Sparse index: unexpected error %v", err)
+	}
+	l := Limited{new(Sparse), 8}
+	if err := l.UnmarshalYAML(yamlValue([]int{8})); err != ErrTooLarge {
+		t.Errorf("Limited: got error %v expected %v", err, ErrTooLarge)
+	}
+	if err := l.UnmarshalYAML(yamlValue("0000")); err != ErrTooLarge {
+		t.Errorf("Limited: got error %v expected %v", err, ErrTooLarge)
+	}
+}
+
+func TestMarshalYAML(t *testing.T) {
+	pText, _ := Pointers{0x0a}.MarshalText()
+	tests := []struct {
+		bs  interface{ MarshalYAML() (interface{}, error) }
+		exp interface{}
+	}{
+		{Bytes{0x81, 0x00}, "8100"},
+		{Bytes{}, ""},
+		{Pointers{0x0a}, string(pText)},
+		{Sparse{}, []int{}},
+		{NewSparseFromIndices([]int{100, 3}), []int{3, 100}},
+	}
+	for testNum, test := range tests {
+		got, err := test.bs.MarshalYAML()
+		if err != nil {
+			t.Fatalf("Test %d: %v", testNum, err)
+		}
+		if !reflect.DeepEqual(got, test.exp) {
+			t.Errorf("Test %d: got %#v expected %#v", testNum, got, test.exp)
+		}
+	}
+}
+
+func TestMarshalYAMLNegativeIndex(t *testing.T) {
+	s := NewSparseFromIndices([]int{3})
+	s.Set(-5)
+	if _, err := s.MarshalYAML(); err != ErrIndexOutOfRange {
+		t.Errorf("got error %v expected %v", err, ErrIndexOutOfRange)
+	}
+}